@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/NicholasRodrigues/go-chain/internal/blockchain"
+	"github.com/NicholasRodrigues/go-chain/internal/schema"
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+	"github.com/NicholasRodrigues/go-chain/pkg/address"
+)
+
+// errInvalidChain is returned by chain validate for a chain failing validation.
+var errInvalidChain = errors.New("blockchain is invalid")
+
+// validateResult is the JSON output of chain validate.
+type validateResult struct {
+	Valid  bool `json:"valid"`
+	Height int  `json:"height"`
+}
+
+func runChainShow(e *env, args []string) error {
+	fs := e.flags()
+	height := fs.Int("height", -1, "show only the block at this height")
+	if _, err := e.parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	n, err := openNode(e.datadir, true)
+	if err != nil {
+		return err
+	}
+
+	first, last := 0, len(n.chain.Blocks)-1
+	if *height >= 0 {
+		if *height > last {
+			return fmt.Errorf("no block at height %d, the chain height is %d", *height, last)
+		}
+		first, last = *height, *height
+	}
+
+	blocks := make([]*schema.Block, 0, last-first+1)
+	for h := first; h <= last; h++ {
+		blocks = append(blocks, schema.NewBlock(n.chain, n.chain.Blocks[h], h, true, netParams))
+	}
+	return e.print(blocks, func(w io.Writer) {
+		for h := first; h <= last; h++ {
+			printBlock(w, h, n.chain.Blocks[h])
+		}
+	})
+}
+
+func runChainValidate(e *env, args []string) error {
+	fs := e.flags()
+	if _, err := e.parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	n, err := openNode(e.datadir, true)
+	if err != nil {
+		return err
+	}
+
+	result := validateResult{
+		Valid:  blockchain.ChainValidationPredicate(n.chain),
+		Height: len(n.chain.Blocks) - 1,
+	}
+	if err := e.print(result, func(w io.Writer) {
+		if result.Valid {
+			fmt.Fprintf(w, "Blockchain is valid, height %d.\n", result.Height)
+		}
+	}); err != nil {
+		return err
+	}
+	if !result.Valid {
+		return errInvalidChain
+	}
+	return nil
+}
+
+// printBlock prints a block and its transactions.
+func printBlock(w io.Writer, height int, block *blockchain.Block) {
+	fmt.Fprintf(w, "Block %d: %x\n", height, block.Hash)
+	fmt.Fprintf(w, "Previous Hash: %x\n", block.PrevBlockHash)
+	fmt.Fprintf(w, "Transactions: \n")
+	for _, tx := range block.Transactions {
+		printTransaction(w, tx)
+	}
+	fmt.Fprintln(w)
+}
+
+// printTransaction prints a transaction followed by the addresses its outputs pay to.
+func printTransaction(w io.Writer, tx *transactions.Transaction) {
+	fmt.Fprintf(w, "  %s\n", tx.String())
+	for i, out := range tx.Vout {
+		if addr, err := transactions.ExtractAddress(out.ScriptPubKey, netParams); err == nil {
+			fmt.Fprintf(w, "     Output %d address: %s\n", i, addr)
+		}
+	}
+}
+
+// scriptForAddress decodes a Base58Check or bech32 address into a locking script.
+func scriptForAddress(addr string) (string, error) {
+	decoded, err := address.Decode(addr, netParams)
+	if err != nil {
+		return "", err
+	}
+	return transactions.PayToAddrScript(decoded), nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// configFile is the configuration file read from the data directory when no
+// --config flag is given.
+const configFile = "gochain.json"
+
+// config holds the settings a configuration file may hold. Each sets the
+// flag of the same name in the commands that have it, for example:
+//
+//	{"network": "testnet", "rpcaddr": "127.0.0.1:18332", "restaddr": ""}
+type config struct {
+	DataDir      *string `json:"datadir"`
+	Network      *string `json:"network"`
+	PasswordFile *string `json:"password-file"`
+	RESTAddr     *string `json:"restaddr"`
+	RPCAddr      *string `json:"rpcaddr"`
+	RPCCookie    *string `json:"rpccookie"`
+}
+
+// values returns the settings present in c as flag values keyed by flag name.
+func (c *config) values() map[string]string {
+	values := make(map[string]string)
+	for name, value := range map[string]*string{
+		"datadir":       c.DataDir,
+		"network":       c.Network,
+		"password-file": c.PasswordFile,
+		"restaddr":      c.RESTAddr,
+		"rpcaddr":       c.RPCAddr,
+		"rpccookie":     c.RPCCookie,
+	} {
+		if value != nil {
+			values[name] = *value
+		}
+	}
+	return values
+}
+
+// applyConfig sets the flags of flags that were not given on the command
+// line from the configuration file.
+func (e *env) applyConfig(flags *flag.FlagSet) error {
+	path, explicit := e.config, e.config != ""
+	if !explicit {
+		path = filepath.Join(e.datadir, configFile)
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var settings config
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&settings); err != nil {
+		return usagef("invalid configuration file %s: %v", path, err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return usagef("invalid configuration file %s: data after the settings", path)
+	}
+	for key, value := range settings.values() {
+		if e.set[key] || flags.Lookup(key) == nil {
+			continue
+		}
+		if err := flags.Set(key, value); err != nil {
+			return usagef("invalid setting %q in %s: %v", key, path, err)
+		}
+		e.set[key] = true
+	}
+	return nil
+}
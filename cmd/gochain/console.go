@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/NicholasRodrigues/go-chain/internal/blockchain"
+	"github.com/NicholasRodrigues/go-chain/internal/mempool"
+	"github.com/NicholasRodrigues/go-chain/internal/rest"
+	"github.com/NicholasRodrigues/go-chain/internal/rpc"
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+	"github.com/NicholasRodrigues/go-chain/internal/wallet"
+	"github.com/NicholasRodrigues/go-chain/pkg/address"
+	"github.com/NicholasRodrigues/go-chain/pkg/keystore"
+	"github.com/NicholasRodrigues/go-chain/pkg/mnemonic"
+)
+
+// mnemonicBits is the entropy of recovery phrases for new wallets (24 words).
+const mnemonicBits = 256
+
+// runConsole runs the interactive menu on an in-memory chain until Exit is
+// chosen or the input ends.
+func runConsole(e *env, args []string) error {
+	fs := e.flags()
+	rpcAddr := fs.String("rpcaddr", rpc.DefaultAddr, "JSON-RPC loopback listen address, empty to disable (default port set by the network)")
+	rpcCookie := fs.String("rpccookie", ".cookie", "file receiving the JSON-RPC credentials")
+	restAddr := fs.String("restaddr", rest.DefaultAddr, "REST API loopback listen address, empty to disable (default port set by the network)")
+	if _, err := e.parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	e.defaultAddrs(rpcAddr, restAddr)
+
+	bc := blockchain.NewBlockchainWithParams(chainParams)
+	pool := mempool.New(bc)
+	sentence, err := mnemonic.Generate(mnemonicBits)
+	if err != nil {
+		return fmt.Errorf("failed to create wallet: %w", err)
+	}
+	w, err := wallet.NewFromMnemonic(bc, pool, netParams, sentence, "")
+	if err != nil {
+		return fmt.Errorf("failed to create wallet: %w", err)
+	}
+	fmt.Println("New wallet recovery phrase (write it down):")
+	fmt.Println(sentence)
+	if _, err := w.NewAddress(); err != nil {
+		return fmt.Errorf("failed to create wallet key: %w", err)
+	}
+
+	svc, err := startServices(e.stdout, bc, pool, w, *rpcAddr, *rpcCookie, *restAddr)
+	if err != nil {
+		return err
+	}
+	defer svc.close()
+	go func() {
+		for err := range svc.errc {
+			fmt.Println(err)
+		}
+	}()
+
+	scanner := bufio.NewScanner(e.stdin)
+	for {
+		printMenu()
+		if !scanner.Scan() {
+			fmt.Println()
+			return scanner.Err()
+		}
+
+		switch scanner.Text() {
+		case "1":
+			handleAddBlock(bc, scanner)
+		case "2":
+			handleViewBlockchain(bc)
+		case "3":
+			handleValidateBlockchain(bc)
+		case "4":
+			handleCreateTransaction(w, scanner)
+		case "5":
+			handleValidateTransaction(bc, scanner)
+		case "6":
+			fmt.Println("Exiting...")
+			return nil
+		case "7":
+			handleNewAddress(w)
+		case "8":
+			handleBalance(w)
+		case "9":
+			handleMine(bc, pool, w)
+		case "10":
+			handleSaveWallet(w, scanner)
+		case "11":
+			handleLoadWallet(w, scanner)
+		case "12":
+			handleChangePassword(scanner)
+		case "13":
+			if restored := handleRestoreWallet(bc, pool, scanner); restored != nil {
+				w = restored
+				if svc.rpc != nil {
+					svc.rpc.SetWallet(w)
+				}
+			}
+		default:
+			fmt.Println("Invalid command. Please try again.")
+		}
+	}
+}
+
+func printMenu() {
+	fmt.Println("\nBlockchain CLI")
+	fmt.Println("1. Add Block")
+	fmt.Println("2. View Blockchain")
+	fmt.Println("3. Validate Blockchain")
+	fmt.Println("4. Create Transaction")
+	fmt.Println("5. Validate Transaction")
+	fmt.Println("6. Exit")
+	fmt.Println("7. New Address")
+	fmt.Println("8. Wallet Balance")
+	fmt.Println("9. Mine Pending Transactions")
+	fmt.Println("10. Save Wallet")
+	fmt.Println("11. Load Wallet")
+	fmt.Println("12. Change Wallet Password")
+	fmt.Println("13. Restore Wallet From Recovery Phrase")
+	fmt.Print("Enter command: ")
+}
+
+func handleAddBlock(bc *blockchain.Blockchain, scanner *bufio.Scanner) {
+	fmt.Print("Enter data for the new block: ")
+	scanner.Scan()
+	data := scanner.Text()
+
+	input := func() string {
+		return "input data"
+	}
+	receive := func() string {
+		return data
+	}
+
+	chainLock.Lock()
+	blockchain.InputContributionFunction([]byte(data), bc, len(bc.Blocks), input, receive)
+	chainLock.Unlock()
+	fmt.Println("Block added successfully!")
+}
+
+func handleViewBlockchain(bc *blockchain.Blockchain) {
+	for i, block := range bc.Blocks {
+		printBlock(os.Stdout, i, block)
+	}
+}
+
+func handleValidateBlockchain(bc *blockchain.Blockchain) {
+	if blockchain.ChainValidationPredicate(bc) {
+		fmt.Println("Blockchain is valid.")
+	} else {
+		fmt.Println("Blockchain is invalid.")
+	}
+}
+
+func handleCreateTransaction(w *wallet.Wallet, scanner *bufio.Scanner) {
+	fmt.Print("Enter recipient address: ")
+	scanner.Scan()
+	addr, err := address.Decode(scanner.Text(), netParams)
+	if err != nil {
+		fmt.Println("Invalid address:", err)
+		return
+	}
+
+	fmt.Print("Enter amount: ")
+	scanner.Scan()
+	amount, err := strconv.Atoi(scanner.Text())
+	if err != nil {
+		fmt.Println("Invalid amount:", err)
+		return
+	}
+
+	tx, err := w.Send(addr, amount)
+	if err != nil {
+		fmt.Println("Failed to send payment:", err)
+		return
+	}
+
+	fmt.Println("Transaction created and added to the mempool!")
+	printTransaction(os.Stdout, tx)
+	fmt.Println("Serialized transaction:", hex.EncodeToString(tx.Serialize()))
+}
+
+func handleValidateTransaction(bc *blockchain.Blockchain, scanner *bufio.Scanner) {
+	fmt.Print("Enter serialized transaction: ")
+	scanner.Scan()
+	txBytes, err := hex.DecodeString(scanner.Text())
+	if err != nil {
+		fmt.Println("Invalid transaction:", err)
+		return
+	}
+	tx, err := transactions.DecodeTransaction(txBytes)
+	if err != nil {
+		fmt.Println("Invalid transaction:", err)
+		return
+	}
+
+	utxoSet := bc.UTXOSet().Outputs()
+	fmt.Println("Enter outputs missing from the chain's UTXO set (enter 'done' to finish):")
+	for {
+		fmt.Print("Enter UTXO key (txid:vout): ")
+		if !scanner.Scan() || scanner.Text() == "done" {
+			break
+		}
+		key := scanner.Text()
+
+		fmt.Print("Enter value: ")
+		scanner.Scan()
+		value, err := strconv.Atoi(scanner.Text())
+		if err != nil {
+			fmt.Println("Invalid value:", err)
+			continue
+		}
+
+		fmt.Print("Enter address: ")
+		scanner.Scan()
+		scriptPubKey, err := scriptForAddress(scanner.Text())
+		if err != nil {
+			fmt.Println("Invalid address:", err)
+			continue
+		}
+
+		utxoSet[key] = transactions.TransactionOutput{Value: value, ScriptPubKey: scriptPubKey}
+	}
+
+	if err := tx.ValidateWithFlags(utxoSet, transactions.StandardFlags(bc.Height()+1, bc.Params().SchemeActivations)); err != nil {
+		fmt.Println("Transaction is invalid:", err)
+	} else {
+		fmt.Println("Transaction is valid.")
+	}
+}
+
+func handleNewAddress(w *wallet.Wallet) {
+	addr, err := w.NewAddress()
+	if err != nil {
+		fmt.Println("Failed to create address:", err)
+		return
+	}
+	fmt.Println("New address:", addr)
+}
+
+func handleBalance(w *wallet.Wallet) {
+	for _, addr := range w.Addresses() {
+		fmt.Println("Address:", addr)
+	}
+	balance := w.Balance()
+	fmt.Printf("Confirmed balance:   %d\n", balance.Confirmed)
+	fmt.Printf("Immature balance:    %d\n", balance.Immature)
+	fmt.Printf("Unconfirmed balance: %d\n", balance.Unconfirmed)
+}
+
+func handleMine(bc *blockchain.Blockchain, pool *mempool.Mempool, w *wallet.Wallet) {
+	rewardAddr := w.Addresses()[0]
+	chainLock.Lock()
+	mined, err := mineBlock(bc, pool, rewardAddr)
+	chainLock.Unlock()
+	if err != nil {
+		fmt.Println("Failed to mine block:", err)
+		return
+	}
+	fmt.Printf("Mined block %d paying the reward to %s\n", mined.Height, rewardAddr)
+}
+
+func handleSaveWallet(w *wallet.Wallet, scanner *bufio.Scanner) {
+	path := prompt(scanner, "Enter keystore file: ")
+	password := prompt(scanner, "Enter password: ")
+	if err := w.SaveKeystore(path, []byte(password)); err != nil {
+		fmt.Println("Failed to save wallet:", err)
+		return
+	}
+	fmt.Println("Wallet saved to", path)
+}
+
+func handleLoadWallet(w *wallet.Wallet, scanner *bufio.Scanner) {
+	path := prompt(scanner, "Enter keystore file: ")
+	password := prompt(scanner, "Enter password: ")
+	if err := w.LoadKeystore(path, []byte(password)); err != nil {
+		fmt.Println("Failed to load wallet:", err)
+		return
+	}
+	fmt.Printf("Wallet loaded, %d keys available\n", len(w.Keys()))
+}
+
+func handleChangePassword(scanner *bufio.Scanner) {
+	path := prompt(scanner, "Enter keystore file: ")
+	oldPassword := prompt(scanner, "Enter current password: ")
+	newPassword := prompt(scanner, "Enter new password: ")
+	if err := keystore.ChangePassword(path, []byte(oldPassword), []byte(newPassword)); err != nil {
+		fmt.Println("Failed to change password:", err)
+		return
+	}
+	fmt.Println("Password changed.")
+}
+
+func handleRestoreWallet(bc *blockchain.Blockchain, pool *mempool.Mempool, scanner *bufio.Scanner) *wallet.Wallet {
+	sentence := prompt(scanner, "Enter recovery phrase: ")
+	passphrase := prompt(scanner, "Enter passphrase (leave empty for none): ")
+
+	w, err := wallet.NewFromMnemonic(bc, pool, netParams, sentence, passphrase)
+	if err != nil {
+		fmt.Println("Failed to restore wallet:", err)
+		return nil
+	}
+	found, err := w.Discover(wallet.DefaultGapLimit)
+	if err != nil {
+		fmt.Println("Failed to rescan the chain:", err)
+		return nil
+	}
+	if found == 0 {
+		if _, err := w.NewAddress(); err != nil {
+			fmt.Println("Failed to create wallet key:", err)
+			return nil
+		}
+	}
+
+	fmt.Printf("Wallet restored, %d used addresses found\n", found)
+	handleBalance(w)
+	return w
+}
+
+// prompt prints label and returns the next line of input.
+func prompt(scanner *bufio.Scanner, label string) string {
+	fmt.Print(label)
+	scanner.Scan()
+	return scanner.Text()
+}
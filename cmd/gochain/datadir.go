@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/NicholasRodrigues/go-chain/internal/blockchain"
+	"github.com/NicholasRodrigues/go-chain/internal/mempool"
+	"github.com/NicholasRodrigues/go-chain/internal/wallet"
+)
+
+// Files kept in the data directory.
+const (
+	chainFile   = "chain.dat"
+	mempoolFile = "mempool.dat"
+	walletFile  = "wallet.json"
+	lockFile    = "LOCK"
+	cookieFile  = ".cookie"
+)
+
+// passwordEnv names the environment variable holding the wallet password
+// when no --password-file is given.
+const passwordEnv = "GOCHAIN_PASSWORD"
+
+// defaultDataDir returns $GOCHAIN_DATADIR, or ~/.gochain if it is unset.
+func defaultDataDir() string {
+	if dir := os.Getenv("GOCHAIN_DATADIR"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".gochain"
+	}
+	return filepath.Join(home, ".gochain")
+}
+
+// node is the chain and mempool stored in a data directory.
+type node struct {
+	dir   string
+	chain *blockchain.Blockchain
+	pool  *mempool.Mempool
+	lock  *os.File // Nil when opened read-only
+}
+
+// openNode loads the chain and mempool stored in dir, creating a new chain
+// of the selected network if there is none. Unless readOnly is set, the directory is locked against
+// other gochain processes until close is called and the new chain is saved;
+// read-only nodes leave the directory untouched.
+func openNode(dir string, readOnly bool) (*node, error) {
+	n := &node{dir: dir}
+	if !readOnly {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, err
+		}
+		lockPath := filepath.Join(dir, lockFile)
+		lock, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("data directory %s is in use by another gochain process (remove %s if none is running)", dir, lockPath)
+		}
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(lock, "%d\n", os.Getpid())
+		n.lock = lock
+	}
+
+	chain, err := blockchain.LoadBlockchain(n.path(chainFile), chainParams)
+	if errors.Is(err, fs.ErrNotExist) {
+		chain, err = blockchain.NewBlockchainWithParams(chainParams), nil
+		if !readOnly {
+			err = chain.Save(n.path(chainFile))
+		}
+	}
+	if err != nil {
+		n.close()
+		return nil, err
+	}
+	n.chain = chain
+
+	n.pool = mempool.New(chain)
+	if _, err := n.pool.Load(n.path(mempoolFile)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		n.close()
+		return nil, err
+	}
+	return n, nil
+}
+
+func (n *node) path(name string) string {
+	return filepath.Join(n.dir, name)
+}
+
+// save writes the chain and mempool back to the data directory.
+func (n *node) save() error {
+	if n.lock == nil {
+		return errors.New("data directory opened read-only")
+	}
+	if err := n.chain.Save(n.path(chainFile)); err != nil {
+		return err
+	}
+	return n.pool.Save(n.path(mempoolFile))
+}
+
+// close releases the data directory lock.
+func (n *node) close() {
+	if n.lock != nil {
+		n.lock.Close()
+		os.Remove(n.lock.Name())
+		n.lock = nil
+	}
+}
+
+// hasWallet reports whether the data directory holds a wallet.
+func (n *node) hasWallet() bool {
+	_, err := os.Stat(n.path(walletFile))
+	return err == nil
+}
+
+// loadWallet opens the wallet stored in the data directory.
+func (n *node) loadWallet(password []byte) (*wallet.Wallet, error) {
+	if !n.hasWallet() {
+		return nil, fmt.Errorf("no wallet in %s, create one with 'gochain wallet new'", n.dir)
+	}
+	w := wallet.New(n.chain, n.pool, netParams)
+	if err := w.LoadKeystore(n.path(walletFile), password); err != nil {
+		return nil, fmt.Errorf("failed to open wallet: %w", err)
+	}
+	return w, nil
+}
+
+// saveWallet writes w to the data directory.
+func (n *node) saveWallet(w *wallet.Wallet, password []byte) error {
+	return w.SaveKeystore(n.path(walletFile), password)
+}
+
+// readPassword returns the wallet password from file, or from $GOCHAIN_PASSWORD
+// if file is empty.
+func readPassword(file string) ([]byte, error) {
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(strings.TrimRight(string(data), "\r\n")), nil
+	}
+	if password := os.Getenv(passwordEnv); password != "" {
+		return []byte(password), nil
+	}
+	return nil, usagef("the wallet password must be given with --password-file or $%s", passwordEnv)
+}
@@ -1,160 +1,230 @@
+// Command gochain runs a go-chain node and manages its chain, transactions
+// and wallet from the command line.
+//
+// Every command is non-interactive and takes --datadir and --json flags, so
+// it can be used from scripts. The exit code is 0 on success, 1 when the
+// command fails and 2 when the command line is invalid. The interactive menu
+// of earlier versions is available as "gochain console".
 package main
 
 import (
-	"bufio"
-	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"github.com/NicholasRodrigues/go-chain/internal/blockchain"
-	"github.com/NicholasRodrigues/go-chain/internal/transactions"
-	"github.com/NicholasRodrigues/go-chain/pkg/crypto"
+	"io"
 	"os"
-	"strconv"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/NicholasRodrigues/go-chain/internal/blockchain"
 )
 
-func main() {
-	bc := blockchain.NewBlockchain()
-	scanner := bufio.NewScanner(os.Stdin)
+// Exit codes returned by gochain.
+const (
+	exitOK    = 0
+	exitError = 1 // The command failed
+	exitUsage = 2 // The command line is invalid
+)
 
-	for {
-		printMenu()
-		scanner.Scan()
-		cmd := scanner.Text()
-
-		switch cmd {
-		case "1":
-			handleAddBlock(bc, scanner)
-		case "2":
-			handleViewBlockchain(bc)
-		case "3":
-			handleValidateBlockchain(bc)
-		case "4":
-			handleCreateTransaction(scanner)
-		case "5":
-			handleValidateTransaction(scanner)
-		case "6":
-			handleExit()
-		default:
-			fmt.Println("Invalid command. Please try again.")
-		}
-	}
+// chainParams is the network selected with --network, and netParams its
+// address prefixes.
+var (
+	chainParams = &blockchain.MainNetParams
+	netParams   = chainParams.AddressParams
+)
+
+// command is a gochain subcommand.
+type command struct {
+	args    string // Synopsis of the command's arguments
+	summary string
+	run     func(e *env, args []string) error
 }
 
-func printMenu() {
-	fmt.Println("\nBlockchain CLI")
-	fmt.Println("1. Add Block")
-	fmt.Println("2. View Blockchain")
-	fmt.Println("3. Validate Blockchain")
-	fmt.Println("4. Create Transaction")
-	fmt.Println("5. Validate Transaction")
-	fmt.Println("6. Exit")
-	fmt.Print("Enter command: ")
+var commands = map[string]command{
+	"node start":     {"[--rpcaddr ADDR] [--restaddr ADDR]", "run a node serving JSON-RPC and REST until interrupted", runNodeStart},
+	"chain show":     {"[--height N]", "print the blocks of the chain", runChainShow},
+	"chain validate": {"", "check the proof of work, links, checkpoints and transactions of the chain", runChainValidate},
+	"tx create":      {"--in TXID:VOUT... --out ADDR:AMOUNT... [--locktime N]", "build an unsigned transaction", runTxCreate},
+	"tx sign":        {"HEX|-", "sign the inputs of a transaction spending wallet coins", runTxSign},
+	"tx send":        {"HEX|-", "add a signed transaction to the mempool", runTxSend},
+	"tx decode":      {"HEX|-", "print a serialized transaction", runTxDecode},
+	"psbt create":    {"--in TXID:VOUT... --out ADDR:AMOUNT... [--locktime N]", "build a partially signed transaction", runPSBTCreate},
+	"psbt update":    {"PSBT|- [--redeem-script INDEX:SCRIPT]", "attach the spent outputs and redeem scripts", runPSBTUpdate},
+	"psbt sign":      {"PSBT|- [--keystore FILE]", "sign a partially signed transaction with keystore keys", runPSBTSign},
+	"psbt combine":   {"PSBT PSBT...", "merge the signatures of partially signed transactions", runPSBTCombine},
+	"psbt finalize":  {"PSBT|-", "build the signed transaction to broadcast with tx send", runPSBTFinalize},
+	"psbt decode":    {"PSBT|-", "print a partially signed transaction", runPSBTDecode},
+	"wallet new":     {"", "create a new wallet address, and the wallet if needed", runWalletNew},
+	"wallet balance": {"", "print the wallet's addresses and balance", runWalletBalance},
+	"wallet send":    {"--to ADDR --amount N", "pay an address from the wallet", runWalletSend},
+	"mine":           {"[--blocks N] [--address ADDR]", "mine blocks including the mempool's transactions", runMine},
+	"generate":       {"N [ADDR]", "mine N blocks immediately on regtest", runGenerate},
+	"console":        {"[--rpcaddr ADDR] [--restaddr ADDR]", "start the interactive menu on an in-memory chain", runConsole},
 }
 
-func handleAddBlock(bc *blockchain.Blockchain, scanner *bufio.Scanner) {
-	fmt.Print("Enter data for the new block: ")
-	scanner.Scan()
-	data := scanner.Text()
+// usageError reports an invalid command line.
+type usageError struct {
+	msg      string
+	reported bool // The flag package already printed the error
+}
 
-	input := func() string {
-		return "input data"
-	}
-	receive := func() string {
-		return data
-	}
+func (e *usageError) Error() string {
+	return e.msg
+}
 
-	blockchain.InputContributionFunction([]byte(data), bc, len(bc.Blocks), input, receive)
-	fmt.Println("Block added successfully!")
+func usagef(format string, args ...interface{}) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
 }
 
-func handleViewBlockchain(bc *blockchain.Blockchain) {
-	for i, block := range bc.Blocks {
-		fmt.Printf("Block %d: %x\n", i, block.Hash)
-		fmt.Printf("Previous Hash: %x\n", block.PrevBlockHash)
-		fmt.Printf("Transactions: \n")
-		for _, tx := range block.Transactions {
-			fmt.Printf("  %s\n", tx.String())
-		}
-		fmt.Println()
-	}
+// env holds the I/O streams and the flags shared by all commands.
+type env struct {
+	stdin          io.Reader
+	stdout, stderr io.Writer
+
+	name     string // Name of the running command
+	synopsis string // Arguments of the running command
+	datadir  string // Data directory of the selected network
+	json     bool
+	network  string
+	config   string          // Configuration file given with --config
+	set      map[string]bool // Flags set on the command line or in the configuration file
 }
 
-func handleValidateBlockchain(bc *blockchain.Blockchain) {
-	if blockchain.ChainValidationPredicate(bc) {
-		fmt.Println("Blockchain is valid.")
-	} else {
-		fmt.Println("Blockchain is invalid.")
+// flags returns a flag set for the running command with the shared flags
+// registered.
+func (e *env) flags() *flag.FlagSet {
+	fs := flag.NewFlagSet("gochain "+e.name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.StringVar(&e.datadir, "datadir", defaultDataDir(), "directory holding the chain, mempool and wallet")
+	fs.BoolVar(&e.json, "json", false, "print results as JSON")
+	fs.StringVar(&e.network, "network", blockchain.MainNetParams.Name, "network to use: mainnet, testnet or regtest")
+	fs.StringVar(&e.config, "config", "", "JSON configuration file (default DATADIR/"+configFile+" if it exists)")
+	fs.Usage = func() {
+		fmt.Fprintf(e.stderr, "usage: gochain %s %s\n\n", e.name, e.synopsis)
+		fs.PrintDefaults()
 	}
+	return fs
 }
 
-func handleCreateTransaction(scanner *bufio.Scanner) {
-	fmt.Print("Enter Txid: ")
-	scanner.Scan()
-	txid := scanner.Text()
-
-	fmt.Print("Enter Vout: ")
-	scanner.Scan()
-	vout, _ := strconv.Atoi(scanner.Text())
-
-	fmt.Print("Enter ScriptSig: ")
-	scanner.Scan()
-	scriptSig := scanner.Text()
-
-	fmt.Print("Enter Value: ")
-	scanner.Scan()
-	value, _ := strconv.Atoi(scanner.Text())
+// parse parses args into fs and returns the positional arguments, which must
+// number between min and max. Flags may follow positional arguments. Flags
+// not given on the command line are then read from the configuration file,
+// and the selected network is applied.
+func (e *env) parse(fs *flag.FlagSet, args []string, min, max int) ([]string, error) {
+	var pos []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, &usageError{msg: err.Error(), reported: true}
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		pos = append(pos, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(pos) < min || len(pos) > max {
+		return nil, usagef("usage: gochain %s %s", e.name, e.synopsis)
+	}
 
-	fmt.Print("Enter ScriptPubKey: ")
-	scanner.Scan()
-	scriptPubKey := scanner.Text()
+	e.set = make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		e.set[f.Name] = true
+	})
+	if err := e.applyConfig(fs); err != nil {
+		return nil, err
+	}
 
-	tx := transactions.NewTransaction(
-		[]transactions.TransactionInput{{Txid: []byte(txid), Vout: vout, ScriptSig: scriptSig}},
-		[]transactions.TransactionOutput{{Value: value, ScriptPubKey: scriptPubKey}},
-	)
+	params, err := blockchain.NetworkByName(e.network)
+	if err != nil {
+		return nil, &usageError{msg: err.Error()}
+	}
+	chainParams, netParams = params, params.AddressParams
+	if params != &blockchain.MainNetParams {
+		e.datadir = filepath.Join(e.datadir, params.Name)
+	}
+	return pos, nil
+}
 
-	privKey, _ := crypto.NewPrivateKey()
-	tx.Sign(privKey)
+// print writes v as JSON when --json is set and calls text otherwise.
+func (e *env) print(v interface{}, text func(w io.Writer)) error {
+	if !e.json {
+		text(e.stdout)
+		return nil
+	}
+	enc := json.NewEncoder(e.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
 
-	fmt.Println("Transaction created successfully!")
-	fmt.Println(tx.String())
+// warnf prints a warning on stderr.
+func (e *env) warnf(format string, args ...interface{}) {
+	fmt.Fprintf(e.stderr, "gochain: warning: "+format+"\n", args...)
 }
 
-func handleValidateTransaction(scanner *bufio.Scanner) {
-	fmt.Print("Enter serialized transaction: ")
-	scanner.Scan()
-	serializedTx := scanner.Text()
-	txBytes, _ := hex.DecodeString(serializedTx)
-	tx := transactions.DeserializeTransaction(txBytes)
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
 
-	utxoSet := make(map[string]transactions.TransactionOutput)
-	fmt.Println("Enter UTXO set (enter 'done' to finish):")
-	for {
-		fmt.Print("Enter UTXO key (txid:vout): ")
-		scanner.Scan()
-		key := scanner.Text()
-		if key == "done" {
-			break
+// run executes the command named by args and returns the process exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(stderr)
+		if len(args) == 0 {
+			return exitUsage
 		}
+		return exitOK
+	}
 
-		fmt.Print("Enter value: ")
-		scanner.Scan()
-		value, _ := strconv.Atoi(scanner.Text())
-
-		fmt.Print("Enter ScriptPubKey: ")
-		scanner.Scan()
-		scriptPubKey := scanner.Text()
-
-		utxoSet[key] = transactions.TransactionOutput{Value: value, ScriptPubKey: scriptPubKey}
+	name, rest := args[0], args[1:]
+	if len(rest) > 0 {
+		if _, ok := commands[name+" "+rest[0]]; ok {
+			name, rest = name+" "+rest[0], rest[1:]
+		}
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "gochain: unknown command %q\n\n", strings.Join(args[:min(2, len(args))], " "))
+		printUsage(stderr)
+		return exitUsage
 	}
 
-	if tx.Validate(utxoSet) {
-		fmt.Println("Transaction is valid.")
-	} else {
-		fmt.Println("Transaction is invalid.")
+	e := &env{stdin: stdin, stdout: stdout, stderr: stderr, name: name, synopsis: cmd.args}
+	err := cmd.run(e, rest)
+	var usage *usageError
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.As(err, &usage):
+		if !usage.reported {
+			fmt.Fprintf(stderr, "gochain %s: %v\n", name, err)
+		}
+		return exitUsage
+	default:
+		fmt.Fprintf(stderr, "gochain %s: %v\n", name, err)
+		return exitError
 	}
 }
 
-func handleExit() {
-	fmt.Println("Exiting...")
-	os.Exit(0)
+func printUsage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "usage: gochain <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-16s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Every command accepts --datadir DIR (default $GOCHAIN_DATADIR or ~/.gochain), --network NAME,")
+	fmt.Fprintln(w, "--config FILE and --json. Networks other than mainnet keep their data in DATADIR/NAME.")
+	fmt.Fprintln(w, "Run 'gochain <command> -h' for the flags of a command.")
 }
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NicholasRodrigues/go-chain/internal/blockchain"
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gochain runs the CLI with args and returns its exit code and output.
+func gochain(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// gochainJSON runs the CLI with --json, requires it to succeed and decodes
+// its output into v.
+func gochainJSON(t *testing.T, v interface{}, args ...string) {
+	t.Helper()
+	code, stdout, stderr := gochain(t, "", append(args, "--json")...)
+	require.Equal(t, exitOK, code, stderr)
+	require.NoError(t, json.Unmarshal([]byte(stdout), v), stdout)
+}
+
+func TestUsageErrors(t *testing.T) {
+	datadir := t.TempDir()
+	t.Setenv(passwordEnv, "")
+
+	for _, args := range [][]string{
+		{},
+		{"frobnicate"},
+		{"chain", "frobnicate"},
+		{"mine", "--datadir", datadir, "--blocks", "x"},
+		{"mine", "--datadir", datadir, "--blocks", "0"},
+		{"chain", "show", "--datadir", datadir, "extra"},
+		{"tx", "decode", "zz"},
+		{"tx", "create", "--datadir", datadir, "--in", "ab:0"},
+		{"tx", "create", "--datadir", datadir, "--in", "ab", "--out", "x:1"},
+		{"wallet", "send", "--datadir", datadir, "--to", "nope", "--amount", "1"},
+		{"wallet", "new", "--datadir", datadir},
+	} {
+		code, _, stderr := gochain(t, "", args...)
+		assert.Equal(t, exitUsage, code, "%v: %s", args, stderr)
+		assert.NotEmpty(t, stderr, args)
+	}
+
+	code, _, _ := gochain(t, "", "chain", "show", "-h")
+	assert.Equal(t, exitOK, code)
+}
+
+func TestWalletMineAndSpend(t *testing.T) {
+	datadir := t.TempDir()
+	t.Setenv(passwordEnv, "secret")
+	// Regtest mines the blocks coinbases need to mature quickly.
+	require.NoError(t, os.WriteFile(filepath.Join(datadir, configFile), []byte(`{"network": "regtest"}`), 0600))
+
+	var created newAddressResult
+	gochainJSON(t, &created, "wallet", "new", "--datadir", datadir)
+	assert.NotEmpty(t, created.Mnemonic)
+
+	var second newAddressResult
+	gochainJSON(t, &second, "wallet", "new", "--datadir", datadir)
+	assert.Empty(t, second.Mnemonic, "the wallet already exists")
+	assert.NotEqual(t, created.Address, second.Address)
+
+	var mined []minedBlock
+	gochainJSON(t, &mined, "mine", "--datadir", datadir, "--blocks", "2")
+	require.Len(t, mined, 2)
+	assert.Equal(t, 2, mined[1].Height)
+
+	var balance balanceResult
+	gochainJSON(t, &balance, "wallet", "balance", "--datadir", datadir)
+	assert.Equal(t, balanceResult{Addresses: []string{created.Address, second.Address}, Immature: 100}, balance)
+
+	// The coinbases at heights 1 to 3 are spendable in the block at height 103.
+	gochainJSON(t, &mined, "generate", "--datadir", datadir, "100")
+	require.Len(t, mined, 100)
+	gochainJSON(t, &balance, "wallet", "balance", "--datadir", datadir)
+	assert.Equal(t, 150, balance.Confirmed)
+	assert.Equal(t, 99*50, balance.Immature)
+
+	// Spend the first coinbase by hand through the tx commands.
+	var blocks []struct {
+		Tx []struct {
+			Txid string `json:"txid"`
+		} `json:"tx"`
+	}
+	gochainJSON(t, &blocks, "chain", "show", "--datadir", datadir, "--height", "1")
+	require.Len(t, blocks, 1)
+
+	var unsigned txResult
+	gochainJSON(t, &unsigned, "tx", "create", "--datadir", datadir, "--in", blocks[0].Tx[0].Txid+":0", "--out", second.Address+":50")
+
+	var signed txResult
+	gochainJSON(t, &signed, "tx", "sign", "--datadir", datadir, unsigned.Hex)
+	assert.Equal(t, unsigned.Txid, signed.Txid)
+	require.NotNil(t, signed.Complete)
+	assert.True(t, *signed.Complete)
+
+	code, stdout, stderr := gochain(t, signed.Hex, "tx", "send", "--datadir", datadir, "-")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, signed.Txid+"\n", stdout)
+
+	code, _, _ = gochain(t, "", "tx", "send", "--datadir", datadir, signed.Hex)
+	assert.Equal(t, exitError, code, "the transaction is already pending")
+
+	// The wallet pays from another mature coinbase.
+	var sent sendResult
+	gochainJSON(t, &sent, "wallet", "send", "--datadir", datadir, "--to", created.Address, "--amount", "20")
+
+	gochainJSON(t, &mined, "mine", "--datadir", datadir, "--address", second.Address)
+	require.Len(t, mined, 1)
+	assert.Equal(t, 3, mined[0].Txs)
+
+	code, stdout, _ = gochain(t, "", "chain", "validate", "--datadir", datadir)
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "height 103")
+
+	gochainJSON(t, &balance, "wallet", "balance", "--datadir", datadir)
+	assert.Equal(t, 0, balance.Unconfirmed)
+	assert.Equal(t, 99*50, balance.Immature)
+	// Coins paid between wallet addresses stay in the wallet, less the fee.
+	assert.Less(t, balance.Confirmed, 200)
+	assert.Greater(t, balance.Confirmed, 190)
+}
+
+func TestDataDirLock(t *testing.T) {
+	datadir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(datadir, lockFile), []byte("1\n"), 0600))
+
+	code, _, stderr := gochain(t, "", "mine", "--datadir", datadir, "--address", "gc1qvt6h95mx8lqwuy5qxxf63gcj0nkwtvwaqhze4keq85gmg3uvdadszht8xd")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "in use")
+
+	// Read-only commands ignore the lock and leave the directory untouched.
+	code, _, stderr = gochain(t, "", "chain", "validate", "--datadir", datadir)
+	assert.Equal(t, exitOK, code, stderr)
+	assert.NoFileExists(t, filepath.Join(datadir, chainFile))
+}
+
+func TestPSBTWorkflow(t *testing.T) {
+	datadir, offline := t.TempDir(), t.TempDir()
+	t.Setenv(passwordEnv, "secret")
+	// Regtest mines the blocks the coinbase needs to mature quickly.
+	require.NoError(t, os.WriteFile(filepath.Join(datadir, configFile), []byte(`{"network": "regtest"}`), 0600))
+
+	var addr newAddressResult
+	gochainJSON(t, &addr, "wallet", "new", "--datadir", datadir)
+	var mined []minedBlock
+	gochainJSON(t, &mined, "generate", "--datadir", datadir, "101")
+
+	var blocks []struct {
+		Tx []struct {
+			Txid string `json:"txid"`
+		} `json:"tx"`
+	}
+	gochainJSON(t, &blocks, "chain", "show", "--datadir", datadir, "--height", "1")
+
+	var created psbtResult
+	gochainJSON(t, &created, "psbt", "create", "--network", "regtest", "--in", blocks[0].Tx[0].Txid+":0", "--out", addr.Address+":49")
+
+	// Signing offline fails without the previous output.
+	keystorePath := filepath.Join(datadir, "regtest", walletFile)
+	var signed psbtResult
+	gochainJSON(t, &signed, "psbt", "sign", "--datadir", offline, "--keystore", keystorePath, created.PSBT)
+	assert.Equal(t, 0, *signed.Signed)
+
+	var updated psbtResult
+	gochainJSON(t, &updated, "psbt", "update", "--datadir", datadir, created.PSBT)
+	gochainJSON(t, &signed, "psbt", "sign", "--datadir", offline, "--keystore", keystorePath, updated.PSBT)
+	assert.Equal(t, 1, *signed.Signed)
+
+	var info psbtInfo
+	gochainJSON(t, &info, "psbt", "decode", signed.PSBT)
+	assert.True(t, info.Complete)
+	require.NotNil(t, info.Fee)
+	assert.Equal(t, 1, *info.Fee)
+
+	var combined psbtResult
+	gochainJSON(t, &combined, "psbt", "combine", updated.PSBT, signed.PSBT)
+
+	code, stdout, stderr := gochain(t, combined.PSBT, "psbt", "finalize", "-")
+	require.Equal(t, exitOK, code, stderr)
+	code, _, stderr = gochain(t, "", "tx", "send", "--datadir", datadir, strings.TrimSpace(stdout))
+	assert.Equal(t, exitOK, code, stderr)
+
+	code, _, stderr = gochain(t, "", "psbt", "finalize", updated.PSBT)
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "not fully signed")
+}
+
+func TestConfigAndNetwork(t *testing.T) {
+	datadir := t.TempDir()
+	t.Setenv(passwordEnv, "secret")
+	config := filepath.Join(datadir, configFile)
+	require.NoError(t, os.WriteFile(config, []byte(`{"network": "regtest", "restaddr": ""}`), 0600))
+
+	var created newAddressResult
+	gochainJSON(t, &created, "wallet", "new", "--datadir", datadir)
+	assert.True(t, strings.HasPrefix(created.Address, "gcrt1"), created.Address)
+	assert.FileExists(t, filepath.Join(datadir, "regtest", walletFile))
+
+	var mined []minedBlock
+	gochainJSON(t, &mined, "mine", "--datadir", datadir, "--blocks", "2")
+	require.Len(t, mined, 2)
+
+	// Flags override the configuration file.
+	var result validateResult
+	gochainJSON(t, &result, "chain", "validate", "--datadir", datadir, "--network", "mainnet")
+	assert.Equal(t, validateResult{Valid: true, Height: 0}, result)
+	gochainJSON(t, &result, "chain", "validate", "--datadir", datadir)
+	assert.Equal(t, validateResult{Valid: true, Height: 2}, result)
+
+	code, _, stderr := gochain(t, "", "chain", "validate", "--config", filepath.Join(datadir, "missing.json"))
+	assert.Equal(t, exitError, code, stderr)
+
+	for _, content := range []string{`{"difficulty": 1}`, `{"network": "simnet"}`, `{"network": true}`, `{"rpcaddr": 1e+06}`, `{"network": "regtest"} {}`, `not json`} {
+		require.NoError(t, os.WriteFile(config, []byte(content), 0600))
+		code, _, stderr = gochain(t, "", "chain", "validate", "--datadir", datadir)
+		assert.Equal(t, exitUsage, code, "%s: %s", content, stderr)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	datadir := t.TempDir()
+	t.Setenv(passwordEnv, "secret")
+
+	code, _, stderr := gochain(t, "", "generate", "1", "--datadir", datadir)
+	assert.Equal(t, exitUsage, code, "generate is refused on mainnet: %s", stderr)
+
+	var created newAddressResult
+	gochainJSON(t, &created, "wallet", "new", "--datadir", datadir, "--network", "regtest")
+
+	var mined []minedBlock
+	gochainJSON(t, &mined, "generate", "3", "--datadir", datadir, "--network", "regtest")
+	require.Len(t, mined, 3)
+	assert.Equal(t, 3, mined[2].Height)
+
+	var balance balanceResult
+	gochainJSON(t, &balance, "wallet", "balance", "--datadir", datadir, "--network", "regtest")
+	assert.Equal(t, 150, balance.Immature, "fresh coinbases are immature")
+
+	code, _, stderr = gochain(t, "", "generate", "1", created.Address, "--datadir", datadir, "--network", "regtest")
+	assert.Equal(t, exitOK, code, stderr)
+	for _, args := range [][]string{{"generate", "x"}, {"generate", "1001"}, {"mine", "--blocks", "1001"}} {
+		code, _, _ = gochain(t, "", append(args, "--datadir", datadir, "--network", "regtest")...)
+		assert.Equal(t, exitUsage, code, args)
+	}
+}
+
+func TestSaveOnBlocks(t *testing.T) {
+	defer func(params *blockchain.ChainParams) { chainParams = params }(chainParams)
+	chainParams = &blockchain.RegTestParams
+
+	n, err := openNode(t.TempDir(), false)
+	require.NoError(t, err)
+	defer n.close()
+	var stderr bytes.Buffer
+	stop := saveOnBlocks(&stderr, n)
+
+	chainLock.Lock()
+	_, err = n.chain.MineBlock(transactions.PayToPubKeyHashScript(make([]byte, 20)), nil)
+	chainLock.Unlock()
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		saved, err := blockchain.LoadBlockchain(n.path(chainFile), chainParams)
+		return err == nil && saved.Height() == 1
+	}, 5*time.Second, 10*time.Millisecond, "the block is saved while the node runs")
+	stop()
+	assert.Empty(t, stderr.String())
+}
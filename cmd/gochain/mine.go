@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/NicholasRodrigues/go-chain/internal/blockchain"
+	"github.com/NicholasRodrigues/go-chain/internal/mempool"
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+	"github.com/NicholasRodrigues/go-chain/pkg/address"
+)
+
+// maxMineBlocks bounds the blocks mined by one command, like the generate
+// RPC method.
+const maxMineBlocks = 1000
+
+// minedBlock is the JSON output of mine for each block.
+type minedBlock struct {
+	Height int    `json:"height"`
+	Hash   string `json:"hash"`
+	Txs    int    `json:"txs"`
+}
+
+// mineBlock mines a block paying the subsidy to addr and including the pool's
+// transactions that are still valid, which are then removed from the pool.
+func mineBlock(bc *blockchain.Blockchain, pool *mempool.Mempool, addr *address.Address) (minedBlock, error) {
+	block, err := bc.MineBlock(transactions.PayToAddrScript(addr), pool.Revalidate())
+	if err != nil {
+		return minedBlock{}, err
+	}
+	pool.RemoveBlock(block)
+	return minedBlock{Height: len(bc.Blocks) - 1, Hash: hex.EncodeToString(block.Hash), Txs: len(block.Transactions)}, nil
+}
+
+func runMine(e *env, args []string) error {
+	fs := e.flags()
+	count := fs.Int("blocks", 1, "number of blocks to mine")
+	addrFlag := fs.String("address", "", "address receiving the rewards (default the wallet's first address)")
+	passwordFile := fs.String("password-file", "", "file holding the wallet password (default $"+passwordEnv+")")
+	if _, err := e.parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if *count < 1 || *count > maxMineBlocks {
+		return usagef("--blocks must be between 1 and %d", maxMineBlocks)
+	}
+	return e.mine(*count, *addrFlag, *passwordFile)
+}
+
+func runGenerate(e *env, args []string) error {
+	fs := e.flags()
+	passwordFile := fs.String("password-file", "", "file holding the wallet password (default $"+passwordEnv+")")
+	pos, err := e.parse(fs, args, 1, 2)
+	if err != nil {
+		return err
+	}
+	count, err := strconv.Atoi(pos[0])
+	if err != nil || count < 1 || count > maxMineBlocks {
+		return usagef("invalid block count %q, it must be between 1 and %d", pos[0], maxMineBlocks)
+	}
+	if !chainParams.RegTest {
+		return usagef("generate is only available with --network regtest, use mine on %s", chainParams.Name)
+	}
+
+	var addr string
+	if len(pos) == 2 {
+		addr = pos[1]
+	}
+	return e.mine(count, addr, *passwordFile)
+}
+
+// mine mines count blocks paying addr, or the wallet's first address if addr
+// is empty, and prints them.
+func (e *env) mine(count int, addrArg, passwordFile string) error {
+	var addr *address.Address
+	if addrArg != "" {
+		var err error
+		if addr, err = address.Decode(addrArg, netParams); err != nil {
+			return usagef("invalid address: %v", err)
+		}
+	}
+
+	n, err := openNode(e.datadir, false)
+	if err != nil {
+		return err
+	}
+	defer n.close()
+
+	if addr == nil {
+		password, err := readPassword(passwordFile)
+		if err != nil {
+			return err
+		}
+		w, err := n.loadWallet(password)
+		if err != nil {
+			return err
+		}
+		addrs := w.Addresses()
+		if len(addrs) == 0 {
+			return fmt.Errorf("the wallet has no address, create one with 'gochain wallet new'")
+		}
+		addr = addrs[0]
+	}
+
+	var mined []minedBlock
+	var mineErr error
+	for i := 0; i < count && mineErr == nil; i++ {
+		var b minedBlock
+		if b, mineErr = mineBlock(n.chain, n.pool, addr); mineErr == nil {
+			mined = append(mined, b)
+		}
+	}
+	// Blocks mined before a failure are kept.
+	if err := n.save(); err != nil {
+		return err
+	}
+	if mineErr != nil {
+		return mineErr
+	}
+
+	return e.print(mined, func(w io.Writer) {
+		for _, b := range mined {
+			fmt.Fprintf(w, "Mined block %d %s with %d transactions\n", b.Height, b.Hash, b.Txs)
+		}
+	})
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/NicholasRodrigues/go-chain/internal/blockchain"
+	"github.com/NicholasRodrigues/go-chain/internal/mempool"
+	"github.com/NicholasRodrigues/go-chain/internal/rest"
+	"github.com/NicholasRodrigues/go-chain/internal/rpc"
+	"github.com/NicholasRodrigues/go-chain/internal/wallet"
+)
+
+// chainLock is held for writing while the chain is modified, so that RPC and
+// REST requests never observe a partially added block.
+var chainLock sync.RWMutex
+
+// services are the API servers of a running node.
+type services struct {
+	rpc  *rpc.Server
+	rest *rest.Server
+	errc chan error // Receives the error of a server that stopped
+}
+
+// startServices serves JSON-RPC on rpcAddr and REST on restAddr in the
+// background. An empty address disables the server.
+func startServices(out io.Writer, bc *blockchain.Blockchain, pool *mempool.Mempool, w *wallet.Wallet, rpcAddr, cookiePath, restAddr string) (*services, error) {
+	s := &services{errc: make(chan error, 2)}
+	if rpcAddr != "" {
+		server, err := rpc.New(rpc.Config{
+			Chain:      bc,
+			Pool:       pool,
+			Wallet:     w,
+			Params:     netParams,
+			ChainLock:  &chainLock,
+			CookiePath: cookiePath,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to start RPC server: %w", err)
+		}
+		s.rpc = server
+		go func() {
+			s.errc <- fmt.Errorf("RPC server stopped: %w", server.ListenAndServe(rpcAddr))
+		}()
+		fmt.Fprintf(out, "JSON-RPC listening on %s, credentials in %s\n", rpcAddr, cookiePath)
+	}
+	if restAddr != "" {
+		s.rest = rest.New(rest.Config{Chain: bc, Pool: pool, Params: netParams, ChainLock: &chainLock})
+		go func() {
+			s.errc <- fmt.Errorf("REST server stopped: %w", s.rest.ListenAndServe(restAddr))
+		}()
+		fmt.Fprintf(out, "REST API listening on %s\n", restAddr)
+	}
+	return s, nil
+}
+
+// close stops the servers and removes the RPC cookie.
+func (s *services) close() {
+	if s.rpc != nil {
+		s.rpc.Close()
+	}
+	if s.rest != nil {
+		s.rest.Close()
+	}
+}
+
+// saveOnBlocks saves n whenever a block is connected or disconnected, so a
+// node that stops abruptly keeps the blocks it accepted. Failures are
+// reported to out. The returned function stops saving and waits for a save
+// in progress.
+func saveOnBlocks(out io.Writer, n *node) (stop func()) {
+	// A single queued event is enough: the save it triggers happens after
+	// any event dropped while it was queued, and sees that block too.
+	sub := n.chain.Events().Subscribe(1, func(e blockchain.Event) bool {
+		return e.Type == blockchain.BlockConnected || e.Type == blockchain.BlockDisconnected
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range sub.Events() {
+			chainLock.RLock()
+			err := n.save()
+			chainLock.RUnlock()
+			if err != nil {
+				fmt.Fprintf(out, "Failed to save the chain: %v\n", err)
+			}
+		}
+	}()
+	return func() {
+		sub.Close()
+		<-done
+	}
+}
+
+// defaultAddrs replaces the API addresses not set by flags or configuration
+// with the loopback addresses on the selected network's ports.
+func (e *env) defaultAddrs(rpcAddr, restAddr *string) {
+	if !e.set["rpcaddr"] {
+		*rpcAddr = fmt.Sprintf("127.0.0.1:%d", chainParams.RPCPort)
+	}
+	if !e.set["restaddr"] {
+		*restAddr = fmt.Sprintf("127.0.0.1:%d", chainParams.RESTPort)
+	}
+}
+
+func runNodeStart(e *env, args []string) error {
+	fs := e.flags()
+	rpcAddr := fs.String("rpcaddr", rpc.DefaultAddr, "JSON-RPC loopback listen address, empty to disable (default port set by the network)")
+	rpcCookie := fs.String("rpccookie", "", "file receiving the JSON-RPC credentials (default DATADIR/"+cookieFile+")")
+	restAddr := fs.String("restaddr", rest.DefaultAddr, "REST API loopback listen address, empty to disable (default port set by the network)")
+	passwordFile := fs.String("password-file", "", "file holding the wallet password (default $"+passwordEnv+")")
+	if _, err := e.parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	e.defaultAddrs(rpcAddr, restAddr)
+
+	n, err := openNode(e.datadir, false)
+	if err != nil {
+		return err
+	}
+	defer n.close()
+
+	// The wallet is optional: without one the RPC wallet methods fail.
+	var w *wallet.Wallet
+	if n.hasWallet() {
+		password, err := readPassword(*passwordFile)
+		if err != nil {
+			return err
+		}
+		if w, err = n.loadWallet(password); err != nil {
+			return err
+		}
+	}
+
+	cookiePath := *rpcCookie
+	if cookiePath == "" {
+		cookiePath = n.path(cookieFile)
+	}
+	stopSaving := saveOnBlocks(e.stderr, n)
+	defer stopSaving()
+	svc, err := startServices(e.stderr, n.chain, n.pool, w, *rpcAddr, cookiePath, *restAddr)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stderr, "Node running on %s at height %d\n", e.datadir, len(n.chain.Blocks)-1)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	var stopErr error
+	select {
+	case sig := <-signals:
+		fmt.Fprintf(e.stderr, "Received %s, shutting down\n", sig)
+	case stopErr = <-svc.errc:
+	}
+	svc.close()
+	stopSaving()
+
+	chainLock.Lock()
+	defer chainLock.Unlock()
+	if err := n.save(); err != nil {
+		return err
+	}
+	return stopErr
+}
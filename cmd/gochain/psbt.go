@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/NicholasRodrigues/go-chain/internal/psbt"
+	"github.com/NicholasRodrigues/go-chain/pkg/keystore"
+)
+
+// psbtResult is the JSON output of the psbt commands producing a packet.
+type psbtResult struct {
+	PSBT   string `json:"psbt"`
+	Signed *int   `json:"signed,omitempty"` // Signatures added by psbt sign
+}
+
+// psbtInfo is the JSON output of psbt decode.
+type psbtInfo struct {
+	Txid     string       `json:"txid"`
+	Packet   *psbt.Packet `json:"packet"`
+	Fee      *int         `json:"fee,omitempty"` // Known once every previous output is
+	Complete bool         `json:"complete"`      // Whether psbt finalize would succeed
+}
+
+// readPacket decodes the packet given as arg, or read from stdin if arg is "-".
+func readPacket(e *env, arg string) (*psbt.Packet, error) {
+	if arg == "-" {
+		data, err := io.ReadAll(e.stdin)
+		if err != nil {
+			return nil, err
+		}
+		arg = string(data)
+	}
+	p, err := psbt.Decode(arg)
+	if err != nil {
+		return nil, usagef("invalid packet: %v", err)
+	}
+	return p, nil
+}
+
+// printPacket prints the encoding of p.
+func (e *env) printPacket(result psbtResult, p *psbt.Packet) error {
+	encoded, err := p.Encode()
+	if err != nil {
+		return err
+	}
+	result.PSBT = encoded
+	return e.print(result, func(w io.Writer) {
+		fmt.Fprintln(w, result.PSBT)
+	})
+}
+
+func runPSBTCreate(e *env, args []string) error {
+	fs := e.flags()
+	var ins, outs listFlag
+	fs.Var(&ins, "in", "outpoint TXID:VOUT to spend (repeatable)")
+	fs.Var(&outs, "out", "payment ADDR:AMOUNT (repeatable)")
+	lockTime := fs.Int64("locktime", 0, "block height or Unix time before which the transaction is not final")
+	if _, err := e.parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	tx, err := buildTransaction(ins, outs, *lockTime)
+	if err != nil {
+		return err
+	}
+	p, err := psbt.New(tx)
+	if err != nil {
+		return err
+	}
+	return e.printPacket(psbtResult{}, p)
+}
+
+func runPSBTUpdate(e *env, args []string) error {
+	fs := e.flags()
+	var redeemScripts listFlag
+	fs.Var(&redeemScripts, "redeem-script", "redeem script INDEX:SCRIPT of a pay-to-script-hash input (repeatable)")
+	pos, err := e.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	p, err := readPacket(e, pos[0])
+	if err != nil {
+		return err
+	}
+
+	n, err := openNode(e.datadir, true)
+	if err != nil {
+		return err
+	}
+	p.UpdateFromUTXOs(n.chain.UTXOSet().Outputs())
+	for i, in := range p.Inputs {
+		if in.PrevOut == nil {
+			e.warnf("input %d spends an output that is not in the chain's UTXO set", i)
+		}
+	}
+
+	for _, s := range redeemScripts {
+		index, script, ok := strings.Cut(s, ":")
+		i, err := strconv.Atoi(index)
+		if !ok || err != nil {
+			return usagef("invalid --redeem-script %q, want INDEX:SCRIPT", s)
+		}
+		if err := p.SetRedeemScript(i, script); err != nil {
+			return err
+		}
+	}
+	return e.printPacket(psbtResult{}, p)
+}
+
+func runPSBTSign(e *env, args []string) error {
+	fs := e.flags()
+	keystorePath := fs.String("keystore", "", "keystore holding the signing keys (default DATADIR/"+walletFile+")")
+	passwordFile := fs.String("password-file", "", "file holding the keystore password (default $"+passwordEnv+")")
+	pos, err := e.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	p, err := readPacket(e, pos[0])
+	if err != nil {
+		return err
+	}
+	password, err := readPassword(*passwordFile)
+	if err != nil {
+		return err
+	}
+
+	// Signing only needs the keystore, so it works on machines without a chain.
+	path := *keystorePath
+	if path == "" {
+		path = (&node{dir: e.datadir}).path(walletFile)
+	}
+	secrets, err := keystore.Load(path, password)
+	if err != nil {
+		return err
+	}
+
+	signed := 0
+	for _, key := range secrets.Keys {
+		n, err := p.Sign(key)
+		if err != nil {
+			return err
+		}
+		signed += n
+	}
+	if signed == 0 {
+		e.warnf("no input could be signed with the keys of %s", path)
+	}
+	return e.printPacket(psbtResult{Signed: &signed}, p)
+}
+
+func runPSBTCombine(e *env, args []string) error {
+	fs := e.flags()
+	pos, err := e.parse(fs, args, 2, math.MaxInt)
+	if err != nil {
+		return err
+	}
+
+	var packets []*psbt.Packet
+	for _, arg := range pos {
+		p, err := readPacket(e, arg)
+		if err != nil {
+			return err
+		}
+		packets = append(packets, p)
+	}
+	combined, err := psbt.Combine(packets...)
+	if err != nil {
+		return err
+	}
+	return e.printPacket(psbtResult{}, combined)
+}
+
+func runPSBTFinalize(e *env, args []string) error {
+	fs := e.flags()
+	pos, err := e.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	p, err := readPacket(e, pos[0])
+	if err != nil {
+		return err
+	}
+
+	tx, err := p.Finalize()
+	if err != nil {
+		return err
+	}
+	return e.printTx(txResult{}, tx)
+}
+
+func runPSBTDecode(e *env, args []string) error {
+	fs := e.flags()
+	pos, err := e.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	p, err := readPacket(e, pos[0])
+	if err != nil {
+		return err
+	}
+
+	info := psbtInfo{Txid: hex.EncodeToString(p.Tx.ID), Packet: p}
+	if fee, ok := p.Fee(); ok {
+		info.Fee = &fee
+	}
+	_, err = p.Finalize()
+	info.Complete = err == nil
+
+	return e.print(info, func(w io.Writer) {
+		printTransaction(w, p.Tx)
+		for i, in := range p.Inputs {
+			prevOut := "unknown"
+			if in.PrevOut != nil {
+				prevOut = fmt.Sprintf("%d to %s", in.PrevOut.Value, in.PrevOut.ScriptPubKey)
+			}
+			fmt.Fprintf(w, "  Input %d spends %s, %d signatures\n", i, prevOut, len(in.PartialSigs))
+		}
+		if info.Fee != nil {
+			fmt.Fprintf(w, "  Fee: %d\n", *info.Fee)
+		}
+		fmt.Fprintf(w, "  Complete: %t\n", info.Complete)
+	})
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/NicholasRodrigues/go-chain/internal/schema"
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+)
+
+// txResult is the JSON output of the tx commands producing a transaction.
+type txResult struct {
+	Txid     string `json:"txid"`
+	Hex      string `json:"hex"`
+	Signed   *int   `json:"signed,omitempty"`   // Inputs signed by tx sign
+	Complete *bool  `json:"complete,omitempty"` // Whether tx sign produced a valid transaction
+}
+
+// listFlag collects the values of a repeated flag.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// parseInput parses an outpoint given as TXID:VOUT.
+func parseInput(s string) (transactions.TransactionInput, error) {
+	txid, vout, ok := strings.Cut(s, ":")
+	if !ok {
+		return transactions.TransactionInput{}, usagef("invalid input %q, want TXID:VOUT", s)
+	}
+	id, err := hex.DecodeString(txid)
+	if err != nil || len(id) == 0 {
+		return transactions.TransactionInput{}, usagef("invalid input %q: bad transaction ID", s)
+	}
+	index, err := strconv.Atoi(vout)
+	if err != nil || index < 0 {
+		return transactions.TransactionInput{}, usagef("invalid input %q: bad output index", s)
+	}
+	return transactions.TransactionInput{Txid: id, Vout: index}, nil
+}
+
+// parseOutput parses an output given as ADDR:AMOUNT.
+func parseOutput(s string) (transactions.TransactionOutput, error) {
+	addr, amount, ok := strings.Cut(s, ":")
+	if !ok {
+		return transactions.TransactionOutput{}, usagef("invalid output %q, want ADDR:AMOUNT", s)
+	}
+	script, err := scriptForAddress(addr)
+	if err != nil {
+		return transactions.TransactionOutput{}, usagef("invalid output %q: %v", s, err)
+	}
+	value, err := strconv.Atoi(amount)
+	if err != nil || value <= 0 {
+		return transactions.TransactionOutput{}, usagef("invalid output %q: bad amount", s)
+	}
+	return transactions.TransactionOutput{Value: value, ScriptPubKey: script}, nil
+}
+
+// readTransaction decodes the hex transaction given as arg, or read from
+// stdin if arg is "-".
+func readTransaction(e *env, arg string) (*transactions.Transaction, error) {
+	if arg == "-" {
+		data, err := io.ReadAll(e.stdin)
+		if err != nil {
+			return nil, err
+		}
+		arg = string(data)
+	}
+	data, err := hex.DecodeString(strings.TrimSpace(arg))
+	if err != nil {
+		return nil, usagef("invalid transaction hex: %v", err)
+	}
+	tx, err := transactions.DecodeTransaction(data)
+	if err != nil {
+		return nil, usagef("invalid transaction: %v", err)
+	}
+	return tx, nil
+}
+
+// printTx prints the serialization of tx.
+func (e *env) printTx(result txResult, tx *transactions.Transaction) error {
+	result.Txid = hex.EncodeToString(tx.ID)
+	result.Hex = hex.EncodeToString(tx.Serialize())
+	return e.print(result, func(w io.Writer) {
+		fmt.Fprintln(w, result.Hex)
+	})
+}
+
+// buildTransaction builds an unsigned transaction from the values of the
+// --in, --out and --locktime flags.
+func buildTransaction(ins, outs []string, lockTime int64) (*transactions.Transaction, error) {
+	if len(ins) == 0 || len(outs) == 0 {
+		return nil, usagef("at least one --in and one --out are required")
+	}
+
+	tx := &transactions.Transaction{LockTime: lockTime}
+	for _, s := range ins {
+		in, err := parseInput(s)
+		if err != nil {
+			return nil, err
+		}
+		tx.Vin = append(tx.Vin, in)
+	}
+	for _, s := range outs {
+		out, err := parseOutput(s)
+		if err != nil {
+			return nil, err
+		}
+		tx.Vout = append(tx.Vout, out)
+	}
+	tx.SetID()
+	return tx, nil
+}
+
+func runTxCreate(e *env, args []string) error {
+	fs := e.flags()
+	var ins, outs listFlag
+	fs.Var(&ins, "in", "outpoint TXID:VOUT to spend (repeatable)")
+	fs.Var(&outs, "out", "payment ADDR:AMOUNT (repeatable)")
+	lockTime := fs.Int64("locktime", 0, "block height or Unix time before which the transaction is not final")
+	if _, err := e.parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	tx, err := buildTransaction(ins, outs, *lockTime)
+	if err != nil {
+		return err
+	}
+	return e.printTx(txResult{}, tx)
+}
+
+func runTxSign(e *env, args []string) error {
+	fs := e.flags()
+	passwordFile := fs.String("password-file", "", "file holding the wallet password (default $"+passwordEnv+")")
+	pos, err := e.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	tx, err := readTransaction(e, pos[0])
+	if err != nil {
+		return err
+	}
+	password, err := readPassword(*passwordFile)
+	if err != nil {
+		return err
+	}
+
+	n, err := openNode(e.datadir, true)
+	if err != nil {
+		return err
+	}
+	w, err := n.loadWallet(password)
+	if err != nil {
+		return err
+	}
+
+	signed, err := w.SignTransaction(tx)
+	if err != nil {
+		return err
+	}
+	complete := tx.Validate(n.chain.UTXOSet().Outputs())
+	if !complete {
+		e.warnf("the transaction is not complete, %d of %d inputs signed", signed, len(tx.Vin))
+	}
+	return e.printTx(txResult{Signed: &signed, Complete: &complete}, tx)
+}
+
+func runTxSend(e *env, args []string) error {
+	fs := e.flags()
+	pos, err := e.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	tx, err := readTransaction(e, pos[0])
+	if err != nil {
+		return err
+	}
+
+	n, err := openNode(e.datadir, false)
+	if err != nil {
+		return err
+	}
+	defer n.close()
+
+	if err := n.pool.Add(tx); err != nil {
+		return err
+	}
+	if err := n.save(); err != nil {
+		return err
+	}
+	result := txResult{Txid: hex.EncodeToString(tx.ID), Hex: hex.EncodeToString(tx.Serialize())}
+	return e.print(result, func(w io.Writer) {
+		fmt.Fprintln(w, result.Txid)
+	})
+}
+
+func runTxDecode(e *env, args []string) error {
+	fs := e.flags()
+	pos, err := e.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	tx, err := readTransaction(e, pos[0])
+	if err != nil {
+		return err
+	}
+	return e.print(schema.NewTransaction(tx, netParams), func(w io.Writer) {
+		printTransaction(w, tx)
+	})
+}
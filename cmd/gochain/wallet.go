@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/NicholasRodrigues/go-chain/internal/wallet"
+	"github.com/NicholasRodrigues/go-chain/pkg/address"
+	"github.com/NicholasRodrigues/go-chain/pkg/mnemonic"
+)
+
+// newAddressResult is the JSON output of wallet new.
+type newAddressResult struct {
+	Address  string `json:"address"`
+	Mnemonic string `json:"mnemonic,omitempty"` // Recovery phrase of a newly created wallet
+}
+
+// balanceResult is the JSON output of wallet balance.
+type balanceResult struct {
+	Addresses   []string `json:"addresses"`
+	Confirmed   int      `json:"confirmed"`
+	Immature    int      `json:"immature"`
+	Unconfirmed int      `json:"unconfirmed"`
+}
+
+// sendResult is the JSON output of wallet send.
+type sendResult struct {
+	Txid string `json:"txid"`
+}
+
+func runWalletNew(e *env, args []string) error {
+	fs := e.flags()
+	passwordFile := fs.String("password-file", "", "file holding the wallet password (default $"+passwordEnv+")")
+	if _, err := e.parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	password, err := readPassword(*passwordFile)
+	if err != nil {
+		return err
+	}
+
+	n, err := openNode(e.datadir, false)
+	if err != nil {
+		return err
+	}
+	defer n.close()
+
+	var result newAddressResult
+	var w *wallet.Wallet
+	if n.hasWallet() {
+		w, err = n.loadWallet(password)
+	} else {
+		result.Mnemonic, err = mnemonic.Generate(mnemonicBits)
+		if err == nil {
+			w, err = wallet.NewFromMnemonic(n.chain, n.pool, netParams, result.Mnemonic, "")
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	addr, err := w.NewAddress()
+	if err != nil {
+		return err
+	}
+	if err := n.saveWallet(w, password); err != nil {
+		return err
+	}
+	result.Address = addr.String()
+
+	return e.print(result, func(out io.Writer) {
+		if result.Mnemonic != "" {
+			fmt.Fprintln(out, "New wallet recovery phrase (write it down):")
+			fmt.Fprintln(out, result.Mnemonic)
+		}
+		fmt.Fprintln(out, result.Address)
+	})
+}
+
+func runWalletBalance(e *env, args []string) error {
+	fs := e.flags()
+	passwordFile := fs.String("password-file", "", "file holding the wallet password (default $"+passwordEnv+")")
+	if _, err := e.parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	password, err := readPassword(*passwordFile)
+	if err != nil {
+		return err
+	}
+
+	n, err := openNode(e.datadir, true)
+	if err != nil {
+		return err
+	}
+	w, err := n.loadWallet(password)
+	if err != nil {
+		return err
+	}
+
+	balance := w.Balance()
+	result := balanceResult{Addresses: []string{}, Confirmed: balance.Confirmed, Immature: balance.Immature, Unconfirmed: balance.Unconfirmed}
+	for _, addr := range w.Addresses() {
+		result.Addresses = append(result.Addresses, addr.String())
+	}
+	return e.print(result, func(out io.Writer) {
+		for _, addr := range result.Addresses {
+			fmt.Fprintln(out, "Address:", addr)
+		}
+		fmt.Fprintf(out, "Confirmed balance:   %d\n", result.Confirmed)
+		fmt.Fprintf(out, "Immature balance:    %d\n", result.Immature)
+		fmt.Fprintf(out, "Unconfirmed balance: %d\n", result.Unconfirmed)
+	})
+}
+
+func runWalletSend(e *env, args []string) error {
+	fs := e.flags()
+	to := fs.String("to", "", "address to pay")
+	amount := fs.Int("amount", 0, "amount to pay")
+	passwordFile := fs.String("password-file", "", "file holding the wallet password (default $"+passwordEnv+")")
+	if _, err := e.parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	addr, err := address.Decode(*to, netParams)
+	if err != nil {
+		return usagef("invalid --to address: %v", err)
+	}
+	if *amount <= 0 {
+		return usagef("--amount must be positive")
+	}
+	password, err := readPassword(*passwordFile)
+	if err != nil {
+		return err
+	}
+
+	n, err := openNode(e.datadir, false)
+	if err != nil {
+		return err
+	}
+	defer n.close()
+	w, err := n.loadWallet(password)
+	if err != nil {
+		return err
+	}
+
+	tx, err := w.Send(addr, *amount)
+	if err != nil {
+		return err
+	}
+	// The payment may have derived a change key.
+	if err := n.saveWallet(w, password); err != nil {
+		return err
+	}
+	if err := n.save(); err != nil {
+		return err
+	}
+
+	result := sendResult{Txid: hex.EncodeToString(tx.ID)}
+	return e.print(result, func(out io.Writer) {
+		fmt.Fprintln(out, result.Txid)
+	})
+}
@@ -0,0 +1,162 @@
+package atomicswap
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+	"github.com/NicholasRodrigues/go-chain/pkg/crypto"
+)
+
+// Funding is an unspent output owned by Key that pays for a contract.
+type Funding struct {
+	Txid   []byte
+	Vout   int
+	Output transactions.TransactionOutput
+	Key    crypto.Signer
+}
+
+// Contract is an HTLC output created by Initiate.
+type Contract struct {
+	RedeemScript string
+	FundingTx    *transactions.Transaction
+	Vout         int
+}
+
+// Output returns the contract output of the funding transaction.
+func (c *Contract) Output() transactions.TransactionOutput {
+	return c.FundingTx.Vout[c.Vout]
+}
+
+// AuditReport describes a contract found on chain.
+type AuditReport struct {
+	HTLC  *transactions.HTLC
+	Value int
+}
+
+// NewSecret returns a random secret and the hash that locks it.
+func NewSecret() ([]byte, []byte, error) {
+	secret := make([]byte, transactions.SecretLen)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, nil, err
+	}
+	return secret, transactions.HashSecret(secret), nil
+}
+
+// Initiate builds and signs a transaction that locks value in a pay-to-script-hash
+// HTLC: recipientPKH can claim it with the preimage of secretHash, and the
+// funding key can refund it once lockTime has passed. Any remainder of the
+// funding output is returned to the funding key. The swap participant calls
+// Initiate with the initiator's secret hash and a shorter lock time.
+func Initiate(funding Funding, recipientPKH, secretHash []byte, lockTime int64, value int) (*Contract, error) {
+	if value <= 0 || value > funding.Output.Value {
+		return nil, fmt.Errorf("invalid contract value %d", value)
+	}
+
+	refundPKH := transactions.HashPubKey(funding.Key.Public().Bytes())
+	redeemScript, err := transactions.NewHTLCScript(&transactions.HTLC{
+		SecretHash:   secretHash,
+		RecipientPKH: recipientPKH,
+		RefundPKH:    refundPKH,
+		LockTime:     lockTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := []transactions.TransactionOutput{
+		{Value: value, ScriptPubKey: transactions.PayToScriptHashScript(transactions.HashScript(redeemScript))},
+	}
+	if change := funding.Output.Value - value; change > 0 {
+		outputs = append(outputs, transactions.TransactionOutput{Value: change, ScriptPubKey: transactions.PayToPubKeyHashScript(refundPKH)})
+	}
+
+	tx := transactions.NewTransaction(
+		[]transactions.TransactionInput{{Txid: funding.Txid, Vout: funding.Vout}},
+		outputs,
+	)
+	if err := tx.SignInput(0, funding.Key, funding.Output); err != nil {
+		return nil, err
+	}
+
+	return &Contract{RedeemScript: redeemScript, FundingTx: tx, Vout: 0}, nil
+}
+
+// Redeem claims the contract for the recipient by revealing secret.
+func Redeem(contract *Contract, recipient crypto.Signer, secret []byte) (*transactions.Transaction, error) {
+	pubKeyHash := transactions.HashPubKey(recipient.Public().Bytes())
+	tx := transactions.NewTransaction(
+		[]transactions.TransactionInput{{
+			Txid:      contract.FundingTx.ID,
+			Vout:      contract.Vout,
+			ScriptSig: contract.RedeemScript,
+			Witness:   [][]byte{secret},
+		}},
+		[]transactions.TransactionOutput{{Value: contract.Output().Value, ScriptPubKey: transactions.PayToPubKeyHashScript(pubKeyHash)}},
+	)
+	if err := tx.SignInput(0, recipient, contract.Output()); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// Refund returns the contract output to the sender. The transaction only
+// becomes final once the contract's lock time has passed.
+func Refund(contract *Contract, sender crypto.Signer) (*transactions.Transaction, error) {
+	htlc, ok := transactions.ParseHTLCScript(contract.RedeemScript)
+	if !ok {
+		return nil, errors.New("redeem script is not an htlc")
+	}
+
+	pubKeyHash := transactions.HashPubKey(sender.Public().Bytes())
+	tx := &transactions.Transaction{
+		Vin: []transactions.TransactionInput{{
+			Txid:      contract.FundingTx.ID,
+			Vout:      contract.Vout,
+			ScriptSig: contract.RedeemScript,
+		}},
+		Vout:     []transactions.TransactionOutput{{Value: contract.Output().Value, ScriptPubKey: transactions.PayToPubKeyHashScript(pubKeyHash)}},
+		LockTime: htlc.LockTime,
+	}
+	tx.SetID()
+	if err := tx.SignInput(0, sender, contract.Output()); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// Audit checks that output vout of fundingTx pays to redeemScript and returns
+// the terms of the contract, so a counterparty can verify them before
+// locking up their own funds.
+func Audit(redeemScript string, fundingTx *transactions.Transaction, vout int) (*AuditReport, error) {
+	if vout < 0 || vout >= len(fundingTx.Vout) {
+		return nil, fmt.Errorf("output %d not found", vout)
+	}
+
+	htlc, ok := transactions.ParseHTLCScript(redeemScript)
+	if !ok {
+		return nil, errors.New("redeem script is not an htlc")
+	}
+
+	out := fundingTx.Vout[vout]
+	hash, ok := transactions.ExtractScriptHash(out.ScriptPubKey)
+	if !ok || !bytes.Equal(hash, transactions.HashScript(redeemScript)) {
+		return nil, errors.New("output does not pay to the contract")
+	}
+
+	return &AuditReport{HTLC: htlc, Value: out.Value}, nil
+}
+
+// ExtractSecret finds the preimage of secretHash revealed by a redeem transaction.
+func ExtractSecret(redeemTx *transactions.Transaction, secretHash []byte) ([]byte, error) {
+	for _, in := range redeemTx.Vin {
+		for _, item := range in.Witness {
+			if bytes.Equal(transactions.HashSecret(item), secretHash) {
+				return item, nil
+			}
+		}
+	}
+	return nil, errors.New("transaction does not reveal the secret")
+}
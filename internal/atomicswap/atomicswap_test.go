@@ -0,0 +1,165 @@
+package atomicswap
+
+import (
+	"testing"
+
+	"github.com/NicholasRodrigues/go-chain/internal/blockchain"
+	"github.com/NicholasRodrigues/go-chain/internal/mempool"
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+	"github.com/NicholasRodrigues/go-chain/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// network is an in-process chain with its mempool.
+type network struct {
+	chain *blockchain.Blockchain
+	pool  *mempool.Mempool
+}
+
+// newNetwork returns a network whose coinbase outputs can be spent right
+// away, so funding a party takes a single block.
+func newNetwork() *network {
+	params := blockchain.MainNetParams
+	params.CoinbaseMaturity = 0
+	chain := blockchain.NewBlockchainWithParams(&params)
+	return &network{chain: chain, pool: mempool.New(chain)}
+}
+
+// mine confirms every pool transaction in a new block.
+func (n *network) mine() {
+	n.chain.AddBlock(n.pool.Transactions())
+	n.pool.RemoveBlock(n.chain.Blocks[len(n.chain.Blocks)-1])
+}
+
+// fund mines a coinbase paying 50 to key and returns it as a Funding.
+func (n *network) fund(key *crypto.PrivateKey, tag string) Funding {
+	coinbase := transactions.NewTransaction(
+		[]transactions.TransactionInput{{Txid: []byte{}, Vout: -1, ScriptSig: tag}},
+		[]transactions.TransactionOutput{{Value: 50, ScriptPubKey: transactions.PayToPubKeyHashScript(pubKeyHash(key))}},
+	)
+	n.chain.AddBlock([]*transactions.Transaction{coinbase})
+	return Funding{Txid: coinbase.ID, Vout: 0, Output: coinbase.Vout[0], Key: key}
+}
+
+func newKey(t *testing.T) *crypto.PrivateKey {
+	key, err := crypto.NewPrivateKey()
+	require.NoError(t, err)
+	return key
+}
+
+func pubKeyHash(key *crypto.PrivateKey) []byte {
+	return transactions.HashPubKey(key.PublicKey().Bytes())
+}
+
+func TestAtomicSwapAcrossTwoChains(t *testing.T) {
+	chainA, chainB := newNetwork(), newNetwork()
+	alice, bob := newKey(t), newKey(t)
+	aliceFunds := chainA.fund(alice, "alice")
+	bobFunds := chainB.fund(bob, "bob")
+
+	// Alice locks 30 on chain A for Bob.
+	secret, secretHash, err := NewSecret()
+	require.NoError(t, err)
+	aliceContract, err := Initiate(aliceFunds, pubKeyHash(bob), secretHash, 20, 30)
+	require.NoError(t, err)
+	require.NoError(t, chainA.pool.Add(aliceContract.FundingTx))
+	chainA.mine()
+
+	// Bob audits Alice's contract before locking 40 on chain B for Alice.
+	report, err := Audit(aliceContract.RedeemScript, aliceContract.FundingTx, aliceContract.Vout)
+	require.NoError(t, err)
+	assert.Equal(t, 30, report.Value)
+	assert.Equal(t, pubKeyHash(bob), report.HTLC.RecipientPKH)
+	assert.Equal(t, int64(20), report.HTLC.LockTime)
+
+	bobContract, err := Initiate(bobFunds, pubKeyHash(alice), report.HTLC.SecretHash, 10, 40)
+	require.NoError(t, err)
+	require.NoError(t, chainB.pool.Add(bobContract.FundingTx))
+	chainB.mine()
+
+	// Alice claims on chain B, revealing the secret.
+	_, err = Audit(bobContract.RedeemScript, bobContract.FundingTx, bobContract.Vout)
+	require.NoError(t, err)
+	aliceRedeem, err := Redeem(bobContract, alice, secret)
+	require.NoError(t, err)
+	require.NoError(t, chainB.pool.Add(aliceRedeem))
+	chainB.mine()
+
+	// Bob learns the secret from chain B and claims on chain A.
+	learned, err := ExtractSecret(aliceRedeem, secretHash)
+	require.NoError(t, err)
+	bobRedeem, err := Redeem(aliceContract, bob, learned)
+	require.NoError(t, err)
+	require.NoError(t, chainA.pool.Add(bobRedeem))
+	chainA.mine()
+
+	assert.Len(t, chainA.chain.FindUTXO(pubKeyHash(bob)), 1)
+	assert.Len(t, chainB.chain.FindUTXO(pubKeyHash(alice)), 1)
+	assert.True(t, blockchain.ChainValidationPredicate(chainA.chain))
+	assert.True(t, blockchain.ChainValidationPredicate(chainB.chain))
+}
+
+func TestRefundAfterLockTime(t *testing.T) {
+	net := newNetwork()
+	alice, bob := newKey(t), newKey(t)
+	_, secretHash, err := NewSecret()
+	require.NoError(t, err)
+
+	contract, err := Initiate(net.fund(alice, "alice"), pubKeyHash(bob), secretHash, 4, 50)
+	require.NoError(t, err)
+	require.NoError(t, net.pool.Add(contract.FundingTx))
+	net.mine()
+
+	refund, err := Refund(contract, alice)
+	require.NoError(t, err)
+	assert.ErrorIs(t, net.pool.Add(refund), mempool.ErrNonFinal)
+
+	net.mine()
+	net.mine()
+	assert.NoError(t, net.pool.Add(refund))
+}
+
+func TestRedeemRejectsWrongSecretOrKey(t *testing.T) {
+	net := newNetwork()
+	alice, bob := newKey(t), newKey(t)
+	secret, secretHash, err := NewSecret()
+	require.NoError(t, err)
+
+	contract, err := Initiate(net.fund(alice, "alice"), pubKeyHash(bob), secretHash, 100, 25)
+	require.NoError(t, err)
+	require.NoError(t, net.pool.Add(contract.FundingTx))
+	net.mine()
+
+	wrongSecret, _, err := NewSecret()
+	require.NoError(t, err)
+	badRedeem, err := Redeem(contract, bob, wrongSecret)
+	require.NoError(t, err)
+	assert.Error(t, net.pool.Add(badRedeem))
+
+	stolen, err := Redeem(contract, alice, secret)
+	require.NoError(t, err)
+	assert.Error(t, net.pool.Add(stolen))
+
+	// A refund that claims an earlier lock time than the contract's is invalid.
+	refund, err := Refund(contract, alice)
+	require.NoError(t, err)
+	refund.LockTime = 1
+	assert.Error(t, net.pool.Add(refund))
+}
+
+func TestAuditRejectsMismatchedScript(t *testing.T) {
+	alice, bob := newKey(t), newKey(t)
+	_, secretHash, err := NewSecret()
+	require.NoError(t, err)
+
+	funding := Funding{Txid: []byte("prev"), Vout: 0, Output: transactions.TransactionOutput{Value: 10}, Key: alice}
+	contract, err := Initiate(funding, pubKeyHash(bob), secretHash, 10, 10)
+	require.NoError(t, err)
+
+	other, err := Initiate(funding, pubKeyHash(alice), secretHash, 10, 10)
+	require.NoError(t, err)
+
+	_, err = Audit(other.RedeemScript, contract.FundingTx, contract.Vout)
+	assert.Error(t, err)
+}
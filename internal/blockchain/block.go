@@ -3,9 +3,9 @@ package blockchain
 import (
 	"bytes"
 	"crypto/sha256"
+	"encoding/gob"
+	"fmt"
 	"github.com/NicholasRodrigues/go-chain/internal/transactions"
-	"strconv"
-	"time"
 )
 
 type Block struct {
@@ -14,14 +14,20 @@ type Block struct {
 	PrevBlockHash []byte
 	Hash          []byte
 	Counter       int // Nonce
+	Bits          int // Leading zero bits required of the hash, 0 for Difficulty
 }
 
-// SetHash recalculates the hash of the block.
+// Difficulty returns the number of leading zero bits required of the block hash.
+func (b *Block) Difficulty() int {
+	if b.Bits == 0 {
+		return Difficulty
+	}
+	return b.Bits
+}
+
+// SetHash recalculates the hash of the block from its contents and nonce.
 func (b *Block) SetHash() {
-	timestamp := []byte(strconv.FormatInt(b.Timestamp, 10))
-	headers := bytes.Join([][]byte{b.PrevBlockHash, b.HashTransactions(), timestamp}, []byte{})
-	hash := sha256.Sum256(headers)
-	b.Hash = hash[:]
+	b.Hash = NewProofOfWork(b).Hash()
 }
 
 // HashTransactions returns a hash of the transactions in the block.
@@ -37,14 +43,26 @@ func (b *Block) HashTransactions() []byte {
 	return txHash[:]
 }
 
-// NewBlock creates and returns a new block.
+// NewBlock creates and returns a new block timestamped with the system time.
 func NewBlock(transactions []*transactions.Transaction, prevBlockHash []byte) *Block {
+	return NewBlockWithClock(transactions, prevBlockHash, SystemClock{})
+}
+
+// NewBlockWithClock creates and returns a new block timestamped by clock.
+func NewBlockWithClock(transactions []*transactions.Transaction, prevBlockHash []byte, clock Clock) *Block {
+	return mineBlock(transactions, prevBlockHash, clock.Now().Unix(), Difficulty)
+}
+
+// mineBlock creates a block with the given timestamp and difficulty and
+// searches for its proof of work.
+func mineBlock(transactions []*transactions.Transaction, prevBlockHash []byte, timestamp int64, bits int) *Block {
 	block := &Block{
-		Timestamp:     time.Now().Unix(),
+		Timestamp:     timestamp,
 		Transactions:  transactions,
 		PrevBlockHash: prevBlockHash,
 		Hash:          []byte{},
 		Counter:       0,
+		Bits:          bits,
 	}
 
 	pow := NewProofOfWork(block)
@@ -54,3 +72,32 @@ func NewBlock(transactions []*transactions.Transaction, prevBlockHash []byte) *B
 
 	return block
 }
+
+// Serialize serializes the block into a byte slice.
+func (b *Block) Serialize() []byte {
+	var encoded bytes.Buffer
+	enc := gob.NewEncoder(&encoded)
+	err := enc.Encode(b)
+	if err != nil {
+		panic(err)
+	}
+	return encoded.Bytes()
+}
+
+// DecodeBlock deserializes a block from a byte slice. Input larger than
+// MaxBlockSize is rejected before decoding, and the decoded block must
+// respect the other consensus limits.
+func DecodeBlock(data []byte) (*Block, error) {
+	if len(data) > MaxBlockSize {
+		return nil, fmt.Errorf("%w: %d bytes, at most %d allowed", ErrBlockLimitExceeded, len(data), MaxBlockSize)
+	}
+	var block Block
+	decoder := gob.NewDecoder(bytes.NewReader(data))
+	if err := decoder.Decode(&block); err != nil {
+		return nil, fmt.Errorf("failed to decode block: %w", err)
+	}
+	if err := block.CheckLimits(); err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
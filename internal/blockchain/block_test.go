@@ -17,7 +17,10 @@ func createTransaction() *transactions.Transaction {
 			{Value: 10, ScriptPubKey: "pubkey1"},
 		},
 	)
-	tx.Sign(privKey)
+	prevOuts := map[string]transactions.TransactionOutput{
+		transactions.UTXOKey([]byte("somepreviousid"), 0): {Value: 10, ScriptPubKey: "pubkey1"},
+	}
+	_ = tx.Sign(privKey, prevOuts)
 	return tx
 }
 
@@ -3,40 +3,192 @@ package blockchain
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/NicholasRodrigues/go-chain/internal/transactions"
 )
 
+// ErrInvalidBlock is returned for blocks that do not extend the tip of the
+// chain or do not meet its proof of work.
+var ErrInvalidBlock = errors.New("invalid block")
+
 type Blockchain struct {
 	Blocks []*Block
+
+	params *ChainParams
+
+	// clock timestamps mined blocks; nil means the system clock.
+	clock    Clock
+	timeData timeData
+
+	// sigCache holds signatures verified by ValidateTransaction so blocks
+	// including those transactions do not verify them again.
+	sigCache *transactions.SigCache
+	events   *EventBus
+
+	// utxos caches the UTXO set at the tip so transactions are validated
+	// without replaying the chain.
+	utxos utxoView
 }
 
 // AddBlock adds a new block to the blockchain with the given transactions.
 func (bc *Blockchain) AddBlock(transactions []*transactions.Transaction) {
-	prevBlock := bc.Blocks[len(bc.Blocks)-1]
-	newBlock := NewBlock(transactions, prevBlock.Hash)
+	newBlock := bc.mineNext(transactions)
 	bc.Blocks = append(bc.Blocks, newBlock)
+	bc.events.Publish(Event{Type: BlockConnected, Block: newBlock, Height: len(bc.Blocks) - 1})
+}
+
+// DisconnectTip removes the last block from the chain and returns it. The
+// genesis block and checkpointed blocks cannot be disconnected.
+func (bc *Blockchain) DisconnectTip() (*Block, bool) {
+	if len(bc.Blocks) <= 1 {
+		return nil, false
+	}
+	height := len(bc.Blocks) - 1
+	if checkpoint, ok := bc.Params().LastCheckpoint(height); ok && checkpoint.Height == height {
+		return nil, false
+	}
+	block := bc.Blocks[height]
+	bc.Blocks = bc.Blocks[:height]
+	bc.events.Publish(Event{Type: BlockDisconnected, Block: block, Height: height})
+	return block, true
+}
+
+// Events returns the bus announcing changes to the chain and to mempools
+// built on it. It is nil for chains not created by NewBlockchain.
+func (bc *Blockchain) Events() *EventBus {
+	return bc.events
+}
+
+// mineNext mines a block extending the tip with the difficulty required by
+// the chain's parameters. The block is timestamped by the chain's clock, but
+// never at or before the median time past.
+func (bc *Blockchain) mineNext(transactions []*transactions.Transaction) *Block {
+	prevBlock := bc.Blocks[len(bc.Blocks)-1]
+	timestamp := bc.now()
+	if medianTime := bc.MedianTimePast(bc.Height()); timestamp <= medianTime {
+		timestamp = medianTime + 1
+	}
+	return mineBlock(transactions, prevBlock.Hash, timestamp, bc.Params().nextDifficulty(bc.Blocks))
+}
+
+// MineBlock mines a block whose coinbase pays the subsidy at its height to
+// script, followed by as many of txs as fit within the block limits. The
+// block is added and returned only if it passes the checks of IsValid.
+func (bc *Blockchain) MineBlock(script string, txs []*transactions.Transaction) (*Block, error) {
+	height := len(bc.Blocks)
+	coinbase := transactions.NewCoinbaseTransaction(script, fmt.Sprintf("height %d", height), bc.Params().Subsidy(height))
+	block := bc.mineNext(append([]*transactions.Transaction{coinbase}, fitBlock(coinbase, txs)...))
+	if err := bc.checkNextBlock(block); err != nil {
+		return nil, fmt.Errorf("mined block at height %d is invalid: %w", height, err)
+	}
+	bc.Blocks = append(bc.Blocks, block)
+	bc.events.Publish(Event{Type: BlockConnected, Block: block, Height: height})
+	return block, nil
+}
+
+// checkNextBlock verifies that block may extend the tip of the chain,
+// applying the checks of IsValid to the block alone.
+func (bc *Blockchain) checkNextBlock(block *Block) error {
+	height := len(bc.Blocks)
+	if !bytes.Equal(block.PrevBlockHash, bc.Tip().Hash) {
+		return fmt.Errorf("%w: block does not extend the tip", ErrInvalidBlock)
+	}
+	if !NewProofOfWork(block).Validate() {
+		return fmt.Errorf("%w: proof of work does not match the block", ErrInvalidBlock)
+	}
+	if block.Difficulty() != bc.Params().nextDifficulty(bc.Blocks) {
+		return fmt.Errorf("%w: difficulty %d, %d is required", ErrInvalidBlock, block.Difficulty(), bc.Params().nextDifficulty(bc.Blocks))
+	}
+	if err := bc.CheckBlockTime(block, height); err != nil {
+		return err
+	}
+	if err := block.CheckLimits(); err != nil {
+		return err
+	}
+	if err := bc.Params().CheckCheckpoint(height, block); err != nil {
+		return err
+	}
+	utxos := bc.UTXOSet()
+	if err := bc.verifyBlockScripts(block, height, utxos); err != nil {
+		return err
+	}
+	return bc.connectTransactions(block, height, utxos)
+}
+
+// SetClock makes the chain timestamp mined blocks with c. A nil clock
+// restores the system clock.
+func (bc *Blockchain) SetClock(c Clock) {
+	bc.clock = c
+}
+
+// Clock returns the clock timestamping mined blocks.
+func (bc *Blockchain) Clock() Clock {
+	if bc.clock == nil {
+		return SystemClock{}
+	}
+	return bc.clock
 }
 
-// NewGenesisBlock creates and returns the genesis block.
+// SetMockTime stops the chain's clock at t, in Unix seconds, so tests can
+// control block timestamps. Zero restores the system clock.
+func (bc *Blockchain) SetMockTime(t int64) {
+	if t == 0 {
+		bc.SetClock(nil)
+		return
+	}
+	bc.SetClock(NewMockClock(time.Unix(t, 0)))
+}
+
+// now returns the current Unix time according to the chain's clock.
+func (bc *Blockchain) now() int64 {
+	return bc.Clock().Now().Unix()
+}
+
+// Params returns the parameters of the network the chain belongs to.
+func (bc *Blockchain) Params() *ChainParams {
+	if bc.params == nil {
+		return &MainNetParams
+	}
+	return bc.params
+}
+
+// NewGenesisBlock creates and returns the genesis block of the main network.
 func NewGenesisBlock() *Block {
-	coinbase := transactions.NewTransaction(
-		[]transactions.TransactionInput{
-			{Txid: []byte{}, Vout: -1, ScriptSig: "Genesis"},
-		},
-		[]transactions.TransactionOutput{
-			{Value: 50, ScriptPubKey: "coinbase"},
-		},
-	)
-	return NewBlock([]*transactions.Transaction{coinbase}, []byte{})
-}
-
-// NewBlockchain creates and returns a new blockchain with the genesis block.
+	return MainNetParams.GenesisBlock()
+}
+
+// NewBlockchain creates and returns a new main network blockchain with the
+// genesis block.
 func NewBlockchain() *Blockchain {
-	return &Blockchain{[]*Block{NewGenesisBlock()}}
+	return NewBlockchainWithParams(&MainNetParams)
+}
+
+// NewBlockchainWithParams creates and returns a new blockchain for the
+// network described by params.
+func NewBlockchainWithParams(params *ChainParams) *Blockchain {
+	return newChain([]*Block{params.GenesisBlock()}, params)
+}
+
+// checkDifficulty reports whether every block was mined at the difficulty
+// required by the chain's parameters.
+func (bc *Blockchain) checkDifficulty() bool {
+	params := bc.Params()
+	for i, block := range bc.Blocks {
+		if block.Difficulty() != params.nextDifficulty(bc.Blocks[:i]) {
+			return false
+		}
+	}
+	return true
 }
 
 // IsValid validates the blockchain.
 func (bc *Blockchain) IsValid() bool {
+	if len(bc.Blocks) == 0 || bc.Params().CheckGenesis(bc.Blocks[0]) != nil {
+		return false
+	}
 	for i := 1; i < len(bc.Blocks); i++ {
 		currentBlock := bc.Blocks[i]
 		prevBlock := bc.Blocks[i-1]
@@ -51,7 +203,7 @@ func (bc *Blockchain) IsValid() bool {
 		}
 	}
 
-	return true
+	return bc.checkCheckpoints() && bc.checkDifficulty() && bc.checkTimestamps() && bc.checkBlockLimits()
 }
 
 // FindUnspentTransactions returns a list of transactions containing unspent outputs for an address
@@ -0,0 +1,50 @@
+package blockchain
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// ErrCheckpointMismatch is returned for blocks that differ from the
+// checkpoint at their height, that is blocks of a fork below a checkpoint.
+var ErrCheckpointMismatch = errors.New("block does not match the checkpoint")
+
+// CheckCheckpoint verifies block against the checkpoint at height, if any.
+// The hash is recomputed from the block's contents rather than taken from its
+// Hash field.
+func (p *ChainParams) CheckCheckpoint(height int, block *Block) error {
+	for _, checkpoint := range p.Checkpoints {
+		if checkpoint.Height != height {
+			continue
+		}
+		if hash := NewProofOfWork(block).Hash(); checkpoint.Hash != hex.EncodeToString(hash) {
+			return fmt.Errorf("%w at height %d: got %x, want %s", ErrCheckpointMismatch, height, hash, checkpoint.Hash)
+		}
+	}
+	return nil
+}
+
+// LastCheckpoint returns the highest checkpoint at or below height.
+func (p *ChainParams) LastCheckpoint(height int) (Checkpoint, bool) {
+	var last Checkpoint
+	found := false
+	for _, checkpoint := range p.Checkpoints {
+		if checkpoint.Height <= height && (!found || checkpoint.Height > last.Height) {
+			last, found = checkpoint, true
+		}
+	}
+	return last, found
+}
+
+// checkCheckpoints reports whether every block of the chain matches the
+// checkpoint at its height.
+func (bc *Blockchain) checkCheckpoints() bool {
+	params := bc.Params()
+	for height, block := range bc.Blocks {
+		if params.CheckCheckpoint(height, block) != nil {
+			return false
+		}
+	}
+	return true
+}
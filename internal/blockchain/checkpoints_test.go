@@ -0,0 +1,89 @@
+package blockchain
+
+import (
+	"encoding/hex"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// regTestChain returns a regtest chain with its own copy of the parameters
+// and count mined blocks, timestamped from start.
+func regTestChain(count int, start int64) (*Blockchain, *ChainParams) {
+	params := RegTestParams
+	bc := NewBlockchainWithParams(&params)
+	bc.SetMockTime(start)
+	for i := 0; i < count; i++ {
+		bc.MineBlock("pubkey1", nil)
+	}
+	return bc, &params
+}
+
+func TestNetworkCheckpoints(t *testing.T) {
+	for _, params := range Networks {
+		genesis := params.GenesisBlock()
+		if err := params.CheckCheckpoint(0, genesis); err != nil {
+			t.Errorf("%s: %v", params.Name, err)
+		}
+		for _, checkpoint := range params.Checkpoints {
+			if _, err := hex.DecodeString(checkpoint.Hash); err != nil || checkpoint.Height < 0 {
+				t.Errorf("%s: invalid checkpoint %+v", params.Name, checkpoint)
+			}
+		}
+	}
+	if err := MainNetParams.CheckCheckpoint(0, TestNetParams.GenesisBlock()); !errors.Is(err, ErrCheckpointMismatch) {
+		t.Errorf("expected a checkpoint mismatch for another genesis block, got %v", err)
+	}
+}
+
+func TestLastCheckpoint(t *testing.T) {
+	params := ChainParams{Checkpoints: []Checkpoint{{Height: 10, Hash: "aa"}, {Height: 0, Hash: "bb"}, {Height: 5, Hash: "cc"}}}
+	for _, test := range []struct{ height, want int }{{0, 0}, {4, 0}, {5, 5}, {9, 5}, {100, 10}} {
+		if got, ok := params.LastCheckpoint(test.height); !ok || got.Height != test.want {
+			t.Errorf("LastCheckpoint(%d) = %+v, %v, want height %d", test.height, got, ok, test.want)
+		}
+	}
+	if _, ok := params.LastCheckpoint(-1); ok {
+		t.Error("expected no checkpoint below the genesis block")
+	}
+}
+
+func TestCheckpoints_RejectForks(t *testing.T) {
+	bc, params := regTestChain(3, 2000000000)
+	params.Checkpoints = []Checkpoint{{Height: 2, Hash: hex.EncodeToString(bc.Blocks[2].Hash)}}
+	if !bc.IsValid() || !ChainValidationPredicate(bc) {
+		t.Fatal("expected the checkpointed chain to be valid")
+	}
+
+	// A chain mined at other times forks from the genesis block.
+	fork, _ := regTestChain(3, 2100000000)
+	fork.params = params
+	if fork.IsValid() || ChainValidationPredicate(fork) {
+		t.Error("expected a fork below the checkpoint to be invalid")
+	}
+	if err := params.CheckCheckpoint(2, fork.Blocks[2]); !errors.Is(err, ErrCheckpointMismatch) {
+		t.Errorf("expected a checkpoint mismatch, got %v", err)
+	}
+	// Claiming the checkpointed hash does not help: it is recomputed.
+	forged := *fork.Blocks[2]
+	forged.Hash = bc.Blocks[2].Hash
+	if err := params.CheckCheckpoint(2, &forged); !errors.Is(err, ErrCheckpointMismatch) {
+		t.Errorf("expected a checkpoint mismatch for a block claiming the checkpointed hash, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "chain.dat")
+	if err := fork.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadBlockchain(path, params); !errors.Is(err, ErrCheckpointMismatch) {
+		t.Errorf("expected loading the fork to fail with a checkpoint mismatch, got %v", err)
+	}
+
+	// Blocks above the checkpoint can be disconnected, the checkpoint cannot.
+	if _, ok := bc.DisconnectTip(); !ok {
+		t.Error("expected the block above the checkpoint to be disconnected")
+	}
+	if _, ok := bc.DisconnectTip(); ok {
+		t.Error("expected the checkpointed block to stay connected")
+	}
+}
@@ -0,0 +1,51 @@
+package blockchain
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock supplies the current time to block creation, so tests and
+// simulations can replay a run with the same timestamps.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock reads the system time.
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// MockClock is a Clock that only moves when told to. It is safe for
+// concurrent use.
+type MockClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewMockClock returns a clock stopped at now.
+func NewMockClock(now time.Time) *MockClock {
+	return &MockClock{now: now}
+}
+
+func (c *MockClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set moves the clock to now.
+func (c *MockClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// Advance moves the clock forward by d.
+func (c *MockClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
@@ -0,0 +1,133 @@
+package blockchain
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+)
+
+// EventType identifies what happened to the chain or the mempool.
+type EventType int
+
+const (
+	BlockConnected EventType = iota
+	BlockDisconnected
+	TxAccepted // Transaction added to the mempool
+	TxEvicted  // Transaction dropped from the mempool without being confirmed
+)
+
+func (t EventType) String() string {
+	switch t {
+	case BlockConnected:
+		return "block_connected"
+	case BlockDisconnected:
+		return "block_disconnected"
+	case TxAccepted:
+		return "tx_accepted"
+	case TxEvicted:
+		return "tx_evicted"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseEventType returns the event type named s.
+func ParseEventType(s string) (EventType, bool) {
+	for t := BlockConnected; t <= TxEvicted; t++ {
+		if t.String() == s {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// Event describes a change to the chain or the mempool. Block and Height are
+// set for block events, Tx for transaction events.
+type Event struct {
+	Type   EventType
+	Block  *Block
+	Height int
+	Tx     *transactions.Transaction
+}
+
+// DefaultEventBuffer is the number of events a subscription queues before
+// further events are dropped.
+const DefaultEventBuffer = 256
+
+// EventBus delivers events to subscribers. Publishing never blocks: events
+// for a subscriber whose queue is full are dropped and counted, so a slow
+// consumer cannot stall block processing.
+type EventBus struct {
+	mu   sync.RWMutex
+	subs map[*Subscription]struct{}
+}
+
+// NewEventBus creates a bus without subscribers.
+func NewEventBus() *EventBus {
+	return &EventBus{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscription receives the events accepted by its filter.
+type Subscription struct {
+	bus     *EventBus
+	ch      chan Event
+	filter  func(Event) bool
+	dropped atomic.Uint64
+	once    sync.Once
+}
+
+// Subscribe registers a subscriber queueing up to buffer events. A nil filter
+// accepts every event.
+func (b *EventBus) Subscribe(buffer int, filter func(Event) bool) *Subscription {
+	if buffer <= 0 {
+		buffer = DefaultEventBuffer
+	}
+	sub := &Subscription{bus: b, ch: make(chan Event, buffer), filter: filter}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs[sub] = struct{}{}
+	return sub
+}
+
+// Publish delivers e to every interested subscriber without blocking.
+// Publishing to a nil bus does nothing.
+func (b *EventBus) Publish(e Event) {
+	if b == nil {
+		return
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subs {
+		if sub.filter != nil && !sub.filter(e) {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// Events returns the channel of delivered events. It is closed by Close.
+func (s *Subscription) Events() <-chan Event {
+	return s.ch
+}
+
+// Dropped returns the number of events discarded because the queue was full.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close unsubscribes and closes the event channel.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.bus.mu.Lock()
+		delete(s.bus.subs, s)
+		s.bus.mu.Unlock()
+		close(s.ch)
+	})
+}
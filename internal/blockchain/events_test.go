@@ -0,0 +1,77 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+)
+
+func TestEventBus_ChainEvents(t *testing.T) {
+	bc := NewBlockchain()
+	sub := bc.Events().Subscribe(4, nil)
+	defer sub.Close()
+
+	bc.AddBlock([]*transactions.Transaction{createTransaction()})
+	e := <-sub.Events()
+	if e.Type != BlockConnected || e.Height != 1 || e.Block != bc.Blocks[1] {
+		t.Errorf("expected block 1 connected, got %v at height %d", e.Type, e.Height)
+	}
+
+	tip := bc.Blocks[1]
+	if block, ok := bc.DisconnectTip(); !ok || block != tip {
+		t.Fatal("expected the tip to be disconnected")
+	}
+	e = <-sub.Events()
+	if e.Type != BlockDisconnected || e.Height != 1 || e.Block != tip {
+		t.Errorf("expected block 1 disconnected, got %v at height %d", e.Type, e.Height)
+	}
+
+	if _, ok := bc.DisconnectTip(); ok {
+		t.Error("expected the genesis block to stay connected")
+	}
+}
+
+func TestEventBus_SlowSubscriberDoesNotBlock(t *testing.T) {
+	bus := NewEventBus()
+	slow := bus.Subscribe(1, nil)
+	txOnly := bus.Subscribe(10, func(e Event) bool { return e.Type == TxAccepted })
+
+	for i := 0; i < 3; i++ {
+		bus.Publish(Event{Type: BlockConnected, Height: i})
+	}
+	bus.Publish(Event{Type: TxAccepted})
+
+	if slow.Dropped() != 3 {
+		t.Errorf("expected 3 dropped events, got %d", slow.Dropped())
+	}
+	if e := <-slow.Events(); e.Type != BlockConnected || e.Height != 0 {
+		t.Errorf("expected the first event to be queued, got %v", e.Type)
+	}
+	if len(txOnly.Events()) != 1 || txOnly.Dropped() != 0 {
+		t.Errorf("expected only the transaction event to pass the filter")
+	}
+
+	slow.Close()
+	slow.Close()
+	if _, ok := <-slow.Events(); ok {
+		t.Error("expected closed subscription channel")
+	}
+	bus.Publish(Event{Type: TxAccepted})
+	if len(txOnly.Events()) != 2 {
+		t.Errorf("expected remaining subscriber to keep receiving events")
+	}
+
+	var nilBus *EventBus
+	nilBus.Publish(Event{})
+}
+
+func TestParseEventType(t *testing.T) {
+	for _, typ := range []EventType{BlockConnected, BlockDisconnected, TxAccepted, TxEvicted} {
+		if parsed, ok := ParseEventType(typ.String()); !ok || parsed != typ {
+			t.Errorf("expected %s to round trip", typ)
+		}
+	}
+	if _, ok := ParseEventType("nope"); ok {
+		t.Error("expected unknown event type to be rejected")
+	}
+}
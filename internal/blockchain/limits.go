@@ -0,0 +1,91 @@
+package blockchain
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+)
+
+// Consensus limits on the size of blocks.
+const (
+	MaxBlockSize         = 1000000 // Serialized bytes
+	MaxBlockTransactions = 10000
+	MaxBlockSigOps       = 20000
+)
+
+// blockOverhead bounds the serialized size of a block beyond that of its
+// transactions encoded separately.
+const blockOverhead = 1000
+
+// ErrBlockLimitExceeded is returned for blocks exceeding a consensus limit.
+var ErrBlockLimitExceeded = errors.New("block exceeds consensus limits")
+
+// CheckLimits returns an error wrapping ErrBlockLimitExceeded if the block
+// is too large, holds too many transactions or signature operations, or
+// holds a transaction exceeding the transaction limits.
+func (b *Block) CheckLimits() error {
+	if len(b.Transactions) > MaxBlockTransactions {
+		return fmt.Errorf("%w: %d transactions, at most %d allowed", ErrBlockLimitExceeded, len(b.Transactions), MaxBlockTransactions)
+	}
+	sigOps := 0
+	for _, tx := range b.Transactions {
+		if err := tx.CheckLimits(); err != nil {
+			return fmt.Errorf("%w: transaction %x: %v", ErrBlockLimitExceeded, tx.ID, err)
+		}
+		sigOps += tx.SigOpCount()
+	}
+	if sigOps > MaxBlockSigOps {
+		return fmt.Errorf("%w: %d signature operations, at most %d allowed", ErrBlockLimitExceeded, sigOps, MaxBlockSigOps)
+	}
+	if size := len(b.Serialize()); size > MaxBlockSize {
+		return fmt.Errorf("%w: %d bytes, at most %d allowed", ErrBlockLimitExceeded, size, MaxBlockSize)
+	}
+	return nil
+}
+
+// checkBlockLimits reports whether every block of the chain respects the
+// block limits.
+func (bc *Blockchain) checkBlockLimits() bool {
+	for _, block := range bc.Blocks {
+		if block.CheckLimits() != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// fitBlock returns the transactions of txs, in order, that fit in a block
+// alongside coinbase. Transactions that would exceed a limit are skipped, so
+// they stay in the mempool, along with later transactions spending them.
+func fitBlock(coinbase *transactions.Transaction, txs []*transactions.Transaction) []*transactions.Transaction {
+	size := blockOverhead + len(coinbase.Serialize())
+	sigOps := coinbase.SigOpCount()
+	var selected []*transactions.Transaction
+	skipped := make(map[string]bool)
+	for _, tx := range txs {
+		if len(selected)+1 >= MaxBlockTransactions {
+			break
+		}
+		txSize, txSigOps := len(tx.Serialize()), tx.SigOpCount()
+		if size+txSize > MaxBlockSize || sigOps+txSigOps > MaxBlockSigOps || spendsAny(tx, skipped) {
+			skipped[hex.EncodeToString(tx.ID)] = true
+			continue
+		}
+		size += txSize
+		sigOps += txSigOps
+		selected = append(selected, tx)
+	}
+	return selected
+}
+
+// spendsAny reports whether tx spends an output of a transaction in txids.
+func spendsAny(tx *transactions.Transaction, txids map[string]bool) bool {
+	for _, in := range tx.Vin {
+		if txids[hex.EncodeToString(in.Txid)] {
+			return true
+		}
+	}
+	return false
+}
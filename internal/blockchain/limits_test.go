@@ -0,0 +1,61 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+)
+
+// fanOut returns n distinct transactions with outputs outputs paying script.
+func fanOut(n, outputs int, script string) []*transactions.Transaction {
+	var txs []*transactions.Transaction
+	for i := 0; i < n; i++ {
+		vout := make([]transactions.TransactionOutput, outputs)
+		for j := range vout {
+			vout[j] = transactions.TransactionOutput{Value: 1, ScriptPubKey: script}
+		}
+		txs = append(txs, transactions.NewTransaction(
+			[]transactions.TransactionInput{{Txid: []byte(fmt.Sprintf("prev %d", i)), Vout: 0}},
+			vout,
+		))
+	}
+	return txs
+}
+
+func TestBlockLimits(t *testing.T) {
+	coinbase := transactions.NewCoinbaseTransaction("pubkey1", "limits", 50)
+	bigScript := strings.Repeat("x", transactions.MaxTransactionSize-1000)
+
+	for name, txs := range map[string][]*transactions.Transaction{
+		"transactions": fanOut(MaxBlockTransactions, 1, "pubkey1"),
+		"sigops":       fanOut(MaxBlockSigOps/transactions.MaxOutputs+1, transactions.MaxOutputs, "pubkey1"),
+		"size":         fanOut(MaxBlockSize/transactions.MaxTransactionSize+1, 1, bigScript),
+	} {
+		block := &Block{Transactions: append([]*transactions.Transaction{coinbase}, txs...)}
+		if err := block.CheckLimits(); !errors.Is(err, ErrBlockLimitExceeded) {
+			t.Errorf("too many %s: got %v, want ErrBlockLimitExceeded", name, err)
+		}
+		if _, err := DecodeBlock(block.Serialize()); !errors.Is(err, ErrBlockLimitExceeded) {
+			t.Errorf("decoding too many %s: got %v, want ErrBlockLimitExceeded", name, err)
+		}
+
+		// Mining leaves out the transactions that do not fit.
+		mined := &Block{Transactions: append([]*transactions.Transaction{coinbase}, fitBlock(coinbase, txs)...)}
+		if err := mined.CheckLimits(); err != nil {
+			t.Errorf("mining too many %s: %v", name, err)
+		}
+		if len(mined.Transactions) < 2 || len(mined.Transactions) > len(txs) {
+			t.Errorf("mining too many %s: block has %d of %d transactions", name, len(mined.Transactions)-1, len(txs))
+		}
+	}
+
+	bc := NewBlockchainWithParams(&RegTestParams)
+	txs := fanOut(MaxBlockSigOps/transactions.MaxOutputs+1, transactions.MaxOutputs, "pubkey1")
+	bc.Blocks = append(bc.Blocks, mineBlock(append([]*transactions.Transaction{coinbase}, txs...), bc.Tip().Hash, bc.now(), RegTestParams.InitialDifficulty))
+	if bc.IsValid() || ChainValidationPredicate(bc) {
+		t.Error("expected a block over the sigop limit to invalidate the chain")
+	}
+}
@@ -0,0 +1,122 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+)
+
+// ErrNonFinal is returned for transactions whose absolute or relative lock
+// times are not satisfied.
+var ErrNonFinal = errors.New("transaction is not final")
+
+// medianTimeBlocks is the number of blocks used to compute the median time past.
+const medianTimeBlocks = 11
+
+// Height returns the height of the chain tip.
+func (bc *Blockchain) Height() int {
+	return len(bc.Blocks) - 1
+}
+
+// MedianTimePast returns the median timestamp of the last eleven blocks up to
+// and including the block at height.
+func (bc *Blockchain) MedianTimePast(height int) int64 {
+	if height < 0 {
+		return 0
+	}
+
+	start := height - medianTimeBlocks + 1
+	if start < 0 {
+		start = 0
+	}
+
+	var timestamps []int64
+	for _, block := range bc.Blocks[start : height+1] {
+		timestamps = append(timestamps, block.Timestamp)
+	}
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+
+	return timestamps[len(timestamps)/2]
+}
+
+// checkLocks verifies the absolute and relative lock times of tx for a block
+// at height. utxos supplies the heights of the outputs being spent.
+func (bc *Blockchain) checkLocks(tx *transactions.Transaction, height int, utxos UTXOSet) error {
+	medianTime := bc.MedianTimePast(height - 1)
+	if !tx.IsFinal(height, medianTime) {
+		return fmt.Errorf("%w: transaction %x at height %d", ErrNonFinal, tx.ID, height)
+	}
+	if tx.IsCoinbase() {
+		return nil
+	}
+
+	prevHeights := make([]int, len(tx.Vin))
+	prevTimes := make([]int64, len(tx.Vin))
+	for i, in := range tx.Vin {
+		entry, ok := utxos[transactions.UTXOKey(in.Txid, in.Vout)]
+		if !ok {
+			// Without a known origin the input cannot satisfy a relative lock.
+			entry.Height = height
+		}
+		prevHeights[i] = entry.Height
+		prevTimes[i] = bc.MedianTimePast(entry.Height - 1)
+	}
+
+	if !tx.CalcSequenceLock(prevHeights, prevTimes).Satisfied(height, medianTime) {
+		return fmt.Errorf("%w: transaction %x has unsatisfied relative lock times", ErrNonFinal, tx.ID)
+	}
+	return nil
+}
+
+// CheckTransactionFinal verifies that tx may be included in the next block,
+// comparing its lock times with the chain tip and the median time past.
+func (bc *Blockchain) CheckTransactionFinal(tx *transactions.Transaction) error {
+	return bc.checkLocks(tx, bc.Height()+1, bc.spentEntries(tx, nil))
+}
+
+// ValidateTransaction checks tx against the UTXO set at the tip of the chain
+// and verifies that it is final for the next block. Lock time failures wrap
+// ErrNonFinal.
+func (bc *Blockchain) ValidateTransaction(tx *transactions.Transaction) error {
+	return bc.ValidateTransactionWithPool(tx, nil)
+}
+
+// ValidateTransactionWithPool is like ValidateTransaction, but tx may also
+// spend the outputs in pool, keyed by transactions.UTXOKey, of unconfirmed
+// transactions that the next block includes before it.
+func (bc *Blockchain) ValidateTransactionWithPool(tx *transactions.Transaction, pool map[string]transactions.TransactionOutput) error {
+	if err := tx.CheckID(); err != nil {
+		return err
+	}
+	utxos := bc.spentEntries(tx, pool)
+	height := bc.Height() + 1
+	if err := bc.checkLocks(tx, height, utxos); err != nil {
+		return err
+	}
+	if err := bc.checkMaturity(tx, height, utxos); err != nil {
+		return err
+	}
+	if err := tx.ValidateWithFlags(utxos.Outputs(), transactions.StandardFlags(height, bc.Params().SchemeActivations).WithCache(bc.sigCache)); err != nil {
+		return fmt.Errorf("transaction %x is invalid: %w", tx.ID, err)
+	}
+	return nil
+}
+
+// validateTransactions checks the IDs, lock times, coinbase maturity, values
+// and input scripts of every transaction in the chain against the height and
+// median time past at which it was included, rejecting spends of missing or
+// already spent outputs.
+func validateTransactions(chain *Blockchain) bool {
+	utxos := make(UTXOSet)
+	for height, block := range chain.Blocks {
+		if err := chain.verifyBlockScripts(block, height, utxos); err != nil {
+			return false
+		}
+		if err := chain.connectTransactions(block, height, utxos); err != nil {
+			return false
+		}
+	}
+	return true
+}
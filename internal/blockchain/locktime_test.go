@@ -0,0 +1,65 @@
+package blockchain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+)
+
+func TestMedianTimePast(t *testing.T) {
+	bc := &Blockchain{}
+	for _, ts := range []int64{10, 50, 20, 40, 30} {
+		bc.Blocks = append(bc.Blocks, &Block{Timestamp: ts})
+	}
+
+	if mtp := bc.MedianTimePast(4); mtp != 30 {
+		t.Errorf("expected median time past 30, got %d", mtp)
+	}
+	if mtp := bc.MedianTimePast(1); mtp != 50 {
+		t.Errorf("expected median time past 50, got %d", mtp)
+	}
+}
+
+func TestCheckTransactionFinal(t *testing.T) {
+	bc := NewBlockchain()
+	genesisTx := bc.Blocks[0].Transactions[0]
+
+	tx := transactions.NewTransaction(
+		[]transactions.TransactionInput{{Txid: genesisTx.ID, Vout: 0}},
+		[]transactions.TransactionOutput{{Value: 50, ScriptPubKey: "pubkey1"}},
+	)
+
+	tx.LockTime = 1
+	if err := bc.CheckTransactionFinal(tx); err == nil {
+		t.Error("expected transaction locked until height 1 to be non-final for the next block")
+	}
+
+	tx.LockTime = 0
+	tx.Vin[0].Sequence = 2
+	if err := bc.CheckTransactionFinal(tx); err == nil {
+		t.Error("expected relative lock of 2 blocks on the genesis output to hold")
+	}
+
+	tx.Vin[0].Sequence = 1
+	if err := bc.CheckTransactionFinal(tx); err != nil {
+		t.Errorf("expected relative lock of 1 block to be satisfied: %v", err)
+	}
+}
+
+func TestChainValidationPredicate_RejectsNonFinalTransaction(t *testing.T) {
+	bc := newMatureChain()
+	tx := spendGenesis(t, bc)
+	tx.LockTime = 10
+	tx.SetID()
+	bc.AddBlock([]*transactions.Transaction{tx})
+
+	utxos := make(UTXOSet)
+	utxos.connectBlock(bc.Blocks[0], 0)
+	if err := bc.connectTransactions(bc.Tip(), 1, utxos); !errors.Is(err, ErrNonFinal) {
+		t.Errorf("expected a non-final error, got %v", err)
+	}
+	if ChainValidationPredicate(bc) {
+		t.Error("expected chain with a non-final transaction to be invalid")
+	}
+}
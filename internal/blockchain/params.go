@@ -0,0 +1,247 @@
+package blockchain
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+	"github.com/NicholasRodrigues/go-chain/pkg/address"
+)
+
+// ErrGenesisMismatch is returned when a chain does not start with the
+// genesis block of its network.
+var ErrGenesisMismatch = errors.New("genesis block does not match the network")
+
+// Checkpoint pins the hash of the block at a height.
+type Checkpoint struct {
+	Height int
+	Hash   string // Hex encoded block hash
+}
+
+// ChainParams defines a network: its genesis block, proof-of-work and
+// subsidy rules, address prefixes and default ports.
+type ChainParams struct {
+	Name string
+
+	// The genesis block pays GenesisReward to GenesisScript in a coinbase
+	// carrying GenesisData. Its nonce and hash are fixed so that every node
+	// of the network starts from the same block.
+	GenesisTimestamp int64
+	GenesisData      string
+	GenesisScript    string
+	GenesisReward    int
+	GenesisNonce     int
+	GenesisHash      string // Hex encoded
+
+	// InitialDifficulty is the number of leading zero bits required of the
+	// genesis block hash. It is also the lowest difficulty retargeting can
+	// reach.
+	InitialDifficulty int
+	// Every RetargetInterval blocks the difficulty moves by one bit towards
+	// one block per TargetSpacing. Zero disables retargeting.
+	RetargetInterval int
+	TargetSpacing    time.Duration
+
+	// The block subsidy starts at InitialSubsidy and halves every
+	// SubsidyHalvingInterval blocks. Zero disables halving.
+	InitialSubsidy         int
+	SubsidyHalvingInterval int
+
+	// CoinbaseMaturity is the number of blocks that must be mined on top of
+	// a coinbase before its outputs can be spent.
+	CoinbaseMaturity int
+
+	// SchemeActivations are the heights from which the signatures of each
+	// scheme are enforced. Schemes missing from it are never enforced.
+	SchemeActivations transactions.SchemeActivations
+
+	// MaxTimeDrift is how far ahead of the network-adjusted time a block
+	// timestamp may be.
+	MaxTimeDrift time.Duration
+
+	AddressParams *address.Params
+	DefaultPort   int // Peer-to-peer port
+	RPCPort       int
+	RESTPort      int
+
+	// Checkpoints pin blocks of the network's history: chains that differ
+	// from them are rejected, so the network cannot be forked below the last
+	// checkpoint. New releases append checkpoints as the chain grows; until
+	// the networks have a history to pin, the presets only pin their genesis
+	// blocks.
+	Checkpoints []Checkpoint
+
+	// RegTest enables on-demand block generation and mock time over RPC.
+	RegTest bool
+}
+
+var (
+	// MainNetParams are the parameters of the main network.
+	MainNetParams = ChainParams{
+		Name:                   "mainnet",
+		GenesisTimestamp:       1717200000, // 2024-06-01 00:00:00 UTC
+		GenesisData:            "Genesis",
+		GenesisScript:          "coinbase",
+		GenesisReward:          50,
+		GenesisNonce:           46245,
+		GenesisHash:            "000017302be9e2f2e6725b702f9b36a162ca91d38d62e17e4a3f97779b081c18",
+		InitialDifficulty:      Difficulty,
+		RetargetInterval:       1440,
+		TargetSpacing:          time.Minute,
+		InitialSubsidy:         50,
+		SubsidyHalvingInterval: 210000,
+		CoinbaseMaturity:       100,
+		SchemeActivations:      transactions.DefaultSchemeActivations(),
+		MaxTimeDrift:           2 * time.Hour,
+		AddressParams:          &address.MainNetParams,
+		DefaultPort:            9331,
+		RPCPort:                9332,
+		RESTPort:               9333,
+		Checkpoints: []Checkpoint{
+			{Height: 0, Hash: "000017302be9e2f2e6725b702f9b36a162ca91d38d62e17e4a3f97779b081c18"},
+		},
+	}
+
+	// TestNetParams are the parameters of the public test network, which is
+	// easier to mine than the main network.
+	TestNetParams = ChainParams{
+		Name:                   "testnet",
+		GenesisTimestamp:       1717200000,
+		GenesisData:            "Genesis testnet",
+		GenesisScript:          "coinbase",
+		GenesisReward:          50,
+		GenesisNonce:           3368,
+		GenesisHash:            "000cc2f747556f9a6e0b582ec361ccc82c2407790083661dfb4b9f35f62c640b",
+		InitialDifficulty:      12,
+		RetargetInterval:       1440,
+		TargetSpacing:          time.Minute,
+		InitialSubsidy:         50,
+		SubsidyHalvingInterval: 210000,
+		CoinbaseMaturity:       100,
+		SchemeActivations:      transactions.DefaultSchemeActivations(),
+		MaxTimeDrift:           2 * time.Hour,
+		AddressParams:          &address.TestNetParams,
+		DefaultPort:            19331,
+		RPCPort:                19332,
+		RESTPort:               19333,
+		Checkpoints: []Checkpoint{
+			{Height: 0, Hash: "000cc2f747556f9a6e0b582ec361ccc82c2407790083661dfb4b9f35f62c640b"},
+		},
+	}
+
+	// RegTestParams are the parameters of private regression test networks.
+	// Blocks are nearly free to mine and the difficulty never changes.
+	RegTestParams = ChainParams{
+		Name:                   "regtest",
+		GenesisTimestamp:       1717200000,
+		GenesisData:            "Genesis regtest",
+		GenesisScript:          "coinbase",
+		GenesisReward:          50,
+		GenesisNonce:           1,
+		GenesisHash:            "004cb1481c199be0f198cc5a81ca2a1967c56dc3f393ae7d8c07c2a9abd19e79",
+		InitialDifficulty:      1,
+		InitialSubsidy:         50,
+		SubsidyHalvingInterval: 150,
+		CoinbaseMaturity:       100,
+		SchemeActivations:      transactions.DefaultSchemeActivations(),
+		MaxTimeDrift:           2 * time.Hour,
+		AddressParams:          &address.RegTestParams,
+		DefaultPort:            29331,
+		RPCPort:                29332,
+		RESTPort:               29333,
+		RegTest:                true,
+	}
+)
+
+// Networks lists the parameters of the known networks.
+var Networks = []*ChainParams{&MainNetParams, &TestNetParams, &RegTestParams}
+
+// NetworkByName returns the parameters of the named network.
+func NetworkByName(name string) (*ChainParams, error) {
+	var names []string
+	for _, params := range Networks {
+		if params.Name == name {
+			return params, nil
+		}
+		names = append(names, params.Name)
+	}
+	return nil, fmt.Errorf("unknown network %q, want one of %s", name, strings.Join(names, ", "))
+}
+
+// GenesisBlock returns the genesis block described by the parameters. Its
+// hash is computed from the fixed nonce rather than mined, so it differs from
+// GenesisHash only if the parameters are inconsistent.
+func (p *ChainParams) GenesisBlock() *Block {
+	coinbase := transactions.NewTransaction(
+		[]transactions.TransactionInput{
+			{Txid: []byte{}, Vout: -1, ScriptSig: p.GenesisData},
+		},
+		[]transactions.TransactionOutput{
+			{Value: p.GenesisReward, ScriptPubKey: p.GenesisScript},
+		},
+	)
+	block := &Block{
+		Timestamp:     p.GenesisTimestamp,
+		Transactions:  []*transactions.Transaction{coinbase},
+		PrevBlockHash: []byte{},
+		Counter:       p.GenesisNonce,
+		Bits:          p.InitialDifficulty,
+	}
+	block.Hash = NewProofOfWork(block).Hash()
+	return block
+}
+
+// CheckGenesis returns an error wrapping ErrGenesisMismatch if block is not
+// the genesis block of the network. The hash is recomputed from the block's
+// contents, so a block claiming the genesis hash is not enough.
+func (p *ChainParams) CheckGenesis(block *Block) error {
+	pow := NewProofOfWork(block)
+	if hash := pow.Hash(); hex.EncodeToString(hash) != p.GenesisHash {
+		return fmt.Errorf("%w: got %x, want %s for %s", ErrGenesisMismatch, hash, p.GenesisHash, p.Name)
+	}
+	if !pow.Validate() {
+		return fmt.Errorf("%w: invalid proof of work", ErrGenesisMismatch)
+	}
+	return nil
+}
+
+// Subsidy returns the value a coinbase may create at height.
+func (p *ChainParams) Subsidy(height int) int {
+	if p.SubsidyHalvingInterval <= 0 {
+		return p.InitialSubsidy
+	}
+	halvings := height / p.SubsidyHalvingInterval
+	if halvings >= 63 {
+		return 0
+	}
+	return p.InitialSubsidy >> uint(halvings)
+}
+
+// nextDifficulty returns the difficulty required of the block following
+// blocks. At each retarget boundary the difficulty rises by one bit if the
+// last interval was mined in less than half the target time and falls by one
+// bit if it took more than twice as long.
+func (p *ChainParams) nextDifficulty(blocks []*Block) int {
+	height := len(blocks)
+	if height == 0 {
+		return p.InitialDifficulty
+	}
+	difficulty := blocks[height-1].Difficulty()
+	if p.RetargetInterval <= 0 || height%p.RetargetInterval != 0 {
+		return difficulty
+	}
+
+	first := blocks[height-p.RetargetInterval]
+	actual := blocks[height-1].Timestamp - first.Timestamp
+	target := int64(p.RetargetInterval-1) * int64(p.TargetSpacing/time.Second)
+	switch {
+	case actual < target/2:
+		difficulty++
+	case actual > target*2 && difficulty > p.InitialDifficulty:
+		difficulty--
+	}
+	return difficulty
+}
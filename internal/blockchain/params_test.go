@@ -0,0 +1,149 @@
+package blockchain
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+)
+
+func TestNetworkByName(t *testing.T) {
+	for _, params := range Networks {
+		got, err := NetworkByName(params.Name)
+		if err != nil || got != params {
+			t.Errorf("NetworkByName(%q) = %v, %v", params.Name, got, err)
+		}
+	}
+	if _, err := NetworkByName("simnet"); err == nil {
+		t.Error("expected an error for an unknown network")
+	}
+}
+
+func TestGenesisBlocks(t *testing.T) {
+	for _, params := range Networks {
+		genesis := params.GenesisBlock()
+		if err := params.CheckGenesis(genesis); err != nil {
+			t.Errorf("%s: %v", params.Name, err)
+		}
+		if genesis.Timestamp != params.GenesisTimestamp || genesis.Difficulty() != params.InitialDifficulty {
+			t.Errorf("%s: genesis block has timestamp %d and difficulty %d", params.Name, genesis.Timestamp, genesis.Difficulty())
+		}
+		if !bytes.Equal(genesis.Hash, params.GenesisBlock().Hash) {
+			t.Errorf("%s: expected the genesis block to be the same every time", params.Name)
+		}
+	}
+
+	if err := MainNetParams.CheckGenesis(TestNetParams.GenesisBlock()); !errors.Is(err, ErrGenesisMismatch) {
+		t.Errorf("expected networks to have distinct genesis blocks, got %v", err)
+	}
+
+	// A genesis block with another nonce has a different hash.
+	params := RegTestParams
+	params.GenesisNonce++
+	if err := RegTestParams.CheckGenesis(params.GenesisBlock()); !errors.Is(err, ErrGenesisMismatch) {
+		t.Errorf("expected a mismatch for another nonce, got %v", err)
+	}
+
+	bc := NewBlockchainWithParams(&RegTestParams)
+	bc.Blocks[0] = params.GenesisBlock()
+	if bc.IsValid() || ChainValidationPredicate(bc) {
+		t.Error("expected a chain with a foreign genesis block to be invalid")
+	}
+}
+
+func TestGenesisBlocks_RejectTamperedTransaction(t *testing.T) {
+	// The coinbase pays someone else and the nonce is mined again, but the
+	// block keeps claiming the real genesis hash.
+	genesis := RegTestParams.GenesisBlock()
+	coinbase := genesis.Transactions[0]
+	coinbase.Vout[0] = transactions.TransactionOutput{Value: 1000000, ScriptPubKey: "attacker"}
+	coinbase.SetID()
+	pow := NewProofOfWork(genesis)
+	_, genesis.Counter = pow.Run()
+
+	if err := RegTestParams.CheckGenesis(genesis); !errors.Is(err, ErrGenesisMismatch) {
+		t.Errorf("expected a mismatch for a tampered genesis block, got %v", err)
+	}
+	bc := NewBlockchainWithParams(&RegTestParams)
+	bc.Blocks[0] = genesis
+	if bc.IsValid() || ChainValidationPredicate(bc) {
+		t.Error("expected a chain with a tampered genesis block to be invalid")
+	}
+}
+
+func TestSubsidyHalving(t *testing.T) {
+	params := RegTestParams
+	for _, test := range []struct{ height, subsidy int }{
+		{0, 50}, {149, 50}, {150, 25}, {300, 12}, {150 * 6, 0}, {150 * 100, 0},
+	} {
+		if got := params.Subsidy(test.height); got != test.subsidy {
+			t.Errorf("Subsidy(%d) = %d, want %d", test.height, got, test.subsidy)
+		}
+	}
+
+	params.SubsidyHalvingInterval = 0
+	if got := params.Subsidy(1 << 30); got != params.InitialSubsidy {
+		t.Errorf("Subsidy without halving = %d, want %d", got, params.InitialSubsidy)
+	}
+}
+
+func TestNextDifficulty(t *testing.T) {
+	params := ChainParams{InitialDifficulty: 4, RetargetInterval: 4, TargetSpacing: 10 * time.Second}
+	blocksEvery := func(spacing int64, bits int) []*Block {
+		blocks := make([]*Block, 4)
+		for i := range blocks {
+			blocks[i] = &Block{Timestamp: int64(i) * spacing, Bits: bits}
+		}
+		return blocks
+	}
+
+	for _, test := range []struct {
+		name   string
+		blocks []*Block
+		bits   int
+	}{
+		{"genesis", nil, 4},
+		{"between boundaries", blocksEvery(1, 5)[:3], 5},
+		{"on target", blocksEvery(10, 5), 5},
+		{"too fast", blocksEvery(1, 5), 6},
+		{"too slow", blocksEvery(100, 5), 4},
+		{"too slow at the minimum", blocksEvery(100, 4), 4},
+	} {
+		if got := params.nextDifficulty(test.blocks); got != test.bits {
+			t.Errorf("%s: nextDifficulty = %d, want %d", test.name, got, test.bits)
+		}
+	}
+}
+
+func TestRegTestChain(t *testing.T) {
+	bc := NewBlockchainWithParams(&RegTestParams)
+	bc.SetMockTime(2000000000)
+	block, err := bc.MineBlock("pubkey1", nil)
+	if err != nil {
+		t.Fatalf("failed to mine: %v", err)
+	}
+	if block != bc.Tip() || block.Timestamp != 2000000000 {
+		t.Errorf("expected the mined block at the tip with the mock time, got timestamp %d", block.Timestamp)
+	}
+	if coinbase := block.Transactions[0]; !coinbase.IsCoinbase() || coinbase.Vout[0].Value != RegTestParams.Subsidy(1) {
+		t.Error("expected the block to start with a coinbase paying the subsidy")
+	}
+
+	if bc.Params() != &RegTestParams {
+		t.Error("expected the chain to keep its parameters")
+	}
+	if got := bc.Blocks[1].Difficulty(); got != RegTestParams.InitialDifficulty {
+		t.Errorf("block mined at difficulty %d, want %d", got, RegTestParams.InitialDifficulty)
+	}
+	if !bc.IsValid() {
+		t.Error("expected the regtest chain to be valid")
+	}
+
+	// A block claiming an easier difficulty than the network requires is rejected.
+	bc.Blocks[1].Bits = 0
+	if bc.IsValid() {
+		t.Error("expected a block at the wrong difficulty to invalidate the chain")
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"strconv"
 )
 
+// Difficulty is the number of leading zero bits required of block hashes on
+// the main network before any retargeting.
 const Difficulty = 16
 
 var maxNonce = math.MaxInt64
@@ -20,8 +22,12 @@ type ProofOfWork struct {
 
 // NewProofOfWork creates and returns a ProofOfWork.
 func NewProofOfWork(b *Block) *ProofOfWork {
-	target := big.NewInt(1)
-	target.Lsh(target, uint(256-Difficulty))
+	// Blocks claiming an impossible difficulty get a zero target, which no
+	// hash satisfies.
+	target := big.NewInt(0)
+	if bits := b.Difficulty(); bits > 0 && bits < 256 {
+		target.Lsh(big.NewInt(1), uint(256-bits))
+	}
 
 	pow := &ProofOfWork{b, target}
 
@@ -35,7 +41,7 @@ func (pow *ProofOfWork) prepareData(nonce int) []byte {
 			pow.block.PrevBlockHash,
 			pow.block.HashTransactions(),
 			[]byte(strconv.FormatInt(pow.block.Timestamp, 10)),
-			[]byte(strconv.FormatInt(int64(Difficulty), 10)),
+			[]byte(strconv.FormatInt(int64(pow.block.Difficulty()), 10)),
 			[]byte(strconv.FormatInt(int64(nonce), 10)),
 		},
 		[]byte{},
@@ -65,13 +71,22 @@ func (pow *ProofOfWork) Run() ([]byte, int) {
 	return hash[:], nonce
 }
 
-// Validate checks if the block's proof-of-work is valid.
+// Hash returns the hash of the block's header with its nonce, which the
+// block's Hash field must hold.
+func (pow *ProofOfWork) Hash() []byte {
+	hash := sha256.Sum256(pow.prepareData(pow.block.Counter))
+	return hash[:]
+}
+
+// Validate checks if the block's proof-of-work is valid and its Hash field is
+// the hash of its contents.
 func (pow *ProofOfWork) Validate() bool {
 	var hashInt big.Int
-	data := pow.prepareData(pow.block.Counter)
-
-	hash := sha256.Sum256(data)
-	hashInt.SetBytes(hash[:])
+	hash := pow.Hash()
+	if !bytes.Equal(hash, pow.block.Hash) {
+		return false
+	}
+	hashInt.SetBytes(hash)
 
 	return hashInt.Cmp(pow.target) == -1
 }
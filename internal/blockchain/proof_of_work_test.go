@@ -30,3 +30,17 @@ func TestProofOfWorkValidate(t *testing.T) {
 		t.Errorf("Proof of work validation failed")
 	}
 }
+
+// TestProofOfWorkValidate_RejectsStaleHash checks that the hash field must be
+// the hash of the block's contents
+func TestProofOfWorkValidate_RejectsStaleHash(t *testing.T) {
+	block := NewBlock([]*transactions.Transaction{createTransaction()}, []byte{})
+	hash := block.Hash
+	block.Transactions = []*transactions.Transaction{createTransaction()}
+	_, block.Counter = NewProofOfWork(block).Run()
+	block.Hash = hash
+
+	if NewProofOfWork(block).Validate() {
+		t.Errorf("Proof of work validated a hash of other contents")
+	}
+}
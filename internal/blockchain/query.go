@@ -0,0 +1,43 @@
+package blockchain
+
+import (
+	"bytes"
+
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+)
+
+// Tip returns the last block of the chain.
+func (bc *Blockchain) Tip() *Block {
+	return bc.Blocks[len(bc.Blocks)-1]
+}
+
+// BlockAt returns the block at height.
+func (bc *Blockchain) BlockAt(height int) (*Block, bool) {
+	if height < 0 || height >= len(bc.Blocks) {
+		return nil, false
+	}
+	return bc.Blocks[height], true
+}
+
+// BlockByHash returns the block with the given hash and its height.
+func (bc *Blockchain) BlockByHash(hash []byte) (*Block, int, bool) {
+	for height, block := range bc.Blocks {
+		if bytes.Equal(block.Hash, hash) {
+			return block, height, true
+		}
+	}
+	return nil, 0, false
+}
+
+// FindTransaction returns the confirmed transaction with the given id along
+// with the height of the block that includes it.
+func (bc *Blockchain) FindTransaction(txid []byte) (*transactions.Transaction, int, bool) {
+	for height := len(bc.Blocks) - 1; height >= 0; height-- {
+		for _, tx := range bc.Blocks[height].Transactions {
+			if bytes.Equal(tx.ID, txid) {
+				return tx, height, true
+			}
+		}
+	}
+	return nil, 0, false
+}
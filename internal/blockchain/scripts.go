@@ -0,0 +1,40 @@
+package blockchain
+
+import (
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+)
+
+// verifyBlockScripts verifies the input scripts of every transaction in block
+// at height in parallel, reusing signatures already verified by the mempool.
+// utxos holds the outputs created before the block; outputs created earlier
+// in the same block may also be spent. Inputs spending unknown outputs are
+// skipped here and rejected by connectTransactions.
+func (bc *Blockchain) verifyBlockScripts(block *Block, height int, utxos UTXOSet) error {
+	created := make(map[string]transactions.TransactionOutput)
+	var checks []transactions.InputCheck
+	for _, tx := range block.Transactions {
+		if !tx.IsCoinbase() {
+			for i, in := range tx.Vin {
+				key := transactions.UTXOKey(in.Txid, in.Vout)
+				prevOut, ok := created[key]
+				if !ok {
+					entry, known := utxos[key]
+					if !known {
+						continue
+					}
+					prevOut = entry.Output
+				}
+				checks = append(checks, transactions.InputCheck{Tx: tx, Index: i, PrevOut: prevOut})
+			}
+		}
+		for i, out := range tx.Vout {
+			created[transactions.UTXOKey(tx.ID, i)] = out
+		}
+	}
+
+	verifier := &transactions.BatchVerifier{
+		Flags: transactions.ConsensusFlags(height, bc.Params().SchemeActivations),
+		Cache: bc.sigCache,
+	}
+	return verifier.Verify(checks)
+}
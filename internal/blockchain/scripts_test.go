@@ -0,0 +1,86 @@
+package blockchain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+	"github.com/NicholasRodrigues/go-chain/pkg/crypto"
+)
+
+// spendGenesis returns a transaction spending the genesis output of a chain
+// created by newMatureChain.
+func spendGenesis(t *testing.T, bc *Blockchain) *transactions.Transaction {
+	genesisTx := bc.Blocks[0].Transactions[0]
+	tx := transactions.NewTransaction(
+		[]transactions.TransactionInput{{Txid: genesisTx.ID, Vout: 0}},
+		[]transactions.TransactionOutput{{Value: 50, ScriptPubKey: "pubkey1"}},
+	)
+	if err := tx.SignInput(0, testKey, genesisTx.Vout[0]); err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	return tx
+}
+
+func TestValidateTransaction_CachesSignatures(t *testing.T) {
+	bc := newMatureChain()
+	tx := spendGenesis(t, bc)
+
+	if err := bc.ValidateTransaction(tx); err != nil {
+		t.Fatalf("expected transaction to be valid: %v", err)
+	}
+	if bc.sigCache.Len() != 1 {
+		t.Fatalf("expected 1 cached signature, got %d", bc.sigCache.Len())
+	}
+
+	bc.AddBlock([]*transactions.Transaction{tx})
+	if !ChainValidationPredicate(bc) {
+		t.Error("expected chain spending the genesis output to be valid")
+	}
+}
+
+func TestChainValidationPredicate_RejectsForgedID(t *testing.T) {
+	bc := newMatureChain()
+	tx := spendGenesis(t, bc)
+	tx.ID = bc.Blocks[0].Transactions[0].ID
+	if err := bc.ValidateTransaction(tx); !errors.Is(err, transactions.ErrIDMismatch) {
+		t.Errorf("expected an ID mismatch, got %v", err)
+	}
+	bc.AddBlock([]*transactions.Transaction{tx})
+	if ChainValidationPredicate(bc) {
+		t.Error("expected a chain with a forged transaction ID to be invalid")
+	}
+}
+
+func TestChainValidationPredicate_RejectsBadSignature(t *testing.T) {
+	bc := newMatureChain()
+	tx := spendGenesis(t, bc)
+	tx.Vin[0].Signature[0] ^= 0xff
+	bc.AddBlock([]*transactions.Transaction{tx})
+
+	if ChainValidationPredicate(bc) {
+		t.Error("expected chain with an invalid signature to be invalid")
+	}
+}
+
+func TestSchemeActivationsPerNetwork(t *testing.T) {
+	params := testParams(RegTestParams)
+	params.SchemeActivations = transactions.SchemeActivations{crypto.SchemeEd25519: 2}
+	bc := NewBlockchainWithParams(params)
+
+	// Policy keeps signatures of a scheme out of the mempool until it activates.
+	tx := spendGenesis(t, bc)
+	if err := bc.ValidateTransaction(tx); err == nil {
+		t.Error("expected a signature of an inactive scheme to be rejected by policy")
+	}
+
+	// Consensus leaves them unchecked, while other networks still enforce them.
+	tx.Vin[0].Signature[0] ^= 0xff
+	bc.AddBlock([]*transactions.Transaction{tx})
+	if !ChainValidationPredicate(bc) {
+		t.Error("expected the signature of an inactive scheme to be left unchecked")
+	}
+	if !MainNetParams.SchemeActivations.Active(crypto.SchemeEd25519, 1) {
+		t.Error("expected mainnet activations to be unaffected")
+	}
+}
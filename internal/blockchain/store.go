@@ -0,0 +1,67 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+)
+
+// newChain wraps blocks in a Blockchain with its caches and event bus.
+func newChain(blocks []*Block, params *ChainParams) *Blockchain {
+	return &Blockchain{
+		Blocks:   blocks,
+		params:   params,
+		sigCache: transactions.NewSigCache(transactions.DefaultSigCacheSize),
+		events:   NewEventBus(),
+	}
+}
+
+// Save writes the chain to path, replacing any previous file atomically.
+func (bc *Blockchain) Save(path string) error {
+	var encoded bytes.Buffer
+	if err := gob.NewEncoder(&encoded).Encode(bc.Blocks); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, encoded.Bytes(), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadBlockchain reads a chain of the network described by params written
+// by Save. It fails with ErrGenesisMismatch if the stored chain belongs to
+// another network.
+func LoadBlockchain(path string, params *ChainParams) (*Blockchain, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var blocks []*Block
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&blocks); err != nil {
+		return nil, fmt.Errorf("failed to decode chain %s: %w", path, err)
+	}
+	if len(blocks) == 0 {
+		return nil, errors.New("stored chain has no genesis block")
+	}
+	if err := params.CheckGenesis(blocks[0]); err != nil {
+		return nil, fmt.Errorf("stored chain %s: %w", path, err)
+	}
+	for height, block := range blocks {
+		if err := block.CheckLimits(); err != nil {
+			return nil, fmt.Errorf("stored chain %s: block %d: %w", path, height, err)
+		}
+		if err := params.CheckCheckpoint(height, block); err != nil {
+			return nil, fmt.Errorf("stored chain %s: %w", path, err)
+		}
+	}
+	return newChain(blocks, params), nil
+}
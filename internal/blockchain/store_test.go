@@ -0,0 +1,62 @@
+package blockchain
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+)
+
+func TestBlockchain_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "chain.dat")
+	bc := NewBlockchain()
+	bc.AddBlock([]*transactions.Transaction{createTransaction()})
+	if err := bc.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadBlockchain(path, &MainNetParams)
+	if err != nil {
+		t.Fatalf("LoadBlockchain failed: %v", err)
+	}
+	if len(loaded.Blocks) != 2 {
+		t.Fatalf("expected 2 blocks, got %d", len(loaded.Blocks))
+	}
+	for i := range bc.Blocks {
+		if !bytes.Equal(loaded.Blocks[i].Hash, bc.Blocks[i].Hash) {
+			t.Errorf("block %d hash mismatch", i)
+		}
+	}
+	if !loaded.IsValid() {
+		t.Error("expected loaded chain to be valid")
+	}
+	if loaded.Events() == nil {
+		t.Error("expected loaded chain to have an event bus")
+	}
+}
+
+func TestLoadBlockchain_Errors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := LoadBlockchain(filepath.Join(dir, "missing"), &MainNetParams); !os.IsNotExist(err) {
+		t.Errorf("expected not-exist error, got %v", err)
+	}
+
+	garbage := filepath.Join(dir, "garbage")
+	if err := os.WriteFile(garbage, []byte("not a chain"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadBlockchain(garbage, &MainNetParams); err == nil {
+		t.Error("expected error decoding garbage")
+	}
+
+	regtest := filepath.Join(dir, "regtest")
+	if err := NewBlockchainWithParams(&RegTestParams).Save(regtest); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadBlockchain(regtest, &MainNetParams); !errors.Is(err, ErrGenesisMismatch) {
+		t.Errorf("expected genesis mismatch loading a regtest chain as mainnet, got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrTimeTooOld is returned for a block timestamp not after the median
+	// time past of the previous blocks.
+	ErrTimeTooOld = errors.New("block timestamp is not after the median time past")
+	// ErrTimeTooNew is returned for a block timestamp too far ahead of the
+	// network-adjusted time.
+	ErrTimeTooNew = errors.New("block timestamp is too far in the future")
+)
+
+const (
+	// minTimeSamples is the number of peer samples needed before the median
+	// offset adjusts the local clock.
+	minTimeSamples = 5
+	// maxTimeSamples bounds the number of peer samples kept.
+	maxTimeSamples = 200
+	// maxTimeOffset is the largest adjustment applied to the local clock.
+	maxTimeOffset = 70 * time.Minute
+)
+
+// timeData collects the clock offsets reported by peers.
+type timeData struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	offset  time.Duration
+}
+
+// add records a sample and recomputes the median offset.
+func (d *timeData) add(offset time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.samples) == maxTimeSamples {
+		d.samples = d.samples[1:]
+	}
+	d.samples = append(d.samples, offset)
+	if len(d.samples) < minTimeSamples {
+		return
+	}
+
+	sorted := append([]time.Duration(nil), d.samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	median := sorted[len(sorted)/2]
+	// A local clock far from the network's is more likely wrong than every
+	// peer, but it is not trusted to move by more than maxTimeOffset.
+	if median > maxTimeOffset || median < -maxTimeOffset {
+		median = 0
+	}
+	d.offset = median
+}
+
+func (d *timeData) get() time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.offset
+}
+
+// AddTimeSample records the difference between a peer's clock and the local
+// clock. Once enough peers have reported, their median offset adjusts the
+// time used to validate block timestamps.
+func (bc *Blockchain) AddTimeSample(offset time.Duration) {
+	bc.timeData.add(offset)
+}
+
+// AdjustedTime returns the chain's clock corrected by the median peer offset.
+func (bc *Blockchain) AdjustedTime() time.Time {
+	return bc.Clock().Now().Add(bc.timeData.get())
+}
+
+// CheckBlockTime verifies that the timestamp of block, placed at height, is
+// after the median time past of the previous blocks and at most
+// MaxTimeDrift ahead of the network-adjusted time.
+func (bc *Blockchain) CheckBlockTime(block *Block, height int) error {
+	if height == 0 {
+		return nil
+	}
+	if medianTime := bc.MedianTimePast(height - 1); block.Timestamp <= medianTime {
+		return fmt.Errorf("%w: block at height %d has timestamp %d, median time past is %d", ErrTimeTooOld, height, block.Timestamp, medianTime)
+	}
+	limit := bc.AdjustedTime().Add(bc.Params().MaxTimeDrift).Unix()
+	if block.Timestamp > limit {
+		return fmt.Errorf("%w: block at height %d has timestamp %d, limit is %d", ErrTimeTooNew, height, block.Timestamp, limit)
+	}
+	return nil
+}
+
+// checkTimestamps reports whether every block of the chain has a valid
+// timestamp.
+func (bc *Blockchain) checkTimestamps() bool {
+	for height, block := range bc.Blocks {
+		if bc.CheckBlockTime(block, height) != nil {
+			return false
+		}
+	}
+	return true
+}
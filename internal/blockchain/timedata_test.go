@@ -0,0 +1,110 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+)
+
+const testTime = 1900000000
+
+// timedChain returns a regtest chain whose blocks after the genesis have the
+// given timestamps, with its clock stopped at testTime.
+func timedChain(t *testing.T, timestamps ...int64) *Blockchain {
+	bc := NewBlockchainWithParams(&RegTestParams)
+	bc.SetMockTime(testTime)
+	for _, ts := range timestamps {
+		bc.Blocks = append(bc.Blocks, craftBlock(bc, ts))
+	}
+	if !bc.IsValid() {
+		t.Fatal("expected the crafted chain to be valid")
+	}
+	return bc
+}
+
+// craftBlock mines a block extending bc with the given timestamp.
+func craftBlock(bc *Blockchain, timestamp int64) *Block {
+	height := len(bc.Blocks)
+	coinbase := transactions.NewCoinbaseTransaction("pubkey1", fmt.Sprintf("crafted %d %d", height, timestamp), bc.Params().Subsidy(height))
+	return mineBlock([]*transactions.Transaction{coinbase}, bc.Tip().Hash, timestamp, bc.Params().nextDifficulty(bc.Blocks))
+}
+
+func TestMedianTimePastRule(t *testing.T) {
+	// Timestamps may go backwards as long as they stay after the median of
+	// the last eleven blocks.
+	bc := timedChain(t, testTime-100, testTime-90, testTime-95, testTime-80, testTime-85)
+	median := bc.MedianTimePast(bc.Height())
+	if median != testTime-90 {
+		t.Fatalf("median time past %d, want %d", median, testTime-90)
+	}
+
+	for _, test := range []struct {
+		timestamp int64
+		err       error
+	}{
+		{median - 1, ErrTimeTooOld},
+		{median, ErrTimeTooOld},
+		{median + 1, nil},
+	} {
+		err := bc.CheckBlockTime(craftBlock(bc, test.timestamp), len(bc.Blocks))
+		if !errors.Is(err, test.err) {
+			t.Errorf("timestamp %d: got %v, want %v", test.timestamp, err, test.err)
+		}
+	}
+
+	bc.Blocks = append(bc.Blocks, craftBlock(bc, median))
+	if bc.IsValid() || ChainValidationPredicate(bc) {
+		t.Error("expected a block at the median time past to invalidate the chain")
+	}
+}
+
+func TestFutureDriftRule(t *testing.T) {
+	bc := timedChain(t, testTime-60)
+	limit := int64(testTime + RegTestParams.MaxTimeDrift/time.Second)
+
+	if err := bc.CheckBlockTime(craftBlock(bc, limit), 2); err != nil {
+		t.Errorf("timestamp at the drift limit: %v", err)
+	}
+	if err := bc.CheckBlockTime(craftBlock(bc, limit+1), 2); !errors.Is(err, ErrTimeTooNew) {
+		t.Errorf("timestamp past the drift limit: got %v, want ErrTimeTooNew", err)
+	}
+
+	// Peers ahead of the local clock move the limit once enough report.
+	for i := 0; i < minTimeSamples-1; i++ {
+		bc.AddTimeSample(10 * time.Minute)
+	}
+	if !bc.AdjustedTime().Equal(time.Unix(testTime, 0)) {
+		t.Error("expected too few samples to leave the time unadjusted")
+	}
+	bc.AddTimeSample(10 * time.Minute)
+	if err := bc.CheckBlockTime(craftBlock(bc, limit+600), 2); err != nil {
+		t.Errorf("timestamp within the adjusted drift limit: %v", err)
+	}
+
+	// Offsets larger than the maximum adjustment are ignored.
+	for i := 0; i < 2*minTimeSamples; i++ {
+		bc.AddTimeSample(3 * time.Hour)
+	}
+	if !bc.AdjustedTime().Equal(time.Unix(testTime, 0)) {
+		t.Errorf("adjusted time %v, want the local time", bc.AdjustedTime())
+	}
+
+	bc.Blocks = append(bc.Blocks, craftBlock(bc, limit+1))
+	if bc.IsValid() {
+		t.Error("expected a block too far in the future to invalidate the chain")
+	}
+}
+
+func TestMiningStaysAfterMedianTimePast(t *testing.T) {
+	bc := timedChain(t, testTime+100, testTime+200, testTime+300)
+	want := bc.MedianTimePast(bc.Height()) + 1
+	if block, err := bc.MineBlock("pubkey1", nil); err != nil || block.Timestamp != want {
+		t.Errorf("mined block %v, %v, want timestamp %d", block, err, want)
+	}
+	if !bc.IsValid() {
+		t.Error("expected the chain to stay valid")
+	}
+}
@@ -0,0 +1,194 @@
+package blockchain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+)
+
+var (
+	// ErrImmatureCoinbase is returned for transactions spending a coinbase
+	// output before it is CoinbaseMaturity blocks deep.
+	ErrImmatureCoinbase = errors.New("coinbase output spent before maturity")
+	// ErrBadCoinbase is returned for blocks with a coinbase after the first
+	// transaction or claiming more than the subsidy and fees.
+	ErrBadCoinbase = errors.New("invalid coinbase")
+)
+
+// UTXOEntry is an unspent output together with its outpoint and the height
+// of the block that created it.
+type UTXOEntry struct {
+	Txid       []byte
+	Vout       int
+	Output     transactions.TransactionOutput
+	Height     int
+	IsCoinbase bool // Whether a coinbase created the output, see CoinbaseMaturity
+}
+
+// UTXOSet maps transactions.UTXOKey keys to unspent outputs.
+type UTXOSet map[string]UTXOEntry
+
+// Outputs returns the plain output view expected by Transaction.Validate.
+func (s UTXOSet) Outputs() map[string]transactions.TransactionOutput {
+	outputs := make(map[string]transactions.TransactionOutput, len(s))
+	for key, entry := range s {
+		outputs[key] = entry.Output
+	}
+	return outputs
+}
+
+// connectBlock applies the spends and outputs of a block at height to the set.
+func (s UTXOSet) connectBlock(block *Block, height int) {
+	for _, tx := range block.Transactions {
+		s.connectTx(tx, height)
+	}
+}
+
+// connectTx applies the spends and outputs of tx, included at height, to the set.
+func (s UTXOSet) connectTx(tx *transactions.Transaction, height int) {
+	coinbase := tx.IsCoinbase()
+	if !coinbase {
+		for _, in := range tx.Vin {
+			delete(s, transactions.UTXOKey(in.Txid, in.Vout))
+		}
+	}
+	for i, out := range tx.Vout {
+		s[transactions.UTXOKey(tx.ID, i)] = UTXOEntry{Txid: tx.ID, Vout: i, Output: out, Height: height, IsCoinbase: coinbase}
+	}
+}
+
+// IsMature reports whether entry may be spent by a transaction included at
+// height: coinbase outputs must be CoinbaseMaturity blocks deep.
+func (bc *Blockchain) IsMature(entry UTXOEntry, height int) bool {
+	return !entry.IsCoinbase || height-entry.Height >= bc.Params().CoinbaseMaturity
+}
+
+// checkMaturity verifies that tx, included at height, spends no immature
+// coinbase output.
+func (bc *Blockchain) checkMaturity(tx *transactions.Transaction, height int, utxos UTXOSet) error {
+	if tx.IsCoinbase() {
+		return nil
+	}
+	for _, in := range tx.Vin {
+		entry, ok := utxos[transactions.UTXOKey(in.Txid, in.Vout)]
+		if ok && !bc.IsMature(entry, height) {
+			return fmt.Errorf("%w: transaction %x spends output %x:%d created at height %d, %d blocks are required",
+				ErrImmatureCoinbase, tx.ID, in.Txid, in.Vout, entry.Height, bc.Params().CoinbaseMaturity)
+		}
+	}
+	return nil
+}
+
+// connectTransactions checks the IDs, lock times, coinbase maturity and values
+// of the transactions of block at height against utxos, applying each one in
+// turn so later transactions may spend the outputs of earlier ones. Inputs
+// must spend an existing output that neither an earlier block nor an earlier
+// transaction of the block has spent, and the coinbase may claim no more than
+// the subsidy plus the fees of the block. The genesis coinbase is exempt.
+func (bc *Blockchain) connectTransactions(block *Block, height int, utxos UTXOSet) error {
+	fees, claimed := 0, 0
+	for i, tx := range block.Transactions {
+		if tx.IsCoinbase() && i > 0 {
+			return fmt.Errorf("%w: transaction %d of the block at height %d is a coinbase", ErrBadCoinbase, i, height)
+		}
+		if err := tx.CheckID(); err != nil {
+			return err
+		}
+		if err := bc.checkLocks(tx, height, utxos); err != nil {
+			return err
+		}
+		if err := bc.checkMaturity(tx, height, utxos); err != nil {
+			return err
+		}
+		if tx.IsCoinbase() {
+			value, err := tx.OutputValue()
+			if err != nil {
+				return fmt.Errorf("coinbase at height %d: %w", height, err)
+			}
+			claimed = value
+		} else {
+			fee, err := tx.CheckValues(utxos.prevOuts(tx))
+			if err != nil {
+				return fmt.Errorf("transaction %x at height %d: %w", tx.ID, height, err)
+			}
+			fees += fee
+		}
+		utxos.connectTx(tx, height)
+	}
+
+	if allowed := bc.Params().Subsidy(height) + fees; height > 0 && claimed > allowed {
+		return fmt.Errorf("%w: coinbase at height %d claims %d, at most %d is allowed", ErrBadCoinbase, height, claimed, allowed)
+	}
+	return nil
+}
+
+// prevOuts returns the outputs of the set spent by tx.
+func (s UTXOSet) prevOuts(tx *transactions.Transaction) map[string]transactions.TransactionOutput {
+	outputs := make(map[string]transactions.TransactionOutput, len(tx.Vin))
+	for _, in := range tx.Vin {
+		key := transactions.UTXOKey(in.Txid, in.Vout)
+		if entry, ok := s[key]; ok {
+			outputs[key] = entry.Output
+		}
+	}
+	return outputs
+}
+
+// utxoView is the UTXO set after the first count blocks of a chain. It is
+// updated incrementally as blocks are added and rebuilt when the blocks it
+// applied are no longer part of the chain.
+type utxoView struct {
+	mu    sync.Mutex
+	set   UTXOSet
+	count int    // Number of blocks applied to set
+	tip   []byte // Hash of the last block applied
+}
+
+// update brings the view to the tip of blocks. Callers must hold mu.
+func (v *utxoView) update(blocks []*Block) {
+	if v.set == nil || v.count > len(blocks) || (v.count > 0 && !bytes.Equal(blocks[v.count-1].Hash, v.tip)) {
+		v.set, v.count = make(UTXOSet), 0
+	}
+	for ; v.count < len(blocks); v.count++ {
+		v.set.connectBlock(blocks[v.count], v.count)
+	}
+	if v.count > 0 {
+		v.tip = blocks[v.count-1].Hash
+	}
+}
+
+// UTXOSet returns the set of unspent outputs at the tip of the chain.
+func (bc *Blockchain) UTXOSet() UTXOSet {
+	bc.utxos.mu.Lock()
+	defer bc.utxos.mu.Unlock()
+
+	bc.utxos.update(bc.Blocks)
+	set := make(UTXOSet, len(bc.utxos.set))
+	for key, entry := range bc.utxos.set {
+		set[key] = entry
+	}
+	return set
+}
+
+// spentEntries returns the entries at the tip of the chain spent by tx. Inputs
+// spending an output of pool, the unconfirmed outputs keyed by
+// transactions.UTXOKey, get an entry created by the next block.
+func (bc *Blockchain) spentEntries(tx *transactions.Transaction, pool map[string]transactions.TransactionOutput) UTXOSet {
+	bc.utxos.mu.Lock()
+	defer bc.utxos.mu.Unlock()
+
+	bc.utxos.update(bc.Blocks)
+	entries := make(UTXOSet, len(tx.Vin))
+	for _, in := range tx.Vin {
+		key := transactions.UTXOKey(in.Txid, in.Vout)
+		if entry, ok := bc.utxos.set[key]; ok {
+			entries[key] = entry
+		} else if out, ok := pool[key]; ok {
+			entries[key] = UTXOEntry{Txid: in.Txid, Vout: in.Vout, Output: out, Height: len(bc.Blocks)}
+		}
+	}
+	return entries
+}
@@ -0,0 +1,270 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+	"github.com/NicholasRodrigues/go-chain/pkg/crypto"
+)
+
+var (
+	// testKey signs the spends of the outputs paying testScript.
+	testKey, _ = crypto.NewPrivateKeyFromSeed(bytes.Repeat([]byte{1}, 32))
+	testScript = transactions.PayToPubKeyHashScript(transactions.HashPubKey(testKey.Public().Bytes()))
+)
+
+// testParams returns a copy of params whose genesis block pays testScript and
+// whose coinbase outputs can be spent right away.
+func testParams(params ChainParams) *ChainParams {
+	params.CoinbaseMaturity = 0
+	params.GenesisScript = testScript
+	_, params.GenesisNonce = NewProofOfWork(params.GenesisBlock()).Run()
+	params.GenesisHash = hex.EncodeToString(params.GenesisBlock().Hash)
+	params.Checkpoints = nil
+	return &params
+}
+
+// newMatureChain returns a regtest chain whose genesis output pays testScript
+// and whose coinbase outputs can be spent right away, for tests about other
+// validation rules.
+func newMatureChain() *Blockchain {
+	return NewBlockchainWithParams(testParams(RegTestParams))
+}
+
+// spendCoinbase returns a transaction spending the coinbase of the block at
+// height, which must pay testScript.
+func spendCoinbase(t *testing.T, bc *Blockchain, height int) *transactions.Transaction {
+	coinbase := bc.Blocks[height].Transactions[0]
+	tx := transactions.NewTransaction(
+		[]transactions.TransactionInput{{Txid: coinbase.ID, Vout: 0}},
+		[]transactions.TransactionOutput{{Value: coinbase.Vout[0].Value, ScriptPubKey: "pubkey2"}},
+	)
+	if err := tx.SignInput(0, testKey, coinbase.Vout[0]); err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	return tx
+}
+
+// addUncheckedBlock adds a block paying the subsidy to script and including
+// txs without the checks of MineBlock, to build invalid chains.
+func addUncheckedBlock(bc *Blockchain, script string, txs ...*transactions.Transaction) {
+	height := len(bc.Blocks)
+	coinbase := transactions.NewCoinbaseTransaction(script, fmt.Sprintf("height %d", height), bc.Params().Subsidy(height))
+	bc.AddBlock(append([]*transactions.Transaction{coinbase}, txs...))
+}
+
+func TestUTXOSet_RecordsCoinbase(t *testing.T) {
+	bc := newMatureChain()
+	tx := spendGenesis(t, bc)
+	bc.AddBlock([]*transactions.Transaction{tx})
+
+	utxos := bc.UTXOSet()
+	if len(utxos) != 1 {
+		t.Fatalf("expected 1 unspent output, got %d", len(utxos))
+	}
+	entry := utxos[transactions.UTXOKey(tx.ID, 0)]
+	if entry.IsCoinbase || entry.Height != 1 {
+		t.Errorf("expected a regular output created at height 1, got %+v", entry)
+	}
+
+	coinbase := newMatureChain().UTXOSet()[transactions.UTXOKey(bc.Blocks[0].Transactions[0].ID, 0)]
+	if !coinbase.IsCoinbase || coinbase.Height != 0 {
+		t.Errorf("expected the genesis output to be a coinbase at height 0, got %+v", coinbase)
+	}
+}
+
+func TestUTXOSet_FollowsTip(t *testing.T) {
+	bc := newMatureChain()
+	check := func(step string) {
+		t.Helper()
+		replay := make(UTXOSet)
+		for height, block := range bc.Blocks {
+			replay.connectBlock(block, height)
+		}
+		if !reflect.DeepEqual(bc.UTXOSet(), replay) {
+			t.Errorf("UTXO set after %s differs from replaying the chain", step)
+		}
+	}
+
+	check("genesis")
+	bc.MineBlock(testScript, []*transactions.Transaction{spendCoinbase(t, bc, 0)})
+	check("connecting a block")
+	bc.DisconnectTip()
+	check("disconnecting the tip")
+	// A different block at the same height replaces the one the set applied.
+	bc.MineBlock(testScript, nil)
+	check("replacing the tip")
+}
+
+func TestIsMature(t *testing.T) {
+	bc := NewBlockchainWithParams(&RegTestParams)
+	coinbase := UTXOEntry{Height: 10, IsCoinbase: true}
+	for _, test := range []struct {
+		entry  UTXOEntry
+		height int
+		mature bool
+	}{
+		{coinbase, 11, false},
+		{coinbase, 109, false},
+		{coinbase, 110, true},
+		{UTXOEntry{Height: 10}, 11, true},
+	} {
+		if got := bc.IsMature(test.entry, test.height); got != test.mature {
+			t.Errorf("IsMature(%+v, %d) = %v, want %v", test.entry, test.height, got, test.mature)
+		}
+	}
+}
+
+func TestValidateTransaction_RejectsImmatureCoinbase(t *testing.T) {
+	bc := NewBlockchainWithParams(&RegTestParams)
+	bc.SetMockTime(2000000000)
+	bc.MineBlock(testScript, nil)
+	tx := spendCoinbase(t, bc, 1)
+
+	// The coinbase at height 1 can be spent from height 1+CoinbaseMaturity.
+	for bc.Height()+1 < 1+RegTestParams.CoinbaseMaturity {
+		if err := bc.ValidateTransaction(tx); !errors.Is(err, ErrImmatureCoinbase) {
+			t.Fatalf("expected an immature coinbase error for the block at height %d, got %v", bc.Height()+1, err)
+		}
+		bc.MineBlock("pubkey1", nil)
+	}
+	if err := bc.ValidateTransaction(tx); err != nil {
+		t.Errorf("expected the coinbase to be mature for the block at height %d: %v", bc.Height()+1, err)
+	}
+	bc.MineBlock("pubkey1", []*transactions.Transaction{tx})
+	if !ChainValidationPredicate(bc) {
+		t.Error("expected a chain spending a mature coinbase to be valid")
+	}
+}
+
+func TestChainValidationPredicate_RejectsImmatureCoinbase(t *testing.T) {
+	bc := NewBlockchainWithParams(&RegTestParams)
+	bc.SetMockTime(2000000000)
+	bc.MineBlock(testScript, nil)
+	tx := spendCoinbase(t, bc, 1)
+	if _, err := bc.MineBlock("pubkey1", []*transactions.Transaction{tx}); !errors.Is(err, ErrImmatureCoinbase) {
+		t.Errorf("expected mining an immature coinbase spend to fail, got %v", err)
+	}
+	addUncheckedBlock(bc, "pubkey1", tx)
+	if ChainValidationPredicate(bc) {
+		t.Error("expected a chain spending an immature coinbase to be invalid")
+	}
+
+	// A coinbase spent within its own block is immature too.
+	bc = NewBlockchainWithParams(&RegTestParams)
+	bc.SetMockTime(2000000000)
+	coinbase := transactions.NewCoinbaseTransaction(testScript, "height 1", RegTestParams.Subsidy(1))
+	bc.Blocks = append(bc.Blocks, &Block{Transactions: []*transactions.Transaction{coinbase}})
+	tx = spendCoinbase(t, bc, 1)
+	bc.Blocks = bc.Blocks[:1]
+	bc.AddBlock([]*transactions.Transaction{coinbase, tx})
+	if ChainValidationPredicate(bc) {
+		t.Error("expected a chain spending a coinbase in its own block to be invalid")
+	}
+}
+
+func TestChainValidationPredicate_RejectsMissingInput(t *testing.T) {
+	bc := NewBlockchainWithParams(&RegTestParams)
+	bc.SetMockTime(2000000000)
+	tx := transactions.NewTransaction(
+		[]transactions.TransactionInput{{Txid: []byte("missing"), Vout: 0}},
+		[]transactions.TransactionOutput{{Value: 1000000, ScriptPubKey: "pubkey1"}},
+	)
+	if _, err := bc.MineBlock("pubkey1", []*transactions.Transaction{tx}); !errors.Is(err, transactions.ErrMissingInput) {
+		t.Errorf("expected mining a spend of a missing output to fail, got %v", err)
+	}
+	if bc.Height() != 0 {
+		t.Errorf("expected the rejected block not to be added, height is %d", bc.Height())
+	}
+	addUncheckedBlock(bc, "pubkey1", tx)
+	if ChainValidationPredicate(bc) {
+		t.Error("expected a chain spending a missing output to be invalid")
+	}
+}
+
+func TestConnectTransactions(t *testing.T) {
+	bc := newMatureChain()
+	genesisTx := bc.Blocks[0].Transactions[0]
+	genesis := transactions.TransactionInput{Txid: genesisTx.ID, Vout: 0}
+	value := genesisTx.Vout[0].Value
+	spend := func(ins []transactions.TransactionInput, values ...int) *transactions.Transaction {
+		var outs []transactions.TransactionOutput
+		for _, v := range values {
+			outs = append(outs, transactions.TransactionOutput{Value: v, ScriptPubKey: "pubkey1"})
+		}
+		return transactions.NewTransaction(ins, outs)
+	}
+	first := spend([]transactions.TransactionInput{genesis}, value)
+
+	for _, test := range []struct {
+		name string
+		txs  []*transactions.Transaction
+		err  error
+	}{
+		{"spends a missing output", []*transactions.Transaction{
+			spend([]transactions.TransactionInput{{Txid: []byte("missing"), Vout: 0}}, 1000000),
+		}, transactions.ErrMissingInput},
+		{"spends an output twice in a transaction", []*transactions.Transaction{
+			spend([]transactions.TransactionInput{genesis, genesis}, 2*value),
+		}, transactions.ErrMissingInput},
+		{"spends an output twice in a block", []*transactions.Transaction{
+			first, spend([]transactions.TransactionInput{genesis}, value-1),
+		}, transactions.ErrMissingInput},
+		{"outputs exceed inputs", []*transactions.Transaction{
+			spend([]transactions.TransactionInput{genesis}, value+1),
+		}, transactions.ErrInvalidValue},
+		{"negative output", []*transactions.Transaction{
+			spend([]transactions.TransactionInput{genesis}, value+10, -10),
+		}, transactions.ErrInvalidValue},
+		{"spends an output created earlier in the block", []*transactions.Transaction{
+			first, spend([]transactions.TransactionInput{{Txid: first.ID, Vout: 0}}, value),
+		}, nil},
+	} {
+		err := bc.connectTransactions(&Block{Transactions: test.txs}, 1, bc.UTXOSet())
+		if !errors.Is(err, test.err) {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.err)
+		}
+	}
+}
+
+func TestChainValidationPredicate_RejectsOverpayingCoinbase(t *testing.T) {
+	bc := newMatureChain()
+	bc.AddBlock([]*transactions.Transaction{transactions.NewCoinbaseTransaction("pubkey1", "height 1", 1<<40)})
+	if ChainValidationPredicate(bc) {
+		t.Error("expected a chain with an overpaying coinbase to be invalid")
+	}
+}
+
+func TestConnectTransactions_CoinbaseValue(t *testing.T) {
+	bc := newMatureChain()
+	genesisTx := bc.Blocks[0].Transactions[0]
+	tx := transactions.NewTransaction(
+		[]transactions.TransactionInput{{Txid: genesisTx.ID, Vout: 0}},
+		[]transactions.TransactionOutput{{Value: genesisTx.Vout[0].Value - 5, ScriptPubKey: "pubkey1"}},
+	)
+	subsidy := bc.Params().Subsidy(1)
+	coinbase := func(value int) *transactions.Transaction {
+		return transactions.NewCoinbaseTransaction("pubkey1", "height 1", value)
+	}
+
+	for _, test := range []struct {
+		name string
+		txs  []*transactions.Transaction
+		err  error
+	}{
+		{"claims the subsidy and fees", []*transactions.Transaction{coinbase(subsidy + 5), tx}, nil},
+		{"claims more than the subsidy and fees", []*transactions.Transaction{coinbase(subsidy + 6), tx}, ErrBadCoinbase},
+		{"second coinbase", []*transactions.Transaction{coinbase(subsidy), coinbase(subsidy + 1)}, ErrBadCoinbase},
+		{"negative output", []*transactions.Transaction{coinbase(-1)}, transactions.ErrInvalidValue},
+	} {
+		err := bc.connectTransactions(&Block{Transactions: test.txs}, 1, bc.UTXOSet())
+		if !errors.Is(err, test.err) {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.err)
+		}
+	}
+}
@@ -31,7 +31,8 @@ func ContentValidatePredicate(chain *Blockchain) bool {
 	return true
 }
 
-// InputContributionFunction processes input and adds a new block to the blockchain
+// InputContributionFunction processes input and adds a new block to the
+// blockchain, timestamped by the chain's clock.
 func InputContributionFunction(data []byte, chain *Blockchain, round int, input Input, receive Receive) {
 	inputData := input()
 	receiveData := receive()
@@ -42,8 +43,7 @@ func InputContributionFunction(data []byte, chain *Blockchain, round int, input
 		[]transactions.TransactionOutput{{Value: 50, ScriptPubKey: "pubkey1"}},
 	)
 
-	prevBlock := chain.Blocks[len(chain.Blocks)-1]
-	newBlock := NewBlock([]*transactions.Transaction{newTransaction}, prevBlock.Hash)
+	newBlock := chain.mineNext([]*transactions.Transaction{newTransaction})
 
 	chain.Blocks = append(chain.Blocks, newBlock)
 
@@ -52,6 +52,7 @@ func InputContributionFunction(data []byte, chain *Blockchain, round int, input
 		chain.Blocks = chain.Blocks[:len(chain.Blocks)-1]
 	} else {
 		fmt.Println("Content Validation Passed")
+		chain.events.Publish(Event{Type: BlockConnected, Block: newBlock, Height: len(chain.Blocks) - 1})
 	}
 }
 
@@ -78,8 +79,14 @@ func ChainValidationPredicate(chain *Blockchain) bool {
 			return false
 		}
 	}
+	if len(chain.Blocks) == 0 || chain.Params().CheckGenesis(chain.Blocks[0]) != nil {
+		return false
+	}
+	if !chain.checkCheckpoints() || !chain.checkDifficulty() || !chain.checkTimestamps() || !chain.checkBlockLimits() {
+		return false
+	}
 
-	return true
+	return validateTransactions(chain)
 }
 
 // MaxChain finds the best chain among multiple chains
@@ -0,0 +1,269 @@
+package mempool
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/NicholasRodrigues/go-chain/internal/blockchain"
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+)
+
+var (
+	// ErrCoinbase is returned when a coinbase transaction is submitted.
+	ErrCoinbase = errors.New("mempool: coinbase transactions are not relayed")
+	// ErrDuplicate is returned when the transaction is already in the pool.
+	ErrDuplicate = errors.New("mempool: transaction already in pool")
+	// ErrDoubleSpend is returned when an input is spent by another pool transaction.
+	ErrDoubleSpend = errors.New("mempool: input already spent by pool transaction")
+	// ErrNonFinal is returned when the transaction's lock times are not yet satisfied.
+	ErrNonFinal = errors.New("mempool: transaction is not final")
+)
+
+// Mempool holds validated transactions waiting to be included in a block.
+type Mempool struct {
+	mu     sync.RWMutex
+	chain  *blockchain.Blockchain
+	txs    map[string]*transactions.Transaction
+	spends map[string]string // UTXO key -> spending txid
+}
+
+// New creates an empty mempool validating against chain.
+func New(chain *blockchain.Blockchain) *Mempool {
+	return &Mempool{
+		chain:  chain,
+		txs:    make(map[string]*transactions.Transaction),
+		spends: make(map[string]string),
+	}
+}
+
+// Add validates tx against the chain tip and adds it to the pool. tx may spend
+// the outputs of other pool transactions.
+func (mp *Mempool) Add(tx *transactions.Transaction) error {
+	if tx.IsCoinbase() {
+		return ErrCoinbase
+	}
+	// The pool is keyed on the ID, so it must be the transaction's own.
+	if err := tx.CheckID(); err != nil {
+		return err
+	}
+
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	txid := hex.EncodeToString(tx.ID)
+	if _, ok := mp.txs[txid]; ok {
+		return ErrDuplicate
+	}
+	for _, in := range tx.Vin {
+		if _, ok := mp.spends[transactions.UTXOKey(in.Txid, in.Vout)]; ok {
+			return ErrDoubleSpend
+		}
+	}
+
+	if err := mp.chain.ValidateTransactionWithPool(tx, mp.poolOutputs(tx)); err != nil {
+		if errors.Is(err, blockchain.ErrNonFinal) {
+			return fmt.Errorf("%w: %v", ErrNonFinal, err)
+		}
+		return err
+	}
+
+	mp.txs[txid] = tx
+	for _, in := range tx.Vin {
+		mp.spends[transactions.UTXOKey(in.Txid, in.Vout)] = txid
+	}
+	mp.chain.Events().Publish(blockchain.Event{Type: blockchain.TxAccepted, Tx: tx})
+	return nil
+}
+
+// Get returns the pool transaction with the given id.
+func (mp *Mempool) Get(txid []byte) (*transactions.Transaction, bool) {
+	mp.mu.RLock()
+	defer mp.mu.RUnlock()
+
+	tx, ok := mp.txs[hex.EncodeToString(txid)]
+	return tx, ok
+}
+
+// poolOutputs returns the outputs of pool transactions spent by tx. Callers
+// must hold mu.
+func (mp *Mempool) poolOutputs(tx *transactions.Transaction) map[string]transactions.TransactionOutput {
+	outputs := make(map[string]transactions.TransactionOutput)
+	for _, in := range tx.Vin {
+		parent, ok := mp.txs[hex.EncodeToString(in.Txid)]
+		if ok && in.Vout >= 0 && in.Vout < len(parent.Vout) {
+			outputs[transactions.UTXOKey(in.Txid, in.Vout)] = parent.Vout[in.Vout]
+		}
+	}
+	return outputs
+}
+
+// sorted returns the pool's transaction IDs with every transaction after the
+// pool transactions it spends, so they can be included in a block in that
+// order. Callers must hold mu.
+func (mp *Mempool) sorted() []string {
+	txids := make([]string, 0, len(mp.txs))
+	for txid := range mp.txs {
+		txids = append(txids, txid)
+	}
+	sort.Strings(txids)
+
+	ordered := make([]string, 0, len(txids))
+	visited := make(map[string]bool, len(txids))
+	var visit func(txid string)
+	visit = func(txid string) {
+		tx, ok := mp.txs[txid]
+		if !ok || visited[txid] {
+			return
+		}
+		visited[txid] = true
+		for _, in := range tx.Vin {
+			visit(hex.EncodeToString(in.Txid))
+		}
+		ordered = append(ordered, txid)
+	}
+	for _, txid := range txids {
+		visit(txid)
+	}
+	return ordered
+}
+
+// Transactions returns the transactions currently in the pool, each after
+// the pool transactions it spends.
+func (mp *Mempool) Transactions() []*transactions.Transaction {
+	mp.mu.RLock()
+	defer mp.mu.RUnlock()
+
+	txs := make([]*transactions.Transaction, 0, len(mp.txs))
+	for _, txid := range mp.sorted() {
+		txs = append(txs, mp.txs[txid])
+	}
+	return txs
+}
+
+// Revalidate checks the pool's transactions against the chain tip again,
+// evicting those that are no longer valid, and returns the others in the
+// order of Transactions. Miners call it before building a block, as the tip
+// or the clock may have changed since the transactions were added.
+func (mp *Mempool) Revalidate() []*transactions.Transaction {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	txs := make([]*transactions.Transaction, 0, len(mp.txs))
+	for _, txid := range mp.sorted() {
+		// Evicting a transaction also evicts those spending it.
+		tx, ok := mp.txs[txid]
+		if !ok {
+			continue
+		}
+		if err := mp.chain.ValidateTransactionWithPool(tx, mp.poolOutputs(tx)); err != nil {
+			mp.evict(txid)
+			continue
+		}
+		txs = append(txs, tx)
+	}
+	return txs
+}
+
+// Count returns the number of transactions in the pool.
+func (mp *Mempool) Count() int {
+	mp.mu.RLock()
+	defer mp.mu.RUnlock()
+
+	return len(mp.txs)
+}
+
+// Remove evicts a transaction, and the pool transactions spending it, from
+// the pool.
+func (mp *Mempool) Remove(txid []byte) {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	mp.evict(hex.EncodeToString(txid))
+}
+
+func (mp *Mempool) remove(txid string) (*transactions.Transaction, bool) {
+	tx, ok := mp.txs[txid]
+	if !ok {
+		return nil, false
+	}
+	for _, in := range tx.Vin {
+		delete(mp.spends, transactions.UTXOKey(in.Txid, in.Vout))
+	}
+	delete(mp.txs, txid)
+	return tx, true
+}
+
+// evict removes an unconfirmed transaction and announces its eviction. Pool
+// transactions spending its outputs are evicted with it.
+func (mp *Mempool) evict(txid string) {
+	tx, ok := mp.remove(txid)
+	if !ok {
+		return
+	}
+	mp.chain.Events().Publish(blockchain.Event{Type: blockchain.TxEvicted, Tx: tx})
+	for i := range tx.Vout {
+		if spender, ok := mp.spends[transactions.UTXOKey(tx.ID, i)]; ok {
+			mp.evict(spender)
+		}
+	}
+}
+
+// RemoveBlock drops the transactions confirmed by block, along with any pool
+// transactions that conflict with them.
+func (mp *Mempool) RemoveBlock(block *blockchain.Block) {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	for _, tx := range block.Transactions {
+		mp.remove(hex.EncodeToString(tx.ID))
+		if tx.IsCoinbase() {
+			continue
+		}
+		for _, in := range tx.Vin {
+			if spender, ok := mp.spends[transactions.UTXOKey(in.Txid, in.Vout)]; ok {
+				mp.evict(spender)
+			}
+		}
+	}
+}
+
+// Save writes the pool's transactions to path so they survive a restart.
+func (mp *Mempool) Save(path string) error {
+	var encoded bytes.Buffer
+	if err := gob.NewEncoder(&encoded).Encode(mp.Transactions()); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, encoded.Bytes(), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Load adds the transactions saved at path back to the pool. Transactions
+// that are no longer valid, for example because a block confirmed them, are
+// skipped. It returns the number of transactions added.
+func (mp *Mempool) Load(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	var txs []*transactions.Transaction
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&txs); err != nil {
+		return 0, fmt.Errorf("failed to decode mempool %s: %w", path, err)
+	}
+
+	added := 0
+	for _, tx := range txs {
+		if mp.Add(tx) == nil {
+			added++
+		}
+	}
+	return added, nil
+}
@@ -0,0 +1,225 @@
+package mempool
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/NicholasRodrigues/go-chain/internal/blockchain"
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+	"github.com/NicholasRodrigues/go-chain/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	// genesisKey owns the genesis output of chains created by newChain.
+	genesisKey, _ = crypto.NewPrivateKeyFromSeed(bytes.Repeat([]byte{1}, 32))
+	genesisScript = transactions.PayToPubKeyHashScript(transactions.HashPubKey(genesisKey.Public().Bytes()))
+)
+
+// newChain returns a regtest chain whose genesis output, owned by genesisKey,
+// can be spent right away.
+func newChain() *blockchain.Blockchain {
+	params := blockchain.RegTestParams
+	params.CoinbaseMaturity = 0
+	params.GenesisScript = genesisScript
+	_, params.GenesisNonce = blockchain.NewProofOfWork(params.GenesisBlock()).Run()
+	params.GenesisHash = hex.EncodeToString(params.GenesisBlock().Hash)
+	return blockchain.NewBlockchainWithParams(&params)
+}
+
+func spendGenesis(t *testing.T, bc *blockchain.Blockchain, value int) *transactions.Transaction {
+	coinbase := bc.Blocks[0].Transactions[0]
+	tx := transactions.NewTransaction(
+		[]transactions.TransactionInput{{Txid: coinbase.ID, Vout: 0}},
+		[]transactions.TransactionOutput{{Value: value, ScriptPubKey: "pubkey1"}},
+	)
+	assert.NoError(t, tx.SignInput(0, genesisKey, coinbase.Vout[0]))
+	return tx
+}
+
+func TestMempoolAdd(t *testing.T) {
+	bc := newChain()
+	mp := New(bc)
+
+	tx := spendGenesis(t, bc, 50)
+	assert.NoError(t, mp.Add(tx))
+	assert.Equal(t, 1, mp.Count())
+
+	got, ok := mp.Get(tx.ID)
+	assert.True(t, ok)
+	assert.Equal(t, tx, got)
+
+	assert.ErrorIs(t, mp.Add(tx), ErrDuplicate)
+	assert.ErrorIs(t, mp.Add(spendGenesis(t, bc, 40)), ErrDoubleSpend)
+	assert.ErrorIs(t, mp.Add(bc.Blocks[0].Transactions[0]), ErrCoinbase)
+}
+
+func TestMempoolRejectsNonFinal(t *testing.T) {
+	bc := newChain()
+	mp := New(bc)
+
+	tx := spendGenesis(t, bc, 50)
+	tx.LockTime = 5
+	tx.SetID()
+	assert.True(t, errors.Is(mp.Add(tx), ErrNonFinal))
+	assert.Equal(t, 0, mp.Count())
+}
+
+func TestMempoolRejectsImmatureCoinbase(t *testing.T) {
+	bc := blockchain.NewBlockchain()
+	mp := New(bc)
+
+	assert.ErrorIs(t, mp.Add(spendGenesis(t, bc, 50)), blockchain.ErrImmatureCoinbase)
+	assert.Equal(t, 0, mp.Count())
+}
+
+func TestMempoolRejectsForgedID(t *testing.T) {
+	bc := newChain()
+	mp := New(bc)
+
+	// A transaction claiming the genesis coinbase's ID would shadow its outputs.
+	tx := spendGenesis(t, bc, 50)
+	tx.ID = bc.Blocks[0].Transactions[0].ID
+	assert.ErrorIs(t, mp.Add(tx), transactions.ErrIDMismatch)
+	assert.Equal(t, 0, mp.Count())
+}
+
+func TestMempoolRejectsInvalid(t *testing.T) {
+	bc := newChain()
+	mp := New(bc)
+
+	tx := spendGenesis(t, bc, 100)
+	assert.Error(t, mp.Add(tx))
+	assert.Equal(t, 0, mp.Count())
+}
+
+func TestMempoolRemoveBlock(t *testing.T) {
+	bc := newChain()
+	mp := New(bc)
+
+	tx := spendGenesis(t, bc, 50)
+	assert.NoError(t, mp.Add(tx))
+
+	bc.AddBlock([]*transactions.Transaction{tx})
+	mp.RemoveBlock(bc.Blocks[1])
+	assert.Equal(t, 0, mp.Count())
+
+	// The spent output is free again as far as the pool is concerned, but the
+	// chain no longer has it.
+	assert.Error(t, mp.Add(spendGenesis(t, bc, 50)))
+}
+
+func TestMempoolRevalidate(t *testing.T) {
+	bc := newChain()
+	mp := New(bc)
+
+	tx := spendGenesis(t, bc, 50)
+	assert.NoError(t, mp.Add(tx))
+	assert.Equal(t, []*transactions.Transaction{tx}, mp.Revalidate())
+
+	// A conflicting transaction confirmed behind the pool's back leaves tx
+	// spending a spent output.
+	bc.AddBlock([]*transactions.Transaction{spendGenesis(t, bc, 40)})
+	assert.Empty(t, mp.Revalidate())
+	assert.Equal(t, 0, mp.Count())
+}
+
+// spend returns a transaction paying value from the first output of parent,
+// which must pay genesisScript, back to genesisScript.
+func spend(t *testing.T, parent *transactions.Transaction, value int) *transactions.Transaction {
+	tx := transactions.NewTransaction(
+		[]transactions.TransactionInput{{Txid: parent.ID, Vout: 0}},
+		[]transactions.TransactionOutput{{Value: value, ScriptPubKey: genesisScript}},
+	)
+	assert.NoError(t, tx.SignInput(0, genesisKey, parent.Vout[0]))
+	return tx
+}
+
+func TestMempoolUnconfirmedParents(t *testing.T) {
+	bc := newChain()
+	mp := New(bc)
+
+	parent := spend(t, bc.Blocks[0].Transactions[0], 40)
+	child := spend(t, parent, 30)
+	assert.Error(t, mp.Add(child), "child of a transaction that is not in the pool")
+	assert.NoError(t, mp.Add(parent))
+	assert.NoError(t, mp.Add(child))
+	assert.ErrorIs(t, mp.Add(spend(t, parent, 20)), ErrDoubleSpend)
+
+	// Children follow their parents so the pool can be mined in order.
+	assert.Equal(t, []*transactions.Transaction{parent, child}, mp.Transactions())
+	assert.Equal(t, []*transactions.Transaction{parent, child}, mp.Revalidate())
+
+	block, err := bc.MineBlock(genesisScript, mp.Revalidate())
+	assert.NoError(t, err)
+	assert.Len(t, block.Transactions, 3)
+	assert.True(t, bc.IsValid())
+	mp.RemoveBlock(block)
+	assert.Equal(t, 0, mp.Count())
+}
+
+func TestMempoolEvictsDescendants(t *testing.T) {
+	bc := newChain()
+	mp := New(bc)
+
+	parent := spend(t, bc.Blocks[0].Transactions[0], 40)
+	child := spend(t, parent, 30)
+	assert.NoError(t, mp.Add(parent))
+	assert.NoError(t, mp.Add(child))
+	mp.Remove(parent.ID)
+	assert.Equal(t, 0, mp.Count())
+
+	// A block confirming a conflict of the parent evicts the child too.
+	assert.NoError(t, mp.Add(parent))
+	assert.NoError(t, mp.Add(child))
+	bc.AddBlock([]*transactions.Transaction{spend(t, bc.Blocks[0].Transactions[0], 35)})
+	mp.RemoveBlock(bc.Blocks[1])
+	assert.Equal(t, 0, mp.Count())
+}
+
+func TestMempoolEvents(t *testing.T) {
+	bc := newChain()
+	mp := New(bc)
+	sub := bc.Events().Subscribe(10, nil)
+	defer sub.Close()
+
+	tx := spendGenesis(t, bc, 50)
+	assert.NoError(t, mp.Add(tx))
+	e := <-sub.Events()
+	assert.Equal(t, blockchain.TxAccepted, e.Type)
+	assert.Equal(t, tx.ID, e.Tx.ID)
+
+	// Rejected transactions produce no event.
+	assert.Error(t, mp.Add(tx))
+	mp.Remove(tx.ID)
+	e = <-sub.Events()
+	assert.Equal(t, blockchain.TxEvicted, e.Type)
+	assert.Equal(t, tx.ID, e.Tx.ID)
+	assert.Empty(t, sub.Events())
+}
+
+func TestMempoolSaveLoad(t *testing.T) {
+	bc := newChain()
+	mp := New(bc)
+	tx := spendGenesis(t, bc, 50)
+	assert.NoError(t, mp.Add(tx))
+
+	path := filepath.Join(t.TempDir(), "mempool.dat")
+	assert.NoError(t, mp.Save(path))
+
+	restored := New(bc)
+	added, err := restored.Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, added)
+	_, ok := restored.Get(tx.ID)
+	assert.True(t, ok)
+
+	// Transactions confirmed since the pool was saved are dropped.
+	bc.AddBlock([]*transactions.Transaction{tx})
+	added, err = New(bc).Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, added)
+}
@@ -0,0 +1,471 @@
+// Package psbt implements partially signed transactions, which carry an
+// unsigned transaction through the steps of an offline signing workflow:
+//
+//  1. Creator: New wraps a transaction whose inputs are not signed.
+//  2. Updater: SetPrevOut, UpdateFromUTXOs and SetRedeemScript attach the
+//     outputs being spent and the redeem scripts of pay-to-script-hash
+//     inputs, which signers need but may not be able to look up.
+//  3. Signer: Sign adds signatures made by a key, usually on a machine that
+//     holds a keystore but no chain.
+//  4. Combiner: Combine merges the signatures of packets signed separately.
+//  5. Finalizer: Finalize builds the signed transaction, ready to broadcast.
+//
+// # Encoding
+//
+// A packet is exchanged as the standard base64 encoding of a JSON document:
+//
+//	{
+//	  "version": 1,
+//	  "tx": {
+//	    "locktime": 0,
+//	    "vin":  [{"txid": "<hex>", "vout": 0, "sequence": 0}],
+//	    "vout": [{"value": 50, "scriptPubKey": "<script>"}]
+//	  },
+//	  "inputs": [{
+//	    "prevout": {"value": 50, "scriptPubKey": "<script>"},
+//	    "redeemScript": "<script>",
+//	    "partialSigs": [{"pubkey": "<hex>", "signature": "<hex>"}]
+//	  }]
+//	}
+//
+// "inputs" has one entry per transaction input, in the same order. Entries
+// omit the fields that are not known yet. Signatures end with their sighash
+// type byte, like input signatures. Decode also accepts the JSON document
+// itself.
+package psbt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+	"github.com/NicholasRodrigues/go-chain/pkg/crypto"
+)
+
+// Version is the packet version written by this package.
+const Version = 1
+
+var (
+	// ErrIncomplete is returned by Finalize when inputs lack data or signatures.
+	ErrIncomplete = errors.New("psbt: transaction is not fully signed")
+	// ErrMismatch is returned by Combine for packets of different transactions.
+	ErrMismatch = errors.New("psbt: packets are for different transactions")
+)
+
+// Packet is a partially signed transaction.
+type Packet struct {
+	Tx     *transactions.Transaction // Unsigned transaction
+	Inputs []Input                   // Signing data for each input of Tx
+}
+
+// Input holds the data needed to sign and finalize one input.
+type Input struct {
+	PrevOut      *transactions.TransactionOutput // Output spent by the input, nil if unknown
+	RedeemScript string                          // Redeem script of a pay-to-script-hash output
+	PartialSigs  []PartialSig
+}
+
+// PartialSig is a signature made for an input by one key.
+type PartialSig struct {
+	PubKey    []byte
+	Signature []byte // Signature followed by its sighash type byte
+}
+
+// New returns a packet for tx, which must not be signed yet.
+func New(tx *transactions.Transaction) (*Packet, error) {
+	if tx.IsCoinbase() {
+		return nil, errors.New("psbt: coinbase transactions cannot be signed")
+	}
+	if len(tx.Vin) == 0 || len(tx.Vout) == 0 {
+		return nil, errors.New("psbt: transaction needs inputs and outputs")
+	}
+
+	unsigned := &transactions.Transaction{LockTime: tx.LockTime}
+	for i, in := range tx.Vin {
+		if len(in.Signature) > 0 || len(in.PubKey) > 0 || len(in.Witness) > 0 || in.ScriptSig != "" {
+			return nil, fmt.Errorf("psbt: input %d is already signed", i)
+		}
+		unsigned.Vin = append(unsigned.Vin, transactions.TransactionInput{Txid: in.Txid, Vout: in.Vout, Sequence: in.Sequence})
+	}
+	unsigned.Vout = append(unsigned.Vout, tx.Vout...)
+	unsigned.SetID()
+
+	return &Packet{Tx: unsigned, Inputs: make([]Input, len(tx.Vin))}, nil
+}
+
+func (p *Packet) input(i int) (*Input, error) {
+	if i < 0 || i >= len(p.Inputs) {
+		return nil, fmt.Errorf("psbt: input index %d out of range", i)
+	}
+	return &p.Inputs[i], nil
+}
+
+// SetPrevOut records the output spent by input i.
+func (p *Packet) SetPrevOut(i int, out transactions.TransactionOutput) error {
+	in, err := p.input(i)
+	if err != nil {
+		return err
+	}
+	if in.PrevOut != nil && *in.PrevOut != out {
+		return fmt.Errorf("psbt: input %d already spends a different output", i)
+	}
+	in.PrevOut = &out
+	return nil
+}
+
+// UpdateFromUTXOs records the outputs spent by inputs without one, looking
+// them up in utxos. It returns the number of inputs updated.
+func (p *Packet) UpdateFromUTXOs(utxos map[string]transactions.TransactionOutput) int {
+	updated := 0
+	for i, vin := range p.Tx.Vin {
+		if p.Inputs[i].PrevOut != nil {
+			continue
+		}
+		if out, ok := utxos[transactions.UTXOKey(vin.Txid, vin.Vout)]; ok {
+			p.Inputs[i].PrevOut = &out
+			updated++
+		}
+	}
+	return updated
+}
+
+// SetRedeemScript records the redeem script of input i, which must spend a
+// pay-to-script-hash output matching it if the output is known.
+func (p *Packet) SetRedeemScript(i int, script string) error {
+	in, err := p.input(i)
+	if err != nil {
+		return err
+	}
+	if in.PrevOut != nil {
+		hash, ok := transactions.ExtractScriptHash(in.PrevOut.ScriptPubKey)
+		if !ok {
+			return fmt.Errorf("psbt: input %d does not spend a script hash output", i)
+		}
+		if !bytes.Equal(transactions.HashScript(script), hash) {
+			return fmt.Errorf("psbt: redeem script does not match input %d", i)
+		}
+	}
+	in.RedeemScript = script
+	return nil
+}
+
+// signingScript returns the script whose conditions input i must satisfy, or
+// false if it is not known yet.
+func (p *Packet) signingScript(i int) (string, bool) {
+	in := p.Inputs[i]
+	if in.PrevOut == nil {
+		return "", false
+	}
+	if _, ok := transactions.ExtractScriptHash(in.PrevOut.ScriptPubKey); ok {
+		return in.RedeemScript, in.RedeemScript != ""
+	}
+	return in.PrevOut.ScriptPubKey, true
+}
+
+// canSign reports whether a signature by pubKey helps satisfy script.
+func canSign(script string, pubKey []byte) bool {
+	if hash, ok := transactions.ExtractPubKeyHash(script); ok {
+		return bytes.Equal(transactions.HashPubKey(pubKey), hash)
+	}
+	if multisig, ok := transactions.ParseMultisigScript(script); ok {
+		for _, key := range multisig.PubKeys {
+			if bytes.Equal(key.Bytes(), pubKey) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sigFrom returns the signature made by pubKey for in, if any.
+func (in *Input) sigFrom(pubKey []byte) ([]byte, bool) {
+	for _, sig := range in.PartialSigs {
+		if bytes.Equal(sig.PubKey, pubKey) {
+			return sig.Signature, true
+		}
+	}
+	return nil, false
+}
+
+// Sign signs with key every input it can help satisfy using SigHashAll and
+// returns the number of inputs signed. Inputs need their previous output, and
+// pay-to-script-hash inputs their redeem script. Pay-to-pubkey-hash and
+// multisig conditions are supported.
+func (p *Packet) Sign(key crypto.Signer) (int, error) {
+	pubKey := key.Public().Bytes()
+	signed := 0
+	for i := range p.Inputs {
+		in := &p.Inputs[i]
+		script, ok := p.signingScript(i)
+		if !ok || !canSign(script, pubKey) {
+			continue
+		}
+		if _, ok := in.sigFrom(pubKey); ok {
+			continue
+		}
+
+		digest, err := p.Tx.SignatureHash(i, *in.PrevOut, transactions.SigHashAll)
+		if err != nil {
+			return signed, err
+		}
+		in.PartialSigs = append(in.PartialSigs, PartialSig{
+			PubKey:    pubKey,
+			Signature: append(key.Sign(digest), byte(transactions.SigHashAll)),
+		})
+		signed++
+	}
+	return signed, nil
+}
+
+// Combine merges the data of packets for the same transaction into a new
+// packet.
+func Combine(packets ...*Packet) (*Packet, error) {
+	if len(packets) == 0 {
+		return nil, errors.New("psbt: nothing to combine")
+	}
+	combined, err := packets[0].clone()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, other := range packets[1:] {
+		if !bytes.Equal(other.Tx.ID, combined.Tx.ID) || len(other.Inputs) != len(combined.Inputs) {
+			return nil, ErrMismatch
+		}
+		for i, in := range other.Inputs {
+			if in.PrevOut != nil {
+				if err := combined.SetPrevOut(i, *in.PrevOut); err != nil {
+					return nil, err
+				}
+			}
+			if in.RedeemScript != "" {
+				if current := combined.Inputs[i].RedeemScript; current != "" && current != in.RedeemScript {
+					return nil, fmt.Errorf("psbt: input %d has conflicting redeem scripts", i)
+				}
+				combined.Inputs[i].RedeemScript = in.RedeemScript
+			}
+			for _, sig := range in.PartialSigs {
+				if _, ok := combined.Inputs[i].sigFrom(sig.PubKey); !ok {
+					combined.Inputs[i].PartialSigs = append(combined.Inputs[i].PartialSigs, sig)
+				}
+			}
+		}
+	}
+	return combined, nil
+}
+
+// Finalize builds the signed transaction and checks that it is valid. It
+// returns ErrIncomplete if an input lacks its previous output, redeem script
+// or enough signatures. The transaction ID covers the redeem scripts placed
+// in ScriptSig, so it differs from the packet's when there are any.
+func (p *Packet) Finalize() (*transactions.Transaction, error) {
+	tx := &transactions.Transaction{LockTime: p.Tx.LockTime, Vout: p.Tx.Vout}
+	prevOuts := make(map[string]transactions.TransactionOutput)
+
+	for i, vin := range p.Tx.Vin {
+		in := p.Inputs[i]
+		script, ok := p.signingScript(i)
+		if !ok {
+			return nil, fmt.Errorf("%w: input %d lacks its previous output or redeem script", ErrIncomplete, i)
+		}
+		prevOuts[transactions.UTXOKey(vin.Txid, vin.Vout)] = *in.PrevOut
+		if script != in.PrevOut.ScriptPubKey {
+			vin.ScriptSig = script
+		}
+
+		if hash, ok := transactions.ExtractPubKeyHash(script); ok {
+			for _, sig := range in.PartialSigs {
+				if bytes.Equal(transactions.HashPubKey(sig.PubKey), hash) {
+					vin.PubKey, vin.Signature = sig.PubKey, sig.Signature
+				}
+			}
+			if vin.Signature == nil {
+				return nil, fmt.Errorf("%w: input %d is not signed", ErrIncomplete, i)
+			}
+		} else if multisig, ok := transactions.ParseMultisigScript(script); ok {
+			// Signatures are placed in the order of the script's keys.
+			for _, key := range multisig.PubKeys {
+				if sig, ok := in.sigFrom(key.Bytes()); ok && len(vin.Witness) < multisig.M {
+					vin.Witness = append(vin.Witness, sig)
+				}
+			}
+			if len(vin.Witness) < multisig.M {
+				return nil, fmt.Errorf("%w: input %d has %d of %d signatures", ErrIncomplete, i, len(vin.Witness), multisig.M)
+			}
+		} else {
+			return nil, fmt.Errorf("psbt: input %d uses an unsupported script", i)
+		}
+		tx.Vin = append(tx.Vin, vin)
+	}
+	tx.SetID()
+
+	if !tx.Validate(prevOuts) {
+		return nil, errors.New("psbt: finalized transaction is invalid")
+	}
+	return tx, nil
+}
+
+// Fee returns the difference between the values of the previous outputs and
+// the outputs, or false if a previous output is unknown.
+func (p *Packet) Fee() (int, bool) {
+	fee := 0
+	for _, in := range p.Inputs {
+		if in.PrevOut == nil {
+			return 0, false
+		}
+		fee += in.PrevOut.Value
+	}
+	for _, out := range p.Tx.Vout {
+		fee -= out.Value
+	}
+	return fee, true
+}
+
+func (p *Packet) clone() (*Packet, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	var clone Packet
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return nil, err
+	}
+	return &clone, nil
+}
+
+// Encode returns the base64 encoding of the packet.
+func (p *Packet) Encode() (string, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// Decode parses a packet in base64 or JSON form.
+func Decode(s string) (*Packet, error) {
+	s = strings.TrimSpace(s)
+	data := []byte(s)
+	if !strings.HasPrefix(s, "{") {
+		var err error
+		if data, err = base64.StdEncoding.DecodeString(s); err != nil {
+			return nil, fmt.Errorf("psbt: invalid base64: %w", err)
+		}
+	}
+	var p Packet
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Portable JSON form of packets, see the package documentation.
+type (
+	wirePacket struct {
+		Version int         `json:"version"`
+		Tx      wireTx      `json:"tx"`
+		Inputs  []wireInput `json:"inputs"`
+	}
+	wireTx struct {
+		LockTime int64          `json:"locktime"`
+		Vin      []wireOutPoint `json:"vin"`
+		Vout     []wireOutput   `json:"vout"`
+	}
+	wireOutPoint struct {
+		Txid     string `json:"txid"`
+		Vout     int    `json:"vout"`
+		Sequence uint32 `json:"sequence"`
+	}
+	wireOutput struct {
+		Value        int    `json:"value"`
+		ScriptPubKey string `json:"scriptPubKey"`
+	}
+	wireInput struct {
+		PrevOut      *wireOutput      `json:"prevout,omitempty"`
+		RedeemScript string           `json:"redeemScript,omitempty"`
+		PartialSigs  []wirePartialSig `json:"partialSigs,omitempty"`
+	}
+	wirePartialSig struct {
+		PubKey    string `json:"pubkey"`
+		Signature string `json:"signature"`
+	}
+)
+
+// MarshalJSON encodes the packet in its portable JSON form.
+func (p *Packet) MarshalJSON() ([]byte, error) {
+	w := wirePacket{Version: Version, Tx: wireTx{LockTime: p.Tx.LockTime}}
+	for _, in := range p.Tx.Vin {
+		w.Tx.Vin = append(w.Tx.Vin, wireOutPoint{Txid: hex.EncodeToString(in.Txid), Vout: in.Vout, Sequence: in.Sequence})
+	}
+	for _, out := range p.Tx.Vout {
+		w.Tx.Vout = append(w.Tx.Vout, wireOutput{Value: out.Value, ScriptPubKey: out.ScriptPubKey})
+	}
+	for _, in := range p.Inputs {
+		wi := wireInput{RedeemScript: in.RedeemScript}
+		if in.PrevOut != nil {
+			wi.PrevOut = &wireOutput{Value: in.PrevOut.Value, ScriptPubKey: in.PrevOut.ScriptPubKey}
+		}
+		for _, sig := range in.PartialSigs {
+			wi.PartialSigs = append(wi.PartialSigs, wirePartialSig{PubKey: hex.EncodeToString(sig.PubKey), Signature: hex.EncodeToString(sig.Signature)})
+		}
+		w.Inputs = append(w.Inputs, wi)
+	}
+	return json.Marshal(w)
+}
+
+// UnmarshalJSON decodes the portable JSON form of a packet.
+func (p *Packet) UnmarshalJSON(data []byte) error {
+	var w wirePacket
+	if err := json.Unmarshal(data, &w); err != nil {
+		return fmt.Errorf("psbt: invalid packet: %w", err)
+	}
+	if w.Version != Version {
+		return fmt.Errorf("psbt: unsupported version %d", w.Version)
+	}
+	if len(w.Tx.Vin) == 0 || len(w.Inputs) != len(w.Tx.Vin) {
+		return errors.New("psbt: packet needs one input entry per transaction input")
+	}
+
+	tx := &transactions.Transaction{LockTime: w.Tx.LockTime}
+	for _, in := range w.Tx.Vin {
+		txid, err := hex.DecodeString(in.Txid)
+		if err != nil || len(txid) == 0 {
+			return fmt.Errorf("psbt: invalid input txid %q", in.Txid)
+		}
+		tx.Vin = append(tx.Vin, transactions.TransactionInput{Txid: txid, Vout: in.Vout, Sequence: in.Sequence})
+	}
+	for _, out := range w.Tx.Vout {
+		tx.Vout = append(tx.Vout, transactions.TransactionOutput{Value: out.Value, ScriptPubKey: out.ScriptPubKey})
+	}
+	tx.SetID()
+	if err := tx.CheckLimits(); err != nil {
+		return fmt.Errorf("psbt: %w", err)
+	}
+
+	inputs := make([]Input, len(w.Inputs))
+	for i, wi := range w.Inputs {
+		inputs[i].RedeemScript = wi.RedeemScript
+		if wi.PrevOut != nil {
+			inputs[i].PrevOut = &transactions.TransactionOutput{Value: wi.PrevOut.Value, ScriptPubKey: wi.PrevOut.ScriptPubKey}
+		}
+		for _, ws := range wi.PartialSigs {
+			pubKey, err := hex.DecodeString(ws.PubKey)
+			if err != nil {
+				return fmt.Errorf("psbt: invalid public key in input %d", i)
+			}
+			sig, err := hex.DecodeString(ws.Signature)
+			if err != nil {
+				return fmt.Errorf("psbt: invalid signature in input %d", i)
+			}
+			inputs[i].PartialSigs = append(inputs[i].PartialSigs, PartialSig{PubKey: pubKey, Signature: sig})
+		}
+	}
+
+	p.Tx, p.Inputs = tx, inputs
+	return nil
+}
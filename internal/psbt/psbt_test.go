@@ -0,0 +1,146 @@
+package psbt
+
+import (
+	"testing"
+
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+	"github.com/NicholasRodrigues/go-chain/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newKey(t *testing.T) *crypto.PrivateKey {
+	key, err := crypto.NewPrivateKey()
+	require.NoError(t, err)
+	return key
+}
+
+func p2pkh(key *crypto.PrivateKey) string {
+	return transactions.PayToPubKeyHashScript(transactions.HashPubKey(key.PublicKey().Bytes()))
+}
+
+// roundTrip encodes and decodes p, as happens when a packet moves between machines.
+func roundTrip(t *testing.T, p *Packet) *Packet {
+	encoded, err := p.Encode()
+	require.NoError(t, err)
+	decoded, err := Decode(encoded)
+	require.NoError(t, err)
+	return decoded
+}
+
+func TestPacketPayToPubKeyHash(t *testing.T) {
+	alice, bob := newKey(t), newKey(t)
+	utxos := map[string]transactions.TransactionOutput{
+		transactions.UTXOKey([]byte("prev-a"), 0): {Value: 30, ScriptPubKey: p2pkh(alice)},
+		transactions.UTXOKey([]byte("prev-b"), 1): {Value: 20, ScriptPubKey: p2pkh(bob)},
+	}
+	tx := transactions.NewTransaction(
+		[]transactions.TransactionInput{{Txid: []byte("prev-a"), Vout: 0}, {Txid: []byte("prev-b"), Vout: 1}},
+		[]transactions.TransactionOutput{{Value: 49, ScriptPubKey: p2pkh(alice)}},
+	)
+
+	p, err := New(tx)
+	require.NoError(t, err)
+	assert.Equal(t, tx.ID, p.Tx.ID)
+	_, ok := p.Fee()
+	assert.False(t, ok)
+
+	// Signers need the previous outputs.
+	signed, err := p.Sign(alice)
+	require.NoError(t, err)
+	assert.Equal(t, 0, signed)
+
+	assert.Equal(t, 2, p.UpdateFromUTXOs(utxos))
+	fee, ok := p.Fee()
+	assert.True(t, ok)
+	assert.Equal(t, 1, fee)
+
+	// Each party signs its own copy offline.
+	forAlice, forBob := roundTrip(t, p), roundTrip(t, p)
+	signed, err = forAlice.Sign(alice)
+	require.NoError(t, err)
+	assert.Equal(t, 1, signed)
+	signed, err = forBob.Sign(bob)
+	require.NoError(t, err)
+	assert.Equal(t, 1, signed)
+
+	_, err = roundTrip(t, forAlice).Finalize()
+	assert.ErrorIs(t, err, ErrIncomplete)
+
+	combined, err := Combine(roundTrip(t, forAlice), roundTrip(t, forBob))
+	require.NoError(t, err)
+	final, err := combined.Finalize()
+	require.NoError(t, err)
+	assert.Equal(t, tx.ID, final.ID)
+	assert.True(t, final.Validate(utxos))
+
+	// Signing again adds nothing.
+	signed, err = combined.Sign(alice)
+	require.NoError(t, err)
+	assert.Equal(t, 0, signed)
+}
+
+func TestPacketMultisig(t *testing.T) {
+	keys := []*crypto.PrivateKey{newKey(t), newKey(t), newKey(t)}
+	redeemScript, err := transactions.NewMultisigScript(2, []crypto.Verifier{keys[0].PublicKey(), keys[1].PublicKey(), keys[2].PublicKey()})
+	require.NoError(t, err)
+	prevOut := transactions.TransactionOutput{Value: 50, ScriptPubKey: transactions.PayToScriptHashScript(transactions.HashScript(redeemScript))}
+
+	tx := transactions.NewTransaction(
+		[]transactions.TransactionInput{{Txid: []byte("prev"), Vout: 0}},
+		[]transactions.TransactionOutput{{Value: 50, ScriptPubKey: p2pkh(keys[0])}},
+	)
+	p, err := New(tx)
+	require.NoError(t, err)
+	require.NoError(t, p.SetPrevOut(0, prevOut))
+	assert.Error(t, p.SetRedeemScript(0, "OP_TRUE"))
+	require.NoError(t, p.SetRedeemScript(0, redeemScript))
+
+	// Signatures are added in any order and placed in key order.
+	for _, key := range []*crypto.PrivateKey{keys[2], keys[0]} {
+		_, err = p.Finalize()
+		assert.ErrorIs(t, err, ErrIncomplete)
+		signed, err := p.Sign(key)
+		require.NoError(t, err)
+		assert.Equal(t, 1, signed)
+	}
+
+	final, err := roundTrip(t, p).Finalize()
+	require.NoError(t, err)
+	assert.Equal(t, redeemScript, final.Vin[0].ScriptSig)
+	assert.Len(t, final.Vin[0].Witness, 2)
+	assert.True(t, final.Validate(map[string]transactions.TransactionOutput{transactions.UTXOKey([]byte("prev"), 0): prevOut}))
+}
+
+func TestPacketErrors(t *testing.T) {
+	key := newKey(t)
+	prevOut := transactions.TransactionOutput{Value: 10, ScriptPubKey: p2pkh(key)}
+	tx := transactions.NewTransaction(
+		[]transactions.TransactionInput{{Txid: []byte("prev"), Vout: 0}},
+		[]transactions.TransactionOutput{{Value: 10, ScriptPubKey: p2pkh(key)}},
+	)
+
+	require.NoError(t, tx.SignInput(0, key, prevOut))
+	_, err := New(tx)
+	assert.Error(t, err, "signed transactions are rejected")
+
+	_, err = New(transactions.NewCoinbaseTransaction(p2pkh(key), "coinbase", 50))
+	assert.Error(t, err)
+
+	tx.Vin[0].Signature, tx.Vin[0].PubKey = nil, nil
+	p, err := New(tx)
+	require.NoError(t, err)
+	require.NoError(t, p.SetPrevOut(0, prevOut))
+	assert.Error(t, p.SetPrevOut(0, transactions.TransactionOutput{Value: 11, ScriptPubKey: p2pkh(key)}))
+	assert.Error(t, p.SetPrevOut(1, prevOut))
+
+	other, err := New(transactions.NewTransaction(tx.Vin, []transactions.TransactionOutput{{Value: 9, ScriptPubKey: p2pkh(key)}}))
+	require.NoError(t, err)
+	_, err = Combine(p, other)
+	assert.ErrorIs(t, err, ErrMismatch)
+
+	for _, s := range []string{"", "not base64!", "e30=", `{"version":2}`, `{"version":1,"tx":{"vin":[{"txid":"aa"}]},"inputs":[]}`} {
+		_, err := Decode(s)
+		assert.Error(t, err, s)
+	}
+}
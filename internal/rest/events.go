@@ -0,0 +1,158 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/NicholasRodrigues/go-chain/internal/blockchain"
+	"github.com/NicholasRodrigues/go-chain/internal/schema"
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+	"github.com/NicholasRodrigues/go-chain/pkg/address"
+)
+
+// keepAliveInterval is how often an idle event stream sends a comment so
+// that proxies and clients do not time the connection out.
+const keepAliveInterval = 15 * time.Second
+
+// handleEvents streams chain and mempool events as server-sent events. The
+// types query parameter limits the stream to a comma separated list of event
+// types, and each address parameter restricts it to transactions paying to or
+// spending from that address, and blocks containing such transactions. When
+// the client falls behind, events are dropped and a "lagged" event reports
+// how many were lost.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	bus := s.cfg.Chain.Events()
+	if bus == nil {
+		writeError(w, http.StatusServiceUnavailable, "chain does not publish events")
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+	filter, err := s.eventFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sub := bus.Subscribe(s.cfg.EventBuffer, filter)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	var reported uint64
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case e, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			if dropped := sub.Dropped(); dropped > reported {
+				writeEvent(w, "lagged", map[string]uint64{"dropped": dropped - reported})
+				reported = dropped
+			}
+			writeEvent(w, e.Type.String(), schema.NewEvent(e, s.cfg.Params))
+		}
+		flusher.Flush()
+	}
+}
+
+func writeEvent(w http.ResponseWriter, name string, data interface{}) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, encoded)
+}
+
+// eventFilter builds the subscription filter described by the request's
+// types and address query parameters.
+func (s *Server) eventFilter(r *http.Request) (func(blockchain.Event) bool, error) {
+	query := r.URL.Query()
+
+	var types map[blockchain.EventType]bool
+	for _, list := range query["types"] {
+		for _, name := range strings.Split(list, ",") {
+			t, ok := blockchain.ParseEventType(strings.TrimSpace(name))
+			if !ok {
+				return nil, fmt.Errorf("unknown event type %q", name)
+			}
+			if types == nil {
+				types = make(map[blockchain.EventType]bool)
+			}
+			types[t] = true
+		}
+	}
+
+	var scripts map[string]bool
+	for _, list := range query["address"] {
+		for _, encoded := range strings.Split(list, ",") {
+			addr, err := address.Decode(strings.TrimSpace(encoded), s.cfg.Params)
+			if err != nil {
+				return nil, fmt.Errorf("invalid address %q: %v", encoded, err)
+			}
+			if scripts == nil {
+				scripts = make(map[string]bool)
+			}
+			scripts[transactions.PayToAddrScript(addr)] = true
+		}
+	}
+
+	return func(e blockchain.Event) bool {
+		if types != nil && !types[e.Type] {
+			return false
+		}
+		if scripts == nil {
+			return true
+		}
+		if e.Tx != nil {
+			return touches(e.Tx, scripts)
+		}
+		if e.Block != nil {
+			for _, tx := range e.Block.Transactions {
+				if touches(tx, scripts) {
+					return true
+				}
+			}
+		}
+		return false
+	}, nil
+}
+
+// touches reports whether tx pays to or spends from one of scripts. Spends
+// are recognised from the public key of pay-to-pubkey-hash inputs and the
+// redeem script of pay-to-script-hash inputs.
+func touches(tx *transactions.Transaction, scripts map[string]bool) bool {
+	for _, out := range tx.Vout {
+		if scripts[out.ScriptPubKey] {
+			return true
+		}
+	}
+	if tx.IsCoinbase() {
+		return false
+	}
+	for _, in := range tx.Vin {
+		if len(in.PubKey) > 0 && scripts[transactions.PayToPubKeyHashScript(transactions.HashPubKey(in.PubKey))] {
+			return true
+		}
+		if in.ScriptSig != "" && scripts[transactions.PayToScriptHashScript(transactions.HashScript(in.ScriptSig))] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package rest
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NicholasRodrigues/go-chain/internal/blockchain"
+	"github.com/NicholasRodrigues/go-chain/internal/schema"
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+	"github.com/NicholasRodrigues/go-chain/pkg/address"
+	"github.com/NicholasRodrigues/go-chain/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readEvent returns the name and data of the next server-sent event.
+func readEvent(t *testing.T, r *bufio.Reader) (string, string) {
+	var name, data string
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "" && name != "":
+			return name, data
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	server, chain, addr := newTestServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/events?types=tx_accepted,tx_evicted&address="+addr.String(), nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	other := transactions.NewTransaction(
+		[]transactions.TransactionInput{{Txid: []byte("prev"), Vout: 0}},
+		[]transactions.TransactionOutput{{Value: 1, ScriptPubKey: "pubkey1"}},
+	)
+	watched := transactions.NewTransaction(
+		[]transactions.TransactionInput{{Txid: []byte("prev"), Vout: 1}},
+		[]transactions.TransactionOutput{{Value: 1, ScriptPubKey: transactions.PayToAddrScript(addr)}},
+	)
+	chain.Events().Publish(blockchain.Event{Type: blockchain.BlockConnected, Block: chain.Tip(), Height: 2})
+	chain.Events().Publish(blockchain.Event{Type: blockchain.TxAccepted, Tx: other})
+	chain.Events().Publish(blockchain.Event{Type: blockchain.TxAccepted, Tx: watched})
+
+	name, data := readEvent(t, bufio.NewReader(resp.Body))
+	assert.Equal(t, "tx_accepted", name)
+	var event schema.Event
+	require.NoError(t, json.Unmarshal([]byte(data), &event))
+	assert.Equal(t, "tx_accepted", event.Type)
+	require.NotNil(t, event.Tx)
+	assert.Equal(t, addr.String(), event.Tx.Vout[0].Address)
+}
+
+func TestEventStreamRejectsBadFilters(t *testing.T) {
+	server, _, _ := newTestServer(t)
+
+	for _, query := range []string{"types=nope", "address=nope"} {
+		resp, err := http.Get(server.URL + "/events?" + query)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+	}
+}
+
+func TestEventFilterMatchesSpends(t *testing.T) {
+	key, err := crypto.NewPrivateKey()
+	require.NoError(t, err)
+	addr, err := address.NewPubKeyHash(transactions.HashPubKey(key.PublicKey().Bytes()), &address.MainNetParams)
+	require.NoError(t, err)
+
+	server := New(Config{Chain: blockchain.NewBlockchain()})
+	req := httptest.NewRequest(http.MethodGet, "/events?address="+addr.String(), nil)
+	filter, err := server.eventFilter(req)
+	require.NoError(t, err)
+
+	spend := transactions.NewTransaction(
+		[]transactions.TransactionInput{{Txid: []byte("prev"), Vout: 0}},
+		[]transactions.TransactionOutput{{Value: 1, ScriptPubKey: "pubkey1"}},
+	)
+	block := &blockchain.Block{Transactions: []*transactions.Transaction{spend}}
+	assert.False(t, filter(blockchain.Event{Type: blockchain.TxAccepted, Tx: spend}))
+	assert.False(t, filter(blockchain.Event{Type: blockchain.BlockConnected, Block: block}))
+
+	// Signing with the address's key marks the input as spending from it.
+	spend.Vin[0].PubKey = key.PublicKey().Bytes()
+	assert.True(t, filter(blockchain.Event{Type: blockchain.TxAccepted, Tx: spend}))
+	assert.True(t, filter(blockchain.Event{Type: blockchain.BlockConnected, Block: block}))
+}
@@ -0,0 +1,319 @@
+// Package rest serves a read-only REST API over the chain, returning the
+// JSON representations defined by package schema, and streams chain events
+// to clients as server-sent events.
+package rest
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/NicholasRodrigues/go-chain/internal/blockchain"
+	"github.com/NicholasRodrigues/go-chain/internal/mempool"
+	"github.com/NicholasRodrigues/go-chain/internal/schema"
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+	"github.com/NicholasRodrigues/go-chain/pkg/address"
+)
+
+// DefaultAddr is the address the REST API listens on by default.
+const DefaultAddr = "127.0.0.1:9333"
+
+const (
+	// DefaultPageSize is the number of items returned when no limit is given.
+	DefaultPageSize = 50
+	// MaxPageSize is the largest limit a client may request.
+	MaxPageSize = 500
+)
+
+// Config holds the node components served by the API.
+type Config struct {
+	Chain  *blockchain.Blockchain
+	Pool   *mempool.Mempool // Optional; lets /tx return unconfirmed transactions
+	Params *address.Params
+	// ChainLock, if set, is read-locked while a request reads the chain.
+	// Event streams do not hold it.
+	ChainLock *sync.RWMutex
+	// EventBuffer is the number of events queued for each stream before
+	// events are dropped; DefaultEventBuffer when zero.
+	EventBuffer int
+}
+
+// UTXOPage is a page of the unspent outputs of an address.
+type UTXOPage struct {
+	Address string        `json:"address"`
+	Total   int           `json:"total"`
+	Offset  int           `json:"offset"`
+	Limit   int           `json:"limit"`
+	UTXOs   []schema.UTXO `json:"utxos"`
+}
+
+// TxPage is a page of the transactions of an address.
+type TxPage struct {
+	Address      string               `json:"address"`
+	Total        int                  `json:"total"`
+	Offset       int                  `json:"offset"`
+	Limit        int                  `json:"limit"`
+	Transactions []schema.Transaction `json:"transactions"`
+}
+
+// Error is the body of unsuccessful responses.
+type Error struct {
+	Error string `json:"error"`
+}
+
+// Server is an http.Handler serving the REST API.
+type Server struct {
+	cfg  Config
+	mux  *http.ServeMux
+	http *http.Server
+}
+
+// New creates a REST server.
+func New(cfg Config) *Server {
+	if cfg.Params == nil {
+		cfg.Params = &address.MainNetParams
+	}
+	if cfg.ChainLock == nil {
+		cfg.ChainLock = &sync.RWMutex{}
+	}
+
+	s := &Server{cfg: cfg, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /blocks/{hash}", s.locked(s.handleBlock))
+	s.mux.HandleFunc("GET /blocks/height/{n}", s.locked(s.handleBlockAtHeight))
+	s.mux.HandleFunc("GET /tx/{id}", s.locked(s.handleTransaction))
+	s.mux.HandleFunc("GET /address/{addr}/utxos", s.locked(s.handleAddressUTXOs))
+	s.mux.HandleFunc("GET /address/{addr}/txs", s.locked(s.handleAddressTxs))
+	s.mux.HandleFunc("GET /chain/tip", s.locked(s.handleTip))
+	s.mux.HandleFunc("GET /events", s.handleEvents)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// ListenAndServe serves the API on addr, which must be a loopback address.
+// It blocks until the server is closed.
+func (s *Server) ListenAndServe(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return fmt.Errorf("rest: refusing to listen on non-loopback address %s", addr)
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s.http = &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	err = s.http.Serve(listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Close stops the server, ending any event streams.
+func (s *Server) Close() error {
+	if s.http == nil {
+		return nil
+	}
+	return s.http.Close()
+}
+
+// locked runs h while holding the chain read lock.
+func (s *Server) locked(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.cfg.ChainLock.RLock()
+		defer s.cfg.ChainLock.RUnlock()
+		h(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, Error{Error: message})
+}
+
+func (s *Server) handleBlock(w http.ResponseWriter, r *http.Request) {
+	hash, err := hex.DecodeString(r.PathValue("hash"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "block hash must be hex")
+		return
+	}
+	block, height, ok := s.cfg.Chain.BlockByHash(hash)
+	if !ok {
+		writeError(w, http.StatusNotFound, "block not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, schema.NewBlock(s.cfg.Chain, block, height, true, s.cfg.Params))
+}
+
+func (s *Server) handleBlockAtHeight(w http.ResponseWriter, r *http.Request) {
+	height, err := strconv.Atoi(r.PathValue("n"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "height must be an integer")
+		return
+	}
+	block, ok := s.cfg.Chain.BlockAt(height)
+	if !ok {
+		writeError(w, http.StatusNotFound, "block height out of range")
+		return
+	}
+	writeJSON(w, http.StatusOK, schema.NewBlock(s.cfg.Chain, block, height, true, s.cfg.Params))
+}
+
+func (s *Server) handleTransaction(w http.ResponseWriter, r *http.Request) {
+	txid, err := hex.DecodeString(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "transaction id must be hex")
+		return
+	}
+
+	if s.cfg.Pool != nil {
+		if tx, ok := s.cfg.Pool.Get(txid); ok {
+			writeJSON(w, http.StatusOK, schema.NewTransaction(tx, s.cfg.Params))
+			return
+		}
+	}
+	tx, height, ok := s.cfg.Chain.FindTransaction(txid)
+	if !ok {
+		writeError(w, http.StatusNotFound, "transaction not found")
+		return
+	}
+	result := schema.NewTransaction(tx, s.cfg.Params)
+	block, _ := s.cfg.Chain.BlockAt(height)
+	result.SetBlock(s.cfg.Chain, block, height)
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleAddressUTXOs lists the confirmed unspent outputs paying to an address,
+// oldest first, paginated with the offset and limit query parameters.
+func (s *Server) handleAddressUTXOs(w http.ResponseWriter, r *http.Request) {
+	addr, err := address.Decode(r.PathValue("addr"), s.cfg.Params)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid address: "+err.Error())
+		return
+	}
+	offset, limit, ok := pagination(w, r)
+	if !ok {
+		return
+	}
+
+	script := transactions.PayToAddrScript(addr)
+	var entries []blockchain.UTXOEntry
+	for _, entry := range s.cfg.Chain.UTXOSet() {
+		if entry.Output.ScriptPubKey == script {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Height != entries[j].Height {
+			return entries[i].Height < entries[j].Height
+		}
+		if c := bytes.Compare(entries[i].Txid, entries[j].Txid); c != 0 {
+			return c < 0
+		}
+		return entries[i].Vout < entries[j].Vout
+	})
+
+	page := UTXOPage{Address: addr.String(), Total: len(entries), Offset: offset, Limit: limit, UTXOs: []schema.UTXO{}}
+	for i := offset; i < len(entries) && i < offset+limit; i++ {
+		page.UTXOs = append(page.UTXOs, schema.NewUTXO(s.cfg.Chain, entries[i]))
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// handleAddressTxs lists the confirmed transactions paying to or spending
+// from an address, oldest first, paginated with the offset and limit query
+// parameters.
+func (s *Server) handleAddressTxs(w http.ResponseWriter, r *http.Request) {
+	addr, err := address.Decode(r.PathValue("addr"), s.cfg.Params)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid address: "+err.Error())
+		return
+	}
+	offset, limit, ok := pagination(w, r)
+	if !ok {
+		return
+	}
+
+	type confirmed struct {
+		tx     *transactions.Transaction
+		height int
+	}
+	script := transactions.PayToAddrScript(addr)
+	owned := make(map[string]bool) // Outputs paying the address
+	var history []confirmed
+	for height, block := range s.cfg.Chain.Blocks {
+		for _, tx := range block.Transactions {
+			involved := false
+			if !tx.IsCoinbase() {
+				for _, in := range tx.Vin {
+					involved = involved || owned[transactions.UTXOKey(in.Txid, in.Vout)]
+				}
+			}
+			for i, out := range tx.Vout {
+				if out.ScriptPubKey == script {
+					owned[transactions.UTXOKey(tx.ID, i)] = true
+					involved = true
+				}
+			}
+			if involved {
+				history = append(history, confirmed{tx, height})
+			}
+		}
+	}
+
+	page := TxPage{Address: addr.String(), Total: len(history), Offset: offset, Limit: limit, Transactions: []schema.Transaction{}}
+	for i := offset; i < len(history) && i < offset+limit; i++ {
+		result := schema.NewTransaction(history[i].tx, s.cfg.Params)
+		block, _ := s.cfg.Chain.BlockAt(history[i].height)
+		result.SetBlock(s.cfg.Chain, block, history[i].height)
+		page.Transactions = append(page.Transactions, *result)
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) handleTip(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, schema.NewTip(s.cfg.Chain))
+}
+
+// pagination parses the offset and limit query parameters, writing an error
+// response when they are invalid.
+func pagination(w http.ResponseWriter, r *http.Request) (offset, limit int, ok bool) {
+	offset, limit = 0, DefaultPageSize
+	query := r.URL.Query()
+	if v := query.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "offset must be a non-negative integer")
+			return 0, 0, false
+		}
+		offset = n
+	}
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > MaxPageSize {
+			writeError(w, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(MaxPageSize))
+			return 0, 0, false
+		}
+		limit = n
+	}
+	return offset, limit, true
+}
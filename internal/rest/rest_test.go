@@ -0,0 +1,150 @@
+package rest
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NicholasRodrigues/go-chain/internal/blockchain"
+	"github.com/NicholasRodrigues/go-chain/internal/mempool"
+	"github.com/NicholasRodrigues/go-chain/internal/schema"
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+	"github.com/NicholasRodrigues/go-chain/pkg/address"
+	"github.com/NicholasRodrigues/go-chain/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer serves a chain whose two mined blocks pay addr.
+func newTestServer(t *testing.T) (*httptest.Server, *blockchain.Blockchain, *address.Address) {
+	key, err := crypto.NewPrivateKey()
+	require.NoError(t, err)
+	addr, err := address.NewPubKeyHash(transactions.HashPubKey(key.PublicKey().Bytes()), &address.MainNetParams)
+	require.NoError(t, err)
+
+	chain := blockchain.NewBlockchain()
+	for i := 1; i <= 2; i++ {
+		coinbase := transactions.NewCoinbaseTransaction(transactions.PayToAddrScript(addr), fmt.Sprintf("height %d", i), 50)
+		chain.AddBlock([]*transactions.Transaction{coinbase})
+	}
+
+	server := httptest.NewServer(New(Config{Chain: chain, Pool: mempool.New(chain)}))
+	t.Cleanup(server.Close)
+	return server, chain, addr
+}
+
+func get(t *testing.T, url string, v interface{}) int {
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+	return resp.StatusCode
+}
+
+func TestBlocksAndTip(t *testing.T) {
+	server, chain, addr := newTestServer(t)
+
+	var tip schema.Tip
+	require.Equal(t, http.StatusOK, get(t, server.URL+"/chain/tip", &tip))
+	assert.Equal(t, 2, tip.Height)
+	assert.Equal(t, hex.EncodeToString(chain.Tip().Hash), tip.Hash)
+
+	var byHeight, byHash struct {
+		schema.Block
+		Tx []schema.Transaction `json:"tx"`
+	}
+	require.Equal(t, http.StatusOK, get(t, server.URL+"/blocks/height/1", &byHeight))
+	require.Equal(t, http.StatusOK, get(t, server.URL+"/blocks/"+byHeight.Hash, &byHash))
+	assert.Equal(t, byHeight.Block.Hash, byHash.Block.Hash)
+	assert.Equal(t, 2, byHash.Confirmations)
+	assert.Equal(t, hex.EncodeToString(chain.Blocks[2].Hash), byHash.NextHash)
+	require.Len(t, byHash.Tx, 1)
+	assert.Equal(t, addr.String(), byHash.Tx[0].Vout[0].Address)
+	assert.Equal(t, byHash.Block.Hash, byHash.Tx[0].BlockHash)
+
+	var tx schema.Transaction
+	require.Equal(t, http.StatusOK, get(t, server.URL+"/tx/"+byHash.Tx[0].Txid, &tx))
+	assert.Equal(t, 2, tx.Confirmations)
+	require.NotNil(t, tx.Height)
+	assert.Equal(t, 1, *tx.Height)
+
+	var apiErr Error
+	assert.Equal(t, http.StatusNotFound, get(t, server.URL+"/blocks/height/9", &apiErr))
+	assert.Equal(t, http.StatusBadRequest, get(t, server.URL+"/blocks/height/x", &apiErr))
+	assert.Equal(t, http.StatusBadRequest, get(t, server.URL+"/blocks/zz", &apiErr))
+	assert.Equal(t, http.StatusNotFound, get(t, server.URL+"/tx/00", &apiErr))
+	assert.NotEmpty(t, apiErr.Error)
+}
+
+func TestAddressUTXOPagination(t *testing.T) {
+	server, _, addr := newTestServer(t)
+	url := server.URL + "/address/" + addr.String() + "/utxos"
+
+	var page UTXOPage
+	require.Equal(t, http.StatusOK, get(t, url, &page))
+	assert.Equal(t, 2, page.Total)
+	assert.Equal(t, DefaultPageSize, page.Limit)
+	require.Len(t, page.UTXOs, 2)
+	assert.Equal(t, 1, page.UTXOs[0].Height)
+	assert.Equal(t, 2, page.UTXOs[1].Height)
+
+	var second UTXOPage
+	require.Equal(t, http.StatusOK, get(t, url+"?offset=1&limit=1", &second))
+	assert.Equal(t, 2, second.Total)
+	require.Len(t, second.UTXOs, 1)
+	assert.Equal(t, page.UTXOs[1], second.UTXOs[0])
+
+	var empty UTXOPage
+	require.Equal(t, http.StatusOK, get(t, url+"?offset=5", &empty))
+	assert.NotNil(t, empty.UTXOs)
+	assert.Empty(t, empty.UTXOs)
+
+	// Base58 addresses are accepted too.
+	var base58 UTXOPage
+	require.Equal(t, http.StatusOK, get(t, server.URL+"/address/"+addr.EncodeBase58()+"/utxos", &base58))
+	assert.Equal(t, 2, base58.Total)
+
+	var apiErr Error
+	assert.Equal(t, http.StatusBadRequest, get(t, url+"?limit=0", &apiErr))
+	assert.Equal(t, http.StatusBadRequest, get(t, url+"?offset=-1", &apiErr))
+	assert.Equal(t, http.StatusBadRequest, get(t, server.URL+"/address/nope/utxos", &apiErr))
+}
+
+func TestAddressTxPagination(t *testing.T) {
+	server, chain, addr := newTestServer(t)
+	// Spending the address's first output adds a third transaction to its
+	// history.
+	spend := transactions.NewTransaction(
+		[]transactions.TransactionInput{{Txid: chain.Blocks[1].Transactions[0].ID, Vout: 0}},
+		[]transactions.TransactionOutput{{Value: 50, ScriptPubKey: "elsewhere"}},
+	)
+	chain.AddBlock([]*transactions.Transaction{spend})
+	url := server.URL + "/address/" + addr.String() + "/txs"
+
+	var page TxPage
+	require.Equal(t, http.StatusOK, get(t, url, &page))
+	assert.Equal(t, 3, page.Total)
+	require.Len(t, page.Transactions, 3)
+	assert.Equal(t, hex.EncodeToString(spend.ID), page.Transactions[2].Txid)
+	require.NotNil(t, page.Transactions[2].Height)
+	assert.Equal(t, 3, *page.Transactions[2].Height)
+
+	var second TxPage
+	require.Equal(t, http.StatusOK, get(t, url+"?offset=1&limit=1", &second))
+	assert.Equal(t, 3, second.Total)
+	require.Len(t, second.Transactions, 1)
+	assert.Equal(t, page.Transactions[1], second.Transactions[0])
+
+	var apiErr Error
+	assert.Equal(t, http.StatusBadRequest, get(t, url+"?limit=501", &apiErr))
+	assert.Equal(t, http.StatusBadRequest, get(t, server.URL+"/address/nope/txs", &apiErr))
+}
+
+func TestListenAndServeRequiresLoopback(t *testing.T) {
+	server := New(Config{Chain: blockchain.NewBlockchain()})
+	assert.Error(t, server.ListenAndServe("0.0.0.0:0"))
+}
@@ -0,0 +1,47 @@
+package rpc
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CookieUser is the user name written to cookie files.
+const CookieUser = "__cookie__"
+
+// NewCookie returns fresh random credentials for cookie authentication.
+func NewCookie() (user, password string, err error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	return CookieUser, hex.EncodeToString(secret), nil
+}
+
+// WriteCookie stores "user:password" at path, readable only by the owner.
+func WriteCookie(path, user, password string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(user+":"+password), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// ReadCookie loads the credentials stored at path by WriteCookie.
+func ReadCookie(path string) (user, password string, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+	user, password, ok := strings.Cut(strings.TrimSpace(string(data)), ":")
+	if !ok {
+		return "", "", fmt.Errorf("rpc: malformed cookie file %s", path)
+	}
+	return user, password, nil
+}
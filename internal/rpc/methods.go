@@ -0,0 +1,307 @@
+package rpc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/NicholasRodrigues/go-chain/internal/blockchain"
+	"github.com/NicholasRodrigues/go-chain/internal/mempool"
+	"github.com/NicholasRodrigues/go-chain/internal/schema"
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+	"github.com/NicholasRodrigues/go-chain/pkg/address"
+)
+
+// method is an RPC method taking positional params named by params. Methods
+// that modify the chain set write to run under the write lock of ChainLock.
+type method struct {
+	params  []string
+	handler func(params []json.RawMessage) (interface{}, *Error)
+	write   bool
+}
+
+func (s *Server) methodTable() map[string]method {
+	return map[string]method{
+		"generate":           {[]string{"nblocks", "address"}, s.generate, true},
+		"getbestblockhash":   {nil, s.getBestBlockHash, false},
+		"getblock":           {[]string{"blockhash", "verbosity"}, s.getBlock, false},
+		"getblockcount":      {nil, s.getBlockCount, false},
+		"getblockhash":       {[]string{"height"}, s.getBlockHash, false},
+		"getbalance":         {nil, s.getBalance, false},
+		"getmempoolinfo":     {nil, s.getMempoolInfo, false},
+		"gettransaction":     {[]string{"txid"}, s.getTransaction, false},
+		"invalidateblock":    {[]string{"blockhash"}, s.invalidateBlock, true},
+		"sendrawtransaction": {[]string{"hexstring"}, s.sendRawTransaction, false},
+		"setmocktime":        {[]string{"timestamp"}, s.setMockTime, true},
+		"validatechain":      {nil, s.validateChain, false},
+	}
+}
+
+// param decodes params[i] into v. Missing optional params leave v unchanged.
+func param(params []json.RawMessage, i int, name string, v interface{}, required bool) *Error {
+	if i >= len(params) || len(params[i]) == 0 || string(params[i]) == "null" {
+		if required {
+			return invalidParams("missing param " + name)
+		}
+		return nil
+	}
+	if err := json.Unmarshal(params[i], v); err != nil {
+		return invalidParams("invalid param " + name + ": " + err.Error())
+	}
+	return nil
+}
+
+// hexParam decodes a required hex encoded param.
+func hexParam(params []json.RawMessage, i int, name string) ([]byte, *Error) {
+	var s string
+	if err := param(params, i, name, &s, true); err != nil {
+		return nil, err
+	}
+	hash, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, invalidParams("invalid param " + name + ": not hex")
+	}
+	return hash, nil
+}
+
+func (s *Server) getBestBlockHash(params []json.RawMessage) (interface{}, *Error) {
+	return hex.EncodeToString(s.cfg.Chain.Tip().Hash), nil
+}
+
+func (s *Server) getBlockCount(params []json.RawMessage) (interface{}, *Error) {
+	return s.cfg.Chain.Height(), nil
+}
+
+func (s *Server) getBlockHash(params []json.RawMessage) (interface{}, *Error) {
+	var height int
+	if err := param(params, 0, "height", &height, true); err != nil {
+		return nil, err
+	}
+	block, ok := s.cfg.Chain.BlockAt(height)
+	if !ok {
+		return nil, &Error{Code: CodeNotFound, Message: "block height out of range"}
+	}
+	return hex.EncodeToString(block.Hash), nil
+}
+
+// getBlock returns the serialized block for verbosity 0, the block with
+// transaction ids for verbosity 1 (the default) and the block with full
+// transactions for verbosity 2.
+func (s *Server) getBlock(params []json.RawMessage) (interface{}, *Error) {
+	hash, rpcErr := hexParam(params, 0, "blockhash")
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	verbosity := 1
+	if err := param(params, 1, "verbosity", &verbosity, false); err != nil {
+		return nil, err
+	}
+	if verbosity < 0 || verbosity > 2 {
+		return nil, invalidParams("verbosity must be 0, 1 or 2")
+	}
+
+	block, height, ok := s.cfg.Chain.BlockByHash(hash)
+	if !ok {
+		return nil, &Error{Code: CodeNotFound, Message: "block not found"}
+	}
+	if verbosity == 0 {
+		return hex.EncodeToString(block.Serialize()), nil
+	}
+	return schema.NewBlock(s.cfg.Chain, block, height, verbosity == 2, s.cfg.Params), nil
+}
+
+// getTransaction looks a transaction up in the mempool and then the chain.
+func (s *Server) getTransaction(params []json.RawMessage) (interface{}, *Error) {
+	txid, rpcErr := hexParam(params, 0, "txid")
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	if s.cfg.Pool != nil {
+		if tx, ok := s.cfg.Pool.Get(txid); ok {
+			return schema.NewTransaction(tx, s.cfg.Params), nil
+		}
+	}
+	tx, height, ok := s.cfg.Chain.FindTransaction(txid)
+	if !ok {
+		return nil, &Error{Code: CodeNotFound, Message: "transaction not found"}
+	}
+	result := schema.NewTransaction(tx, s.cfg.Params)
+	block, _ := s.cfg.Chain.BlockAt(height)
+	result.SetBlock(s.cfg.Chain, block, height)
+	return result, nil
+}
+
+// sendRawTransaction decodes a hex serialized transaction and adds it to the
+// mempool, returning its id.
+func (s *Server) sendRawTransaction(params []json.RawMessage) (interface{}, *Error) {
+	raw, rpcErr := hexParam(params, 0, "hexstring")
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	if s.cfg.Pool == nil {
+		return nil, &Error{Code: CodeInternalError, Message: "no mempool"}
+	}
+	tx, err := transactions.DecodeTransaction(raw)
+	if err != nil {
+		return nil, invalidParams(err.Error())
+	}
+
+	if err := s.cfg.Pool.Add(tx); err != nil {
+		code := CodeVerifyRejected
+		if errors.Is(err, mempool.ErrDuplicate) {
+			code = CodeVerifyDuplicate
+		}
+		return nil, &Error{Code: code, Message: err.Error()}
+	}
+	return hex.EncodeToString(tx.ID), nil
+}
+
+func (s *Server) getBalance(params []json.RawMessage) (interface{}, *Error) {
+	if s.cfg.Wallet == nil {
+		return nil, &Error{Code: CodeWalletNotFound, Message: "no wallet loaded"}
+	}
+	balance := s.cfg.Wallet.Balance()
+	return BalanceResult{Confirmed: balance.Confirmed, Immature: balance.Immature, Unconfirmed: balance.Unconfirmed}, nil
+}
+
+func (s *Server) getMempoolInfo(params []json.RawMessage) (interface{}, *Error) {
+	info := MempoolInfoResult{}
+	if s.cfg.Pool != nil {
+		for _, tx := range s.cfg.Pool.Transactions() {
+			info.Size++
+			info.Bytes += len(tx.Serialize())
+		}
+	}
+	return info, nil
+}
+
+func (s *Server) validateChain(params []json.RawMessage) (interface{}, *Error) {
+	return blockchain.ChainValidationPredicate(s.cfg.Chain), nil
+}
+
+// regTestOnly returns an error unless the chain is a regression test network.
+func (s *Server) regTestOnly(method string) *Error {
+	if !s.cfg.Chain.Params().RegTest {
+		return &Error{Code: CodeMethodNotFound, Message: method + " is only available on regtest"}
+	}
+	return nil
+}
+
+// maxGenerateBlocks bounds the blocks a generate call mines, as the chain
+// stays locked until they are all mined.
+const maxGenerateBlocks = 1000
+
+// generate mines nblocks blocks including the mempool's transactions that are
+// still valid and returns their hashes. The coinbases pay address, or the
+// wallet's first address if it is omitted.
+func (s *Server) generate(params []json.RawMessage) (interface{}, *Error) {
+	if err := s.regTestOnly("generate"); err != nil {
+		return nil, err
+	}
+	var count int
+	if err := param(params, 0, "nblocks", &count, true); err != nil {
+		return nil, err
+	}
+	if count < 1 || count > maxGenerateBlocks {
+		return nil, invalidParams(fmt.Sprintf("nblocks must be between 1 and %d", maxGenerateBlocks))
+	}
+	var encoded string
+	if err := param(params, 1, "address", &encoded, false); err != nil {
+		return nil, err
+	}
+
+	var addr *address.Address
+	if encoded != "" {
+		var err error
+		if addr, err = address.Decode(encoded, s.cfg.Params); err != nil {
+			return nil, invalidParams("invalid param address: " + err.Error())
+		}
+	} else {
+		if s.cfg.Wallet == nil {
+			return nil, &Error{Code: CodeWalletNotFound, Message: "no wallet loaded, pass an address"}
+		}
+		addrs := s.cfg.Wallet.Addresses()
+		if len(addrs) == 0 {
+			return nil, &Error{Code: CodeWalletNotFound, Message: "the wallet has no address"}
+		}
+		addr = addrs[0]
+	}
+
+	hashes := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		var txs []*transactions.Transaction
+		if s.cfg.Pool != nil {
+			txs = s.cfg.Pool.Revalidate()
+		}
+		block, err := s.cfg.Chain.MineBlock(transactions.PayToAddrScript(addr), txs)
+		if err != nil {
+			return nil, &Error{Code: CodeInternalError, Message: err.Error()}
+		}
+		if s.cfg.Pool != nil {
+			s.cfg.Pool.RemoveBlock(block)
+		}
+		hashes = append(hashes, hex.EncodeToString(block.Hash))
+	}
+	return hashes, nil
+}
+
+// setMockTime sets the time used for new blocks, in Unix seconds. Zero
+// restores the system clock.
+func (s *Server) setMockTime(params []json.RawMessage) (interface{}, *Error) {
+	if err := s.regTestOnly("setmocktime"); err != nil {
+		return nil, err
+	}
+	var timestamp int64
+	if err := param(params, 0, "timestamp", &timestamp, true); err != nil {
+		return nil, err
+	}
+	if timestamp < 0 {
+		return nil, invalidParams("timestamp must not be negative")
+	}
+	s.cfg.Chain.SetMockTime(timestamp)
+	return nil, nil
+}
+
+// invalidateBlock disconnects the block with the given hash and every block
+// after it. The transactions of the disconnected blocks return to the
+// mempool, and pool transactions spending their coinbases are evicted.
+func (s *Server) invalidateBlock(params []json.RawMessage) (interface{}, *Error) {
+	if err := s.regTestOnly("invalidateblock"); err != nil {
+		return nil, err
+	}
+	hash, rpcErr := hexParam(params, 0, "blockhash")
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	_, height, ok := s.cfg.Chain.BlockByHash(hash)
+	if !ok {
+		return nil, &Error{Code: CodeNotFound, Message: "block not found"}
+	}
+	if checkpoint, ok := s.cfg.Chain.Params().LastCheckpoint(s.cfg.Chain.Height()); height == 0 || ok && checkpoint.Height >= height {
+		return nil, invalidParams("the genesis block and checkpointed blocks cannot be invalidated")
+	}
+
+	var disconnected []*blockchain.Block
+	for s.cfg.Chain.Height() >= height {
+		block, ok := s.cfg.Chain.DisconnectTip()
+		if !ok {
+			return nil, &Error{Code: CodeInternalError, Message: "failed to disconnect the tip"}
+		}
+		disconnected = append(disconnected, block)
+	}
+	if s.cfg.Pool != nil {
+		// Blocks were disconnected from the tip, so add their transactions
+		// back oldest first.
+		for i := len(disconnected) - 1; i >= 0; i-- {
+			for _, tx := range disconnected[i].Transactions {
+				if !tx.IsCoinbase() {
+					s.cfg.Pool.Add(tx)
+				}
+			}
+		}
+		s.cfg.Pool.Revalidate()
+	}
+	return nil, nil
+}
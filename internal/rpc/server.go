@@ -0,0 +1,330 @@
+// Package rpc implements a JSON-RPC 2.0 server over HTTP for querying and
+// controlling a node. Requests are authenticated with credentials stored in
+// a cookie file that only the local user can read.
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/NicholasRodrigues/go-chain/internal/blockchain"
+	"github.com/NicholasRodrigues/go-chain/internal/mempool"
+	"github.com/NicholasRodrigues/go-chain/internal/wallet"
+	"github.com/NicholasRodrigues/go-chain/pkg/address"
+)
+
+// DefaultAddr is the address the server listens on by default.
+const DefaultAddr = "127.0.0.1:9332"
+
+// maxRequestSize bounds the size of a request body.
+const maxRequestSize = 4 << 20
+
+// Standard JSON-RPC 2.0 error codes, followed by node specific codes.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+
+	CodeNotFound        = -5  // Block or transaction not found
+	CodeWalletNotFound  = -18 // No wallet is loaded
+	CodeVerifyRejected  = -26 // Transaction rejected by the mempool
+	CodeVerifyDuplicate = -27 // Transaction already in the mempool
+)
+
+// Error is a JSON-RPC error object.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// Request is a JSON-RPC request. Requests without an id are notifications
+// and receive no response.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+// Response is a JSON-RPC response.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// Config holds the node components served over RPC.
+type Config struct {
+	Chain  *blockchain.Blockchain
+	Pool   *mempool.Mempool
+	Wallet *wallet.Wallet  // Optional; required by getbalance
+	Params *address.Params // Address encoding used in results
+	// ChainLock, if set, is read-locked while a request runs. Code that
+	// modifies Chain while the server is running must hold its write lock.
+	ChainLock *sync.RWMutex
+	// CookiePath is where the credentials are written when the server starts
+	// listening. It is removed again by Close.
+	CookiePath string
+}
+
+// Server answers JSON-RPC requests.
+type Server struct {
+	cfg      Config
+	user     string
+	password string
+	methods  map[string]method
+	http     *http.Server
+}
+
+// New creates a server with fresh cookie credentials.
+func New(cfg Config) (*Server, error) {
+	if cfg.Params == nil {
+		cfg.Params = &address.MainNetParams
+	}
+	if cfg.ChainLock == nil {
+		cfg.ChainLock = &sync.RWMutex{}
+	}
+	user, password, err := NewCookie()
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{cfg: cfg, user: user, password: password}
+	s.methods = s.methodTable()
+	return s, nil
+}
+
+// SetWallet replaces the wallet used by wallet methods. It takes the write
+// lock of ChainLock, so it must not be called while holding it.
+func (s *Server) SetWallet(w *wallet.Wallet) {
+	s.cfg.ChainLock.Lock()
+	defer s.cfg.ChainLock.Unlock()
+	s.cfg.Wallet = w
+}
+
+// Credentials returns the user name and password clients must present.
+func (s *Server) Credentials() (user, password string) {
+	return s.user, s.password
+}
+
+// ListenAndServe writes the cookie file and serves requests on addr, which
+// must be a loopback address. It blocks until the server is closed.
+func (s *Server) ListenAndServe(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return fmt.Errorf("rpc: refusing to listen on non-loopback address %s", addr)
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	if s.cfg.CookiePath != "" {
+		if err := WriteCookie(s.cfg.CookiePath, s.user, s.password); err != nil {
+			listener.Close()
+			return err
+		}
+	}
+
+	s.http = &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	err = s.http.Serve(listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Close stops the server and removes the cookie file.
+func (s *Server) Close() error {
+	if s.cfg.CookiePath != "" {
+		os.Remove(s.cfg.CookiePath)
+	}
+	if s.http == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.http.Shutdown(ctx)
+}
+
+// ServeHTTP authenticates the request and handles a single or batch call.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "JSON-RPC requests must use POST", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="gochain"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, "request too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	var result interface{}
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(trimmed, &batch); err != nil {
+			result = errorResponse(nil, CodeParseError, "parse error")
+		} else if len(batch) == 0 {
+			result = errorResponse(nil, CodeInvalidRequest, "empty batch")
+		} else {
+			var responses []*Response
+			for _, raw := range batch {
+				if resp := s.handle(raw); resp != nil {
+					responses = append(responses, resp)
+				}
+			}
+			if len(responses) == 0 {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			result = responses
+		}
+	} else {
+		resp := s.handle(trimmed)
+		if resp == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		result = resp
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	user, password, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+	userOK := subtle.ConstantTimeCompare([]byte(user), []byte(s.user)) == 1
+	passwordOK := subtle.ConstantTimeCompare([]byte(password), []byte(s.password)) == 1
+	return userOK && passwordOK
+}
+
+// handle runs one request and returns its response, or nil for notifications.
+func (s *Server) handle(raw []byte) *Response {
+	var req Request
+	if err := json.Unmarshal(raw, &req); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return errorResponse(nil, CodeParseError, "parse error")
+		}
+		return errorResponse(nil, CodeInvalidRequest, "invalid request")
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return errorResponse(req.ID, CodeInvalidRequest, "invalid request")
+	}
+
+	result, rpcErr := s.call(req.Method, req.Params)
+	if len(req.ID) == 0 {
+		return nil
+	}
+	if rpcErr != nil {
+		return &Response{JSONRPC: "2.0", Error: rpcErr, ID: req.ID}
+	}
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return errorResponse(req.ID, CodeInternalError, err.Error())
+	}
+	return &Response{JSONRPC: "2.0", Result: encoded, ID: req.ID}
+}
+
+func (s *Server) call(name string, rawParams json.RawMessage) (interface{}, *Error) {
+	m, ok := s.methods[name]
+	if !ok {
+		return nil, &Error{Code: CodeMethodNotFound, Message: "method not found: " + name}
+	}
+	params, err := positionalParams(rawParams, m.params)
+	if err != nil {
+		return nil, invalidParams(err.Error())
+	}
+
+	if m.write {
+		s.cfg.ChainLock.Lock()
+		defer s.cfg.ChainLock.Unlock()
+	} else {
+		s.cfg.ChainLock.RLock()
+		defer s.cfg.ChainLock.RUnlock()
+	}
+	return m.handler(params)
+}
+
+// positionalParams converts by-position or by-name params into a slice
+// ordered like names.
+func positionalParams(raw json.RawMessage, names []string) ([]json.RawMessage, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil, nil
+	}
+
+	switch raw[0] {
+	case '[':
+		var params []json.RawMessage
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, err
+		}
+		if len(params) > len(names) {
+			return nil, fmt.Errorf("expected at most %d params, got %d", len(names), len(params))
+		}
+		return params, nil
+	case '{':
+		var named map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &named); err != nil {
+			return nil, err
+		}
+		params := make([]json.RawMessage, len(names))
+		last := 0
+		for i, name := range names {
+			if value, ok := named[name]; ok {
+				params[i] = value
+				last = i + 1
+				delete(named, name)
+			}
+		}
+		for name := range named {
+			return nil, fmt.Errorf("unknown param %q", name)
+		}
+		return params[:last], nil
+	default:
+		return nil, errors.New("params must be an array or object")
+	}
+}
+
+func errorResponse(id json.RawMessage, code int, message string) *Response {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &Response{JSONRPC: "2.0", Error: &Error{Code: code, Message: message}, ID: id}
+}
+
+func invalidParams(message string) *Error {
+	return &Error{Code: CodeInvalidParams, Message: message}
+}
@@ -0,0 +1,339 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/NicholasRodrigues/go-chain/internal/blockchain"
+	"github.com/NicholasRodrigues/go-chain/internal/mempool"
+	"github.com/NicholasRodrigues/go-chain/internal/schema"
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+	"github.com/NicholasRodrigues/go-chain/internal/wallet"
+	"github.com/NicholasRodrigues/go-chain/pkg/address"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testNode struct {
+	chain  *blockchain.Blockchain
+	pool   *mempool.Mempool
+	wallet *wallet.Wallet
+	server *Server
+	http   *httptest.Server
+}
+
+// newTestNode serves a chain with one mined block paying the node's wallet.
+// Coinbase outputs are spendable right away.
+func newTestNode(t *testing.T) *testNode {
+	params := blockchain.MainNetParams
+	params.CoinbaseMaturity = 0
+	chain := blockchain.NewBlockchainWithParams(&params)
+	pool := mempool.New(chain)
+	w := wallet.New(chain, pool, &address.MainNetParams)
+	addr, err := w.NewAddress()
+	require.NoError(t, err)
+	coinbase := transactions.NewCoinbaseTransaction(transactions.PayToAddrScript(addr), "height 1", 50)
+	chain.AddBlock([]*transactions.Transaction{coinbase})
+
+	server, err := New(Config{Chain: chain, Pool: pool, Wallet: w})
+	require.NoError(t, err)
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	return &testNode{chain: chain, pool: pool, wallet: w, server: server, http: httpServer}
+}
+
+func (n *testNode) post(t *testing.T, body string) *http.Response {
+	req, err := http.NewRequest(http.MethodPost, n.http.URL, strings.NewReader(body))
+	require.NoError(t, err)
+	req.SetBasicAuth(n.server.Credentials())
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// call invokes method and decodes its result into result, returning the RPC error.
+func (n *testNode) call(t *testing.T, method string, params interface{}, result interface{}) *Error {
+	body, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params, "id": 1})
+	require.NoError(t, err)
+	resp := n.post(t, string(body))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var decoded Response
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+	assert.Equal(t, "1", string(decoded.ID))
+	if decoded.Error != nil {
+		return decoded.Error
+	}
+	if result != nil {
+		require.NoError(t, json.Unmarshal(decoded.Result, result))
+	}
+	return nil
+}
+
+func TestAuthentication(t *testing.T) {
+	node := newTestNode(t)
+	body := `{"jsonrpc":"2.0","method":"getblockcount","id":1}`
+
+	resp, err := http.Post(node.http.URL, "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	req, _ := http.NewRequest(http.MethodPost, node.http.URL, strings.NewReader(body))
+	req.SetBasicAuth(CookieUser, "wrong")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp, err = http.Get(node.http.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestChainQueries(t *testing.T) {
+	node := newTestNode(t)
+
+	var count int
+	require.Nil(t, node.call(t, "getblockcount", nil, &count))
+	assert.Equal(t, 1, count)
+
+	var best, hash string
+	require.Nil(t, node.call(t, "getbestblockhash", nil, &best))
+	require.Nil(t, node.call(t, "getblockhash", []int{1}, &hash))
+	assert.Equal(t, hex.EncodeToString(node.chain.Tip().Hash), best)
+	assert.Equal(t, best, hash)
+
+	rpcErr := node.call(t, "getblockhash", []int{5}, nil)
+	require.NotNil(t, rpcErr)
+	assert.Equal(t, CodeNotFound, rpcErr.Code)
+
+	var block struct {
+		schema.Block
+		Tx []string `json:"tx"`
+	}
+	require.Nil(t, node.call(t, "getblock", map[string]interface{}{"blockhash": best}, &block))
+	assert.Equal(t, 1, block.Height)
+	assert.Equal(t, 1, block.Confirmations)
+	assert.Equal(t, hex.EncodeToString(node.chain.Blocks[0].Hash), block.PrevHash)
+	require.Len(t, block.Tx, 1)
+
+	var full struct {
+		Tx []schema.Transaction `json:"tx"`
+	}
+	require.Nil(t, node.call(t, "getblock", []interface{}{best, 2}, &full))
+	require.Len(t, full.Tx, 1)
+	assert.Equal(t, node.wallet.Addresses()[0].String(), full.Tx[0].Vout[0].Address)
+
+	var raw string
+	require.Nil(t, node.call(t, "getblock", []interface{}{best, 0}, &raw))
+	encoded, err := hex.DecodeString(raw)
+	require.NoError(t, err)
+	decoded, err := blockchain.DecodeBlock(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, node.chain.Tip().Hash, decoded.Hash)
+
+	var tx schema.Transaction
+	require.Nil(t, node.call(t, "gettransaction", []string{block.Tx[0]}, &tx))
+	assert.Equal(t, best, tx.BlockHash)
+	assert.Equal(t, 1, tx.Confirmations)
+	assert.Equal(t, "height 1", tx.Vin[0].Coinbase)
+
+	var valid bool
+	require.Nil(t, node.call(t, "validatechain", nil, &valid))
+	assert.True(t, valid)
+}
+
+func TestSendRawTransaction(t *testing.T) {
+	node := newTestNode(t)
+	to, err := address.Decode(node.wallet.Addresses()[0].String(), &address.MainNetParams)
+	require.NoError(t, err)
+	tx, err := node.wallet.CreatePayment(to, 20)
+	require.NoError(t, err)
+	rawTx := hex.EncodeToString(tx.Serialize())
+
+	var txid string
+	require.Nil(t, node.call(t, "sendrawtransaction", []string{rawTx}, &txid))
+	assert.Equal(t, hex.EncodeToString(tx.ID), txid)
+
+	rpcErr := node.call(t, "sendrawtransaction", []string{rawTx}, nil)
+	require.NotNil(t, rpcErr)
+	assert.Equal(t, CodeVerifyDuplicate, rpcErr.Code)
+
+	rpcErr = node.call(t, "sendrawtransaction", []string{"00ff"}, nil)
+	require.NotNil(t, rpcErr)
+	assert.Equal(t, CodeInvalidParams, rpcErr.Code)
+
+	var info MempoolInfoResult
+	require.Nil(t, node.call(t, "getmempoolinfo", nil, &info))
+	assert.Equal(t, 1, info.Size)
+	assert.Equal(t, len(tx.Serialize()), info.Bytes)
+
+	var pending schema.Transaction
+	require.Nil(t, node.call(t, "gettransaction", []string{txid}, &pending))
+	assert.Equal(t, 0, pending.Confirmations)
+	assert.Empty(t, pending.BlockHash)
+
+	var balance BalanceResult
+	require.Nil(t, node.call(t, "getbalance", nil, &balance))
+	// The payment goes back to the wallet, so both outputs are unconfirmed coins.
+	assert.Equal(t, tx.Vout[0].Value+tx.Vout[1].Value, balance.Unconfirmed)
+}
+
+func TestProtocolErrors(t *testing.T) {
+	node := newTestNode(t)
+
+	rpcErr := node.call(t, "nosuchmethod", nil, nil)
+	require.NotNil(t, rpcErr)
+	assert.Equal(t, CodeMethodNotFound, rpcErr.Code)
+
+	rpcErr = node.call(t, "getblockhash", []string{"one"}, nil)
+	require.NotNil(t, rpcErr)
+	assert.Equal(t, CodeInvalidParams, rpcErr.Code)
+
+	rpcErr = node.call(t, "getblock", map[string]string{"hash": "00"}, nil)
+	require.NotNil(t, rpcErr)
+	assert.Equal(t, CodeInvalidParams, rpcErr.Code)
+
+	for body, code := range map[string]int{
+		`{"jsonrpc":`: CodeParseError,
+		`{"jsonrpc":"1.0","method":"getblockcount","id":1}`: CodeInvalidRequest,
+		`[]`: CodeInvalidRequest,
+	} {
+		var resp Response
+		require.NoError(t, json.NewDecoder(node.post(t, body).Body).Decode(&resp))
+		require.NotNil(t, resp.Error, body)
+		assert.Equal(t, code, resp.Error.Code, body)
+	}
+
+	// Notifications get no response.
+	resp := node.post(t, `{"jsonrpc":"2.0","method":"getblockcount"}`)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	var batch []Response
+	resp = node.post(t, `[{"jsonrpc":"2.0","method":"getblockcount","id":1},{"jsonrpc":"2.0","method":"getblockcount"},{"jsonrpc":"2.0","method":"nope","id":"b"}]`)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&batch))
+	require.Len(t, batch, 2)
+	assert.Equal(t, "1", string(bytes.TrimSpace(batch[0].Result)))
+	assert.Equal(t, CodeMethodNotFound, batch[1].Error.Code)
+}
+
+func TestWalletRequired(t *testing.T) {
+	server, err := New(Config{Chain: blockchain.NewBlockchain()})
+	require.NoError(t, err)
+	_, rpcErr := server.call("getbalance", nil)
+	require.NotNil(t, rpcErr)
+	assert.Equal(t, CodeWalletNotFound, rpcErr.Code)
+}
+
+func TestRegTestMethods(t *testing.T) {
+	node := newTestNode(t)
+	rpcErr := node.call(t, "generate", []int{1}, nil)
+	require.NotNil(t, rpcErr)
+	assert.Equal(t, CodeMethodNotFound, rpcErr.Code, "generate is refused on mainnet")
+
+	chain := blockchain.NewBlockchainWithParams(&blockchain.RegTestParams)
+	pool := mempool.New(chain)
+	w := wallet.New(chain, pool, &address.RegTestParams)
+	server, err := New(Config{Chain: chain, Pool: pool, Wallet: w, Params: &address.RegTestParams})
+	require.NoError(t, err)
+
+	_, rpcErr = server.call("generate", json.RawMessage(`[1]`))
+	require.NotNil(t, rpcErr)
+	assert.Equal(t, CodeWalletNotFound, rpcErr.Code, "the wallet has no address")
+	addr, err := w.NewAddress()
+	require.NoError(t, err)
+
+	_, rpcErr = server.call("setmocktime", json.RawMessage(`[2000000000]`))
+	require.Nil(t, rpcErr)
+	result, rpcErr := server.call("generate", json.RawMessage(`[2]`))
+	require.Nil(t, rpcErr)
+	assert.Len(t, result, 2)
+	assert.Equal(t, 2, chain.Height())
+	// Blocks mined in the same mock second stay after the median time past.
+	assert.Equal(t, int64(2000000000), chain.Blocks[1].Timestamp)
+	assert.Equal(t, int64(2000000001), chain.Tip().Timestamp)
+	// The coinbases stay immature until CoinbaseMaturity blocks are mined on top.
+	balance, rpcErr := server.call("getbalance", nil)
+	require.Nil(t, rpcErr)
+	assert.Equal(t, BalanceResult{Immature: 100}, balance)
+
+	other, err := wallet.New(chain, pool, &address.RegTestParams).NewAddress()
+	require.NoError(t, err)
+	_, rpcErr = server.call("generate", json.RawMessage(`{"nblocks": 1, "address": "`+other.String()+`"}`))
+	require.Nil(t, rpcErr)
+	assert.Equal(t, transactions.PayToAddrScript(other), chain.Tip().Transactions[0].Vout[0].ScriptPubKey)
+	assert.NotEqual(t, addr.String(), other.String())
+
+	for _, params := range []string{`[0]`, `[1001]`, `[1, "gc1qnotregtest"]`} {
+		_, rpcErr = server.call("generate", json.RawMessage(params))
+		require.NotNil(t, rpcErr, params)
+		assert.Equal(t, CodeInvalidParams, rpcErr.Code, params)
+	}
+	assert.True(t, blockchain.ChainValidationPredicate(chain))
+}
+
+func TestInvalidateBlock(t *testing.T) {
+	node := newTestNode(t)
+	rpcErr := node.call(t, "invalidateblock", []string{"00"}, nil)
+	require.NotNil(t, rpcErr)
+	assert.Equal(t, CodeMethodNotFound, rpcErr.Code, "invalidateblock is refused on mainnet")
+
+	params := blockchain.RegTestParams
+	params.CoinbaseMaturity = 0
+	chain := blockchain.NewBlockchainWithParams(&params)
+	pool := mempool.New(chain)
+	w := wallet.New(chain, pool, &address.RegTestParams)
+	server, err := New(Config{Chain: chain, Pool: pool, Wallet: w, Params: &address.RegTestParams})
+	require.NoError(t, err)
+	addr, err := w.NewAddress()
+	require.NoError(t, err)
+
+	_, rpcErr = server.call("generate", json.RawMessage(`[1]`))
+	require.Nil(t, rpcErr)
+	tx, err := w.Send(addr, 10)
+	require.NoError(t, err)
+	_, rpcErr = server.call("generate", json.RawMessage(`[2]`))
+	require.Nil(t, rpcErr)
+	assert.Equal(t, 0, pool.Count())
+
+	// Invalidating the block confirming tx disconnects it and the block on
+	// top, returning tx to the mempool.
+	hash := hex.EncodeToString(chain.Blocks[2].Hash)
+	_, rpcErr = server.call("invalidateblock", json.RawMessage(`["`+hash+`"]`))
+	require.Nil(t, rpcErr)
+	assert.Equal(t, 1, chain.Height())
+	_, pending := pool.Get(tx.ID)
+	assert.True(t, pending)
+	assert.True(t, blockchain.ChainValidationPredicate(chain))
+
+	_, rpcErr = server.call("invalidateblock", json.RawMessage(`["`+hash+`"]`))
+	require.NotNil(t, rpcErr)
+	assert.Equal(t, CodeNotFound, rpcErr.Code)
+	_, rpcErr = server.call("invalidateblock", json.RawMessage(`["`+hex.EncodeToString(chain.Blocks[0].Hash)+`"]`))
+	require.NotNil(t, rpcErr)
+	assert.Equal(t, CodeInvalidParams, rpcErr.Code, "the genesis block cannot be invalidated")
+	assert.Equal(t, 1, chain.Height())
+}
+
+func TestCookieFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "node", ".cookie")
+	require.NoError(t, WriteCookie(path, CookieUser, "secret"))
+	user, password, err := ReadCookie(path)
+	require.NoError(t, err)
+	assert.Equal(t, CookieUser, user)
+	assert.Equal(t, "secret", password)
+
+	server, err := New(Config{Chain: blockchain.NewBlockchain(), CookiePath: path})
+	require.NoError(t, err)
+	assert.Error(t, server.ListenAndServe("0.0.0.0:0"))
+}
@@ -0,0 +1,14 @@
+package rpc
+
+// BalanceResult is the result of getbalance.
+type BalanceResult struct {
+	Confirmed   int `json:"confirmed"`
+	Immature    int `json:"immature"`
+	Unconfirmed int `json:"unconfirmed"`
+}
+
+// MempoolInfoResult is the result of getmempoolinfo.
+type MempoolInfoResult struct {
+	Size  int `json:"size"`
+	Bytes int `json:"bytes"`
+}
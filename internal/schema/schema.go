@@ -0,0 +1,214 @@
+// Package schema defines the JSON representations of chain data shared by the
+// RPC and REST interfaces. Hashes and binary fields are hex encoded and every
+// field is named, so the encodings stay stable as the Go types evolve.
+package schema
+
+import (
+	"encoding/hex"
+
+	"github.com/NicholasRodrigues/go-chain/internal/blockchain"
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+	"github.com/NicholasRodrigues/go-chain/pkg/address"
+)
+
+// Block is the JSON form of a block.
+type Block struct {
+	Hash          string `json:"hash"`
+	Height        int    `json:"height"`
+	Confirmations int    `json:"confirmations"`
+	PrevHash      string `json:"previousblockhash,omitempty"`
+	NextHash      string `json:"nextblockhash,omitempty"`
+	Timestamp     int64  `json:"time"`
+	Nonce         int    `json:"nonce"`
+	// Tx holds transaction ids, or Transaction values when full
+	// transactions are requested.
+	Tx interface{} `json:"tx"`
+}
+
+// Transaction is the JSON form of a transaction. Block fields are only set
+// for confirmed transactions.
+type Transaction struct {
+	Txid          string   `json:"txid"`
+	Hex           string   `json:"hex"`
+	LockTime      int64    `json:"locktime"`
+	Vin           []Input  `json:"vin"`
+	Vout          []Output `json:"vout"`
+	BlockHash     string   `json:"blockhash,omitempty"`
+	Height        *int     `json:"height,omitempty"`
+	Confirmations int      `json:"confirmations"`
+}
+
+// Input is the JSON form of a transaction input.
+type Input struct {
+	Coinbase  string   `json:"coinbase,omitempty"`
+	Txid      string   `json:"txid,omitempty"`
+	Vout      int      `json:"vout"`
+	ScriptSig string   `json:"scriptSig,omitempty"`
+	Signature string   `json:"signature,omitempty"`
+	PubKey    string   `json:"pubkey,omitempty"`
+	Witness   []string `json:"witness,omitempty"`
+	Sequence  uint32   `json:"sequence"`
+}
+
+// Output is the JSON form of a transaction output.
+type Output struct {
+	N            int    `json:"n"`
+	Value        int    `json:"value"`
+	ScriptPubKey string `json:"scriptPubKey"`
+	Address      string `json:"address,omitempty"`
+}
+
+// UTXO is the JSON form of an unspent output.
+type UTXO struct {
+	Txid          string `json:"txid"`
+	Vout          int    `json:"vout"`
+	Value         int    `json:"value"`
+	ScriptPubKey  string `json:"scriptPubKey"`
+	Height        int    `json:"height"`
+	Confirmations int    `json:"confirmations"`
+	Coinbase      bool   `json:"coinbase"`
+}
+
+// Tip describes the last block of the chain.
+type Tip struct {
+	Hash           string `json:"hash"`
+	Height         int    `json:"height"`
+	Timestamp      int64  `json:"time"`
+	MedianTimePast int64  `json:"mediantime"`
+}
+
+// NewBlock describes the block at height of chain. With fullTx set the
+// transactions are included in full, otherwise only their ids.
+func NewBlock(chain *blockchain.Blockchain, block *blockchain.Block, height int, fullTx bool, params *address.Params) *Block {
+	result := &Block{
+		Hash:          hex.EncodeToString(block.Hash),
+		Height:        height,
+		Confirmations: chain.Height() - height + 1,
+		PrevHash:      hex.EncodeToString(block.PrevBlockHash),
+		Timestamp:     block.Timestamp,
+		Nonce:         block.Counter,
+	}
+	if next, ok := chain.BlockAt(height + 1); ok {
+		result.NextHash = hex.EncodeToString(next.Hash)
+	}
+
+	if fullTx {
+		txs := make([]*Transaction, 0, len(block.Transactions))
+		for _, tx := range block.Transactions {
+			txResult := NewTransaction(tx, params)
+			txResult.SetBlock(chain, block, height)
+			txs = append(txs, txResult)
+		}
+		result.Tx = txs
+	} else {
+		ids := make([]string, 0, len(block.Transactions))
+		for _, tx := range block.Transactions {
+			ids = append(ids, hex.EncodeToString(tx.ID))
+		}
+		result.Tx = ids
+	}
+	return result
+}
+
+// NewTransaction describes tx, rendering output addresses for params.
+func NewTransaction(tx *transactions.Transaction, params *address.Params) *Transaction {
+	result := &Transaction{
+		Txid:     hex.EncodeToString(tx.ID),
+		Hex:      hex.EncodeToString(tx.Serialize()),
+		LockTime: tx.LockTime,
+		Vin:      make([]Input, 0, len(tx.Vin)),
+		Vout:     make([]Output, 0, len(tx.Vout)),
+	}
+
+	for _, in := range tx.Vin {
+		if tx.IsCoinbase() {
+			result.Vin = append(result.Vin, Input{Coinbase: in.ScriptSig, Vout: in.Vout, Sequence: in.Sequence})
+			continue
+		}
+		input := Input{
+			Txid:      hex.EncodeToString(in.Txid),
+			Vout:      in.Vout,
+			ScriptSig: in.ScriptSig,
+			Signature: hex.EncodeToString(in.Signature),
+			PubKey:    hex.EncodeToString(in.PubKey),
+			Sequence:  in.Sequence,
+		}
+		for _, item := range in.Witness {
+			input.Witness = append(input.Witness, hex.EncodeToString(item))
+		}
+		result.Vin = append(result.Vin, input)
+	}
+
+	for i, out := range tx.Vout {
+		output := Output{N: i, Value: out.Value, ScriptPubKey: out.ScriptPubKey}
+		if addr, err := transactions.ExtractAddress(out.ScriptPubKey, params); err == nil {
+			output.Address = addr.String()
+		}
+		result.Vout = append(result.Vout, output)
+	}
+	return result
+}
+
+// SetBlock records that the transaction is confirmed by block at height.
+func (t *Transaction) SetBlock(chain *blockchain.Blockchain, block *blockchain.Block, height int) {
+	t.BlockHash = hex.EncodeToString(block.Hash)
+	t.Height = &height
+	t.Confirmations = chain.Height() - height + 1
+}
+
+// NewUTXO describes an unspent output of chain.
+func NewUTXO(chain *blockchain.Blockchain, entry blockchain.UTXOEntry) UTXO {
+	return UTXO{
+		Txid:          hex.EncodeToString(entry.Txid),
+		Vout:          entry.Vout,
+		Value:         entry.Output.Value,
+		ScriptPubKey:  entry.Output.ScriptPubKey,
+		Height:        entry.Height,
+		Confirmations: chain.Height() - entry.Height + 1,
+		Coinbase:      entry.IsCoinbase,
+	}
+}
+
+// NewTip describes the last block of chain.
+func NewTip(chain *blockchain.Blockchain) Tip {
+	tip := chain.Tip()
+	return Tip{
+		Hash:           hex.EncodeToString(tip.Hash),
+		Height:         chain.Height(),
+		Timestamp:      tip.Timestamp,
+		MedianTimePast: chain.MedianTimePast(chain.Height()),
+	}
+}
+
+// Event is the JSON form of a chain or mempool event. Block events carry the
+// block with its transaction ids; confirmations are not reported since they
+// change as the chain grows.
+type Event struct {
+	Type  string       `json:"type"`
+	Block *Block       `json:"block,omitempty"`
+	Tx    *Transaction `json:"tx,omitempty"`
+}
+
+// NewEvent describes e. It does not read the chain, so it is safe to call
+// while the chain is being modified.
+func NewEvent(e blockchain.Event, params *address.Params) *Event {
+	result := &Event{Type: e.Type.String()}
+	if e.Block != nil {
+		ids := make([]string, 0, len(e.Block.Transactions))
+		for _, tx := range e.Block.Transactions {
+			ids = append(ids, hex.EncodeToString(tx.ID))
+		}
+		result.Block = &Block{
+			Hash:      hex.EncodeToString(e.Block.Hash),
+			Height:    e.Height,
+			PrevHash:  hex.EncodeToString(e.Block.PrevBlockHash),
+			Timestamp: e.Block.Timestamp,
+			Nonce:     e.Block.Counter,
+			Tx:        ids,
+		}
+	}
+	if e.Tx != nil {
+		result.Tx = NewTransaction(e.Tx, params)
+	}
+	return result
+}
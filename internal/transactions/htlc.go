@@ -0,0 +1,121 @@
+package transactions
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/NicholasRodrigues/go-chain/pkg/address"
+)
+
+const (
+	opIf                  = "OP_IF"
+	opElse                = "OP_ELSE"
+	opEndIf               = "OP_ENDIF"
+	opDrop                = "OP_DROP"
+	opCheckLockTimeVerify = "OP_CHECKLOCKTIMEVERIFY"
+)
+
+// SecretLen is the required length of an HTLC preimage.
+const SecretLen = 32
+
+// HTLC is a hash time-locked contract. The recipient can claim the output by
+// revealing the preimage of SecretHash; once LockTime has passed the sender
+// can take it back instead.
+type HTLC struct {
+	SecretHash   []byte
+	RecipientPKH []byte
+	RefundPKH    []byte
+	LockTime     int64
+}
+
+// NewHTLCScript returns the script for the given contract.
+func NewHTLCScript(htlc *HTLC) (string, error) {
+	for _, hash := range [][]byte{htlc.SecretHash, htlc.RecipientPKH, htlc.RefundPKH} {
+		if len(hash) != address.HashLen {
+			return "", errors.New("htlc hashes must be 32 bytes")
+		}
+	}
+	if htlc.LockTime <= 0 {
+		return "", errors.New("htlc lock time must be positive")
+	}
+
+	return strings.Join([]string{
+		opIf,
+		opSHA256, hex.EncodeToString(htlc.SecretHash), opEqualVerify,
+		opDup, opSHA256, hex.EncodeToString(htlc.RecipientPKH),
+		opElse,
+		strconv.FormatInt(htlc.LockTime, 10), opCheckLockTimeVerify, opDrop,
+		opDup, opSHA256, hex.EncodeToString(htlc.RefundPKH),
+		opEndIf,
+		opEqualVerify, opCheckSig,
+	}, " "), nil
+}
+
+// ParseHTLCScript parses a script created by NewHTLCScript.
+func ParseHTLCScript(script string) (*HTLC, bool) {
+	tokens := strings.Fields(script)
+	template := []string{
+		opIf, opSHA256, "", opEqualVerify, opDup, opSHA256, "", opElse,
+		"", opCheckLockTimeVerify, opDrop, opDup, opSHA256, "", opEndIf,
+		opEqualVerify, opCheckSig,
+	}
+	if len(tokens) != len(template) {
+		return nil, false
+	}
+	for i, op := range template {
+		if op != "" && tokens[i] != op {
+			return nil, false
+		}
+	}
+
+	secretHash, ok1 := parseHash(tokens[2])
+	recipient, ok2 := parseHash(tokens[6])
+	refund, ok3 := parseHash(tokens[13])
+	lockTime, err := strconv.ParseInt(tokens[8], 10, 64)
+	if !ok1 || !ok2 || !ok3 || err != nil || lockTime <= 0 {
+		return nil, false
+	}
+
+	return &HTLC{SecretHash: secretHash, RecipientPKH: recipient, RefundPKH: refund, LockTime: lockTime}, true
+}
+
+// HashSecret returns the hash an HTLC locks a preimage with.
+func HashSecret(secret []byte) []byte {
+	hash := sha256.Sum256(secret)
+	return hash[:]
+}
+
+// verifyHTLC checks a claim or refund of an HTLC output. A claim carries the
+// preimage as its only witness item; a refund carries no witness and must set
+// a lock time at or past the contract's.
+func (tx *Transaction) verifyHTLC(idx int, prevOut TransactionOutput, htlc *HTLC, flags ScriptFlags) error {
+	in := tx.Vin[idx]
+
+	var owner []byte
+	switch len(in.Witness) {
+	case 1:
+		if !bytes.Equal(HashSecret(in.Witness[0]), htlc.SecretHash) {
+			return errors.New("preimage does not match secret hash")
+		}
+		owner = htlc.RecipientPKH
+	case 0:
+		if (tx.LockTime < LockTimeThreshold) != (htlc.LockTime < LockTimeThreshold) {
+			return errors.New("lock time type does not match contract")
+		}
+		if tx.LockTime < htlc.LockTime {
+			return errors.New("contract lock time has not been reached")
+		}
+		if in.Sequence == SequenceFinal {
+			return errors.New("refund input must not be final")
+		}
+		owner = htlc.RefundPKH
+	default:
+		return errors.New("unexpected witness for htlc spend")
+	}
+
+	return tx.checkKeyHashSignature(idx, prevOut, owner, in.PubKey, in.Signature, flags)
+}
@@ -0,0 +1,84 @@
+package transactions
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestHTLCScriptRoundTrip(t *testing.T) {
+	htlc := &HTLC{
+		SecretHash:   HashSecret([]byte("secret")),
+		RecipientPKH: HashPubKey([]byte("recipient")),
+		RefundPKH:    HashPubKey([]byte("refund")),
+		LockTime:     144,
+	}
+
+	script, err := NewHTLCScript(htlc)
+	if err != nil {
+		t.Fatalf("Failed to create htlc script: %v", err)
+	}
+
+	parsed, ok := ParseHTLCScript(script)
+	if !ok {
+		t.Fatalf("Failed to parse htlc script")
+	}
+	if !bytes.Equal(parsed.SecretHash, htlc.SecretHash) || !bytes.Equal(parsed.RecipientPKH, htlc.RecipientPKH) ||
+		!bytes.Equal(parsed.RefundPKH, htlc.RefundPKH) || parsed.LockTime != htlc.LockTime {
+		t.Errorf("Expected parsed htlc to match the original")
+	}
+}
+
+func TestNewHTLCScript_Invalid(t *testing.T) {
+	valid := HashSecret([]byte("secret"))
+
+	if _, err := NewHTLCScript(&HTLC{SecretHash: []byte("short"), RecipientPKH: valid, RefundPKH: valid, LockTime: 1}); err == nil {
+		t.Errorf("Expected short secret hash to fail")
+	}
+	if _, err := NewHTLCScript(&HTLC{SecretHash: valid, RecipientPKH: valid, RefundPKH: valid}); err == nil {
+		t.Errorf("Expected missing lock time to fail")
+	}
+}
+
+func TestValidate_HTLCClaimAndRefund(t *testing.T) {
+	recipient, sender := newTestKey(t), newTestKey(t)
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	script, _ := NewHTLCScript(&HTLC{
+		SecretHash:   HashSecret(secret),
+		RecipientPKH: HashPubKey(recipient.PublicKey().Bytes()),
+		RefundPKH:    HashPubKey(sender.PublicKey().Bytes()),
+		LockTime:     50,
+	})
+	prevOut := TransactionOutput{Value: 10, ScriptPubKey: script}
+	utxoSet := map[string]TransactionOutput{UTXOKey([]byte("htlc"), 0): prevOut}
+
+	claim := NewTransaction(
+		[]TransactionInput{{Txid: []byte("htlc"), Vout: 0, Witness: [][]byte{secret}}},
+		[]TransactionOutput{{Value: 10, ScriptPubKey: "recipient"}},
+	)
+	if err := claim.SignInput(0, recipient, prevOut); err != nil {
+		t.Fatalf("Failed to sign claim: %v", err)
+	}
+	if !claim.Validate(utxoSet) {
+		t.Errorf("Expected claim with preimage to be valid")
+	}
+
+	refund := &Transaction{
+		Vin:      []TransactionInput{{Txid: []byte("htlc"), Vout: 0}},
+		Vout:     []TransactionOutput{{Value: 10, ScriptPubKey: "sender"}},
+		LockTime: 49,
+	}
+	if err := refund.SignInput(0, sender, prevOut); err != nil {
+		t.Fatalf("Failed to sign refund: %v", err)
+	}
+	if refund.Validate(utxoSet) {
+		t.Errorf("Expected refund before the contract lock time to be invalid")
+	}
+
+	refund.LockTime = 50
+	if err := refund.SignInput(0, sender, prevOut); err != nil {
+		t.Fatalf("Failed to sign refund: %v", err)
+	}
+	if !refund.Validate(utxoSet) {
+		t.Errorf("Expected refund at the contract lock time to be valid")
+	}
+}
@@ -0,0 +1,79 @@
+package transactions
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Consensus limits on the size of transactions.
+const (
+	MaxTransactionSize = 100000 // Serialized bytes
+	MaxInputs          = 1000
+	MaxOutputs         = 1000
+)
+
+// ErrLimitExceeded is returned for transactions exceeding a consensus limit.
+var ErrLimitExceeded = errors.New("transaction exceeds consensus limits")
+
+// ScriptSigOps returns the number of signature checks a locking script may
+// require. Pay-to-script-hash outputs count zero: their redeem script is
+// counted by the input spending them.
+func ScriptSigOps(script string) int {
+	if _, ok := ExtractScriptHash(script); ok {
+		return 0
+	}
+	if multisig, ok := ParseMultisigScript(script); ok {
+		return len(multisig.PubKeys)
+	}
+	if _, ok := ParseHTLCScript(script); ok {
+		// One signature check on each branch.
+		return 2
+	}
+	// Pay-to-pubkey-hash and non-standard scripts check one signature.
+	return 1
+}
+
+// redeemScriptSigOps returns the signature checks of the redeem script an
+// input reveals, or zero if its ScriptSig is not a redeem script.
+func redeemScriptSigOps(in TransactionInput) int {
+	if _, ok := ParseMultisigScript(in.ScriptSig); ok {
+		return ScriptSigOps(in.ScriptSig)
+	}
+	if _, ok := ParseHTLCScript(in.ScriptSig); ok {
+		return ScriptSigOps(in.ScriptSig)
+	}
+	return 0
+}
+
+// SigOpCount returns the signature operations attributed to tx: those of its
+// locking scripts and of the redeem scripts its inputs reveal. Every check is
+// counted once, either by the transaction creating a bare output or by the
+// one spending a pay-to-script-hash output, without looking up prevouts.
+func (tx *Transaction) SigOpCount() int {
+	count := 0
+	for _, out := range tx.Vout {
+		count += ScriptSigOps(out.ScriptPubKey)
+	}
+	if tx.IsCoinbase() {
+		return count
+	}
+	for _, in := range tx.Vin {
+		count += redeemScriptSigOps(in)
+	}
+	return count
+}
+
+// CheckLimits returns an error wrapping ErrLimitExceeded if tx has too many
+// inputs or outputs or is too large once serialized.
+func (tx *Transaction) CheckLimits() error {
+	if len(tx.Vin) > MaxInputs {
+		return fmt.Errorf("%w: %d inputs, at most %d allowed", ErrLimitExceeded, len(tx.Vin), MaxInputs)
+	}
+	if len(tx.Vout) > MaxOutputs {
+		return fmt.Errorf("%w: %d outputs, at most %d allowed", ErrLimitExceeded, len(tx.Vout), MaxOutputs)
+	}
+	if size := len(tx.Serialize()); size > MaxTransactionSize {
+		return fmt.Errorf("%w: %d bytes, at most %d allowed", ErrLimitExceeded, size, MaxTransactionSize)
+	}
+	return nil
+}
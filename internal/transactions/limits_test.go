@@ -0,0 +1,84 @@
+package transactions
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/NicholasRodrigues/go-chain/pkg/crypto"
+)
+
+func TestScriptSigOps(t *testing.T) {
+	var keys []crypto.Verifier
+	for i := 0; i < 3; i++ {
+		key, _ := crypto.NewPrivateKey()
+		keys = append(keys, key.PublicKey())
+	}
+	multisig, err := NewMultisigScript(2, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	htlc, err := NewHTLCScript(&HTLC{SecretHash: make([]byte, 32), RecipientPKH: make([]byte, 32), RefundPKH: make([]byte, 32), LockTime: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		name   string
+		script string
+		sigOps int
+	}{
+		{"pay to pubkey hash", PayToPubKeyHashScript(make([]byte, 32)), 1},
+		{"pay to script hash", PayToScriptHashScript(HashScript(multisig)), 0},
+		{"bare multisig", multisig, 3},
+		{"htlc", htlc, 2},
+		{"non-standard", "pubkey1", 1},
+	} {
+		if got := ScriptSigOps(test.script); got != test.sigOps {
+			t.Errorf("%s: ScriptSigOps = %d, want %d", test.name, got, test.sigOps)
+		}
+	}
+
+	// The redeem script of a pay-to-script-hash spend counts with the input.
+	tx := NewTransaction(
+		[]TransactionInput{{Txid: []byte("prev"), Vout: 0, ScriptSig: multisig}},
+		[]TransactionOutput{{Value: 1, ScriptPubKey: PayToScriptHashScript(HashScript(multisig))}, {Value: 1, ScriptPubKey: "pubkey1"}},
+	)
+	if got := tx.SigOpCount(); got != 4 {
+		t.Errorf("SigOpCount = %d, want 4", got)
+	}
+	if got := NewCoinbaseTransaction(multisig, "data", 50).SigOpCount(); got != 3 {
+		t.Errorf("coinbase SigOpCount = %d, want 3", got)
+	}
+}
+
+func TestTransactionLimits(t *testing.T) {
+	in := TransactionInput{Txid: []byte("prev"), Vout: 0}
+	out := TransactionOutput{Value: 1, ScriptPubKey: "pubkey1"}
+
+	for name, tx := range map[string]*Transaction{
+		"inputs":  {Vin: make([]TransactionInput, MaxInputs+1), Vout: []TransactionOutput{out}},
+		"outputs": {Vin: []TransactionInput{in}, Vout: make([]TransactionOutput, MaxOutputs+1)},
+		"size":    {Vin: []TransactionInput{in}, Vout: []TransactionOutput{{Value: 1, ScriptPubKey: strings.Repeat("x", MaxTransactionSize)}}},
+	} {
+		tx.SetID()
+		if err := tx.CheckLimits(); !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("too many %s: got %v, want ErrLimitExceeded", name, err)
+		}
+		if _, err := DecodeTransaction(tx.Serialize()); !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("decoding too many %s: got %v, want ErrLimitExceeded", name, err)
+		}
+		if tx.Validate(nil) {
+			t.Errorf("too many %s: expected the transaction to be invalid", name)
+		}
+	}
+
+	tx := &Transaction{Vin: make([]TransactionInput, MaxInputs), Vout: make([]TransactionOutput, MaxOutputs)}
+	tx.SetID()
+	if err := tx.CheckLimits(); err != nil {
+		t.Errorf("transaction at the limits: %v", err)
+	}
+	if _, err := DecodeTransaction(tx.Serialize()); err != nil {
+		t.Errorf("decoding a transaction at the limits: %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package transactions
+
+const (
+	// LockTimeThreshold separates block heights from Unix timestamps in
+	// Transaction.LockTime: smaller values are heights.
+	LockTimeThreshold = 500000000
+
+	// SequenceFinal marks an input as final; a transaction whose inputs are
+	// all final ignores its LockTime.
+	SequenceFinal uint32 = 0xffffffff
+
+	// SequenceLockTimeDisabled disables the relative lock of an input.
+	SequenceLockTimeDisabled uint32 = 1 << 31
+	// SequenceLockTimeIsSeconds makes the relative lock a time span in units
+	// of 512 seconds instead of a number of blocks.
+	SequenceLockTimeIsSeconds uint32 = 1 << 22
+	// SequenceLockTimeMask extracts the relative lock value.
+	SequenceLockTimeMask uint32 = 0x0000ffff
+	// SequenceLockTimeGranularity is the shift applied to time based locks.
+	SequenceLockTimeGranularity = 9
+)
+
+// IsFinal reports whether the transaction may be included in a block at
+// blockHeight whose lock time reference is blockTime. Callers pass the median
+// time past of the previous block as blockTime.
+func (tx *Transaction) IsFinal(blockHeight int, blockTime int64) bool {
+	if tx.LockTime == 0 {
+		return true
+	}
+
+	limit := int64(blockHeight)
+	if tx.LockTime >= LockTimeThreshold {
+		limit = blockTime
+	}
+	if tx.LockTime < limit {
+		return true
+	}
+
+	for _, in := range tx.Vin {
+		if in.Sequence != SequenceFinal {
+			return false
+		}
+	}
+	return true
+}
+
+// SequenceLock holds the last block height and median time past at which a
+// transaction is still locked by its inputs' relative lock times. A value of
+// -1 means no lock applies.
+type SequenceLock struct {
+	MinHeight int
+	MinTime   int64
+}
+
+// CalcSequenceLock computes the relative locks of the transaction. For every
+// input, prevHeights holds the height of the block that created the spent
+// output and prevTimes the median time past of the block before it.
+func (tx *Transaction) CalcSequenceLock(prevHeights []int, prevTimes []int64) SequenceLock {
+	lock := SequenceLock{MinHeight: -1, MinTime: -1}
+	if tx.IsCoinbase() {
+		return lock
+	}
+
+	for i, in := range tx.Vin {
+		if in.Sequence&SequenceLockTimeDisabled != 0 {
+			continue
+		}
+
+		value := int64(in.Sequence & SequenceLockTimeMask)
+		if in.Sequence&SequenceLockTimeIsSeconds != 0 {
+			minTime := prevTimes[i] + value<<SequenceLockTimeGranularity - 1
+			if minTime > lock.MinTime {
+				lock.MinTime = minTime
+			}
+		} else {
+			minHeight := prevHeights[i] + int(value) - 1
+			if minHeight > lock.MinHeight {
+				lock.MinHeight = minHeight
+			}
+		}
+	}
+	return lock
+}
+
+// Satisfied reports whether a block at blockHeight, whose previous block has
+// the given median time past, is past the lock.
+func (l SequenceLock) Satisfied(blockHeight int, medianTimePast int64) bool {
+	return l.MinHeight < blockHeight && l.MinTime < medianTimePast
+}
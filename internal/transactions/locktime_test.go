@@ -0,0 +1,86 @@
+package transactions
+
+import "testing"
+
+func lockedTransaction(lockTime int64, sequence uint32) *Transaction {
+	tx := NewTransaction(
+		[]TransactionInput{{Txid: []byte("prev"), Vout: 0, Sequence: sequence}},
+		[]TransactionOutput{{Value: 10, ScriptPubKey: "recipient"}},
+	)
+	tx.LockTime = lockTime
+	return tx
+}
+
+func TestIsFinal_Height(t *testing.T) {
+	tx := lockedTransaction(100, 0)
+
+	if tx.IsFinal(100, 0) {
+		t.Errorf("Expected transaction locked until height 100 not to be final at 100")
+	}
+	if !tx.IsFinal(101, 0) {
+		t.Errorf("Expected transaction locked until height 100 to be final at 101")
+	}
+}
+
+func TestIsFinal_Timestamp(t *testing.T) {
+	tx := lockedTransaction(LockTimeThreshold+1000, 0)
+
+	if tx.IsFinal(1000000, LockTimeThreshold+1000) {
+		t.Errorf("Expected time locked transaction not to be final before its lock time")
+	}
+	if !tx.IsFinal(0, LockTimeThreshold+1001) {
+		t.Errorf("Expected time locked transaction to be final after its lock time")
+	}
+}
+
+func TestIsFinal_FinalSequences(t *testing.T) {
+	if !lockedTransaction(100, SequenceFinal).IsFinal(1, 0) {
+		t.Errorf("Expected transaction with only final inputs to ignore its lock time")
+	}
+	if !lockedTransaction(0, 0).IsFinal(0, 0) {
+		t.Errorf("Expected transaction without lock time to be final")
+	}
+}
+
+func TestCalcSequenceLock_Blocks(t *testing.T) {
+	tx := lockedTransaction(0, 10)
+	lock := tx.CalcSequenceLock([]int{5}, []int64{0})
+
+	if lock.Satisfied(14, 0) {
+		t.Errorf("Expected 10 block relative lock on an output at height 5 to hold at 14")
+	}
+	if !lock.Satisfied(15, 0) {
+		t.Errorf("Expected 10 block relative lock on an output at height 5 to expire at 15")
+	}
+}
+
+func TestCalcSequenceLock_Seconds(t *testing.T) {
+	tx := lockedTransaction(0, SequenceLockTimeIsSeconds|2)
+	lock := tx.CalcSequenceLock([]int{5}, []int64{1000})
+
+	if lock.Satisfied(100, 1000+2*512-1) {
+		t.Errorf("Expected time based relative lock to hold before 1024 seconds")
+	}
+	if !lock.Satisfied(100, 1000+2*512) {
+		t.Errorf("Expected time based relative lock to expire after 1024 seconds")
+	}
+}
+
+func TestCalcSequenceLock_Disabled(t *testing.T) {
+	tx := lockedTransaction(0, SequenceLockTimeDisabled|100)
+	if !tx.CalcSequenceLock([]int{5}, []int64{0}).Satisfied(6, 0) {
+		t.Errorf("Expected disabled relative lock to be ignored")
+	}
+}
+
+func TestSignatureHash_CommitsToLockTime(t *testing.T) {
+	tx := lockedTransaction(100, 0)
+	before, _ := tx.SignatureHash(0, TransactionOutput{Value: 10}, SigHashAll)
+
+	tx.LockTime = 50
+	after, _ := tx.SignatureHash(0, TransactionOutput{Value: 10}, SigHashAll)
+
+	if string(before) == string(after) {
+		t.Errorf("Expected signature hash to commit to the lock time")
+	}
+}
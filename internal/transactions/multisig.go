@@ -0,0 +1,172 @@
+package transactions
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/NicholasRodrigues/go-chain/pkg/crypto"
+)
+
+// MaxMultisigKeys is the largest number of keys a multisig script may list.
+const MaxMultisigKeys = 15
+
+const opCheckMultisig = "OP_CHECKMULTISIG"
+
+// Multisig is an m-of-n locking condition.
+type Multisig struct {
+	M       int
+	PubKeys []crypto.Verifier
+}
+
+// NewMultisigScript returns a script that requires signatures from m of the
+// given public keys. It can be used directly as a locking script or as the
+// redeem script of a pay-to-script-hash output.
+func NewMultisigScript(m int, pubKeys []crypto.Verifier) (string, error) {
+	n := len(pubKeys)
+	if n == 0 || n > MaxMultisigKeys {
+		return "", fmt.Errorf("multisig requires between 1 and %d keys, got %d", MaxMultisigKeys, n)
+	}
+	if m < 1 || m > n {
+		return "", fmt.Errorf("invalid multisig threshold %d of %d", m, n)
+	}
+
+	tokens := []string{strconv.Itoa(m)}
+	for i, pubKey := range pubKeys {
+		for _, other := range pubKeys[:i] {
+			if bytes.Equal(pubKey.Bytes(), other.Bytes()) {
+				return "", errors.New("multisig keys must be distinct")
+			}
+		}
+		tokens = append(tokens, pubKey.String())
+	}
+	tokens = append(tokens, strconv.Itoa(n), opCheckMultisig)
+
+	return strings.Join(tokens, " "), nil
+}
+
+// ParseMultisigScript parses an m-of-n multisig script. Scripts listing a
+// key twice are rejected, as one signer would fill several of the m slots.
+func ParseMultisigScript(script string) (*Multisig, bool) {
+	tokens := strings.Fields(script)
+	if len(tokens) < 4 || tokens[len(tokens)-1] != opCheckMultisig {
+		return nil, false
+	}
+
+	m, err := strconv.Atoi(tokens[0])
+	if err != nil {
+		return nil, false
+	}
+	n, err := strconv.Atoi(tokens[len(tokens)-2])
+	if err != nil || n != len(tokens)-3 || n > MaxMultisigKeys || m < 1 || m > n {
+		return nil, false
+	}
+
+	multisig := &Multisig{M: m}
+	for _, token := range tokens[1 : 1+n] {
+		encoded, err := hex.DecodeString(token)
+		if err != nil {
+			return nil, false
+		}
+		pubKey, err := crypto.ParsePublicKey(encoded)
+		if err != nil {
+			return nil, false
+		}
+		for _, other := range multisig.PubKeys {
+			if bytes.Equal(pubKey.Bytes(), other.Bytes()) {
+				return nil, false
+			}
+		}
+		multisig.PubKeys = append(multisig.PubKeys, pubKey)
+	}
+	return multisig, true
+}
+
+// multisigFor returns the multisig condition guarding input idx, looking
+// through pay-to-script-hash outputs to the redeem script in ScriptSig.
+func (tx *Transaction) multisigFor(idx int, prevOut TransactionOutput) (*Multisig, error) {
+	script := prevOut.ScriptPubKey
+	if hash, ok := ExtractScriptHash(script); ok {
+		script = tx.Vin[idx].ScriptSig
+		if !bytes.Equal(HashScript(script), hash) {
+			return nil, errors.New("redeem script does not match script hash")
+		}
+	}
+
+	multisig, ok := ParseMultisigScript(script)
+	if !ok {
+		return nil, errors.New("output is not locked by a multisig script")
+	}
+	return multisig, nil
+}
+
+// SignMultisigInput adds privKey's partial signature to input i, which spends
+// the multisig output prevOut. When prevOut pays to a script hash, the input's
+// ScriptSig must already hold the redeem script. Each key holder calls this in
+// turn until the threshold is reached.
+func (tx *Transaction) SignMultisigInput(i int, privKey crypto.Signer, prevOut TransactionOutput) error {
+	if i < 0 || i >= len(tx.Vin) {
+		return fmt.Errorf("input index %d out of range", i)
+	}
+	multisig, err := tx.multisigFor(i, prevOut)
+	if err != nil {
+		return err
+	}
+
+	pubKey := privKey.Public().Bytes()
+	listed := false
+	for _, key := range multisig.PubKeys {
+		if bytes.Equal(key.Bytes(), pubKey) {
+			listed = true
+			break
+		}
+	}
+	if !listed {
+		return errors.New("key is not part of the multisig script")
+	}
+
+	in := &tx.Vin[i]
+	if len(in.Witness) >= multisig.M {
+		return errors.New("input already has enough signatures")
+	}
+	for _, sig := range in.Witness {
+		if tx.checkSignature(i, prevOut, pubKey, sig, defaultFlags) == nil {
+			return errors.New("input already signed by this key")
+		}
+	}
+
+	digest, err := tx.SignatureHash(i, prevOut, SigHashAll)
+	if err != nil {
+		return err
+	}
+	in.Witness = append(in.Witness, append(privKey.Sign(digest), byte(SigHashAll)))
+	return nil
+}
+
+// verifyMultisig checks that input idx carries exactly m valid signatures
+// made by distinct keys of the multisig script.
+func (tx *Transaction) verifyMultisig(idx int, prevOut TransactionOutput, multisig *Multisig, flags ScriptFlags) error {
+	sigs := tx.Vin[idx].Witness
+	if len(sigs) != multisig.M {
+		return fmt.Errorf("expected %d signatures, got %d", multisig.M, len(sigs))
+	}
+
+	used := make([]bool, len(multisig.PubKeys))
+Signatures:
+	for _, sig := range sigs {
+		for k, pubKey := range multisig.PubKeys {
+			if used[k] {
+				continue
+			}
+			if tx.checkSignature(idx, prevOut, pubKey.Bytes(), sig, flags) == nil {
+				used[k] = true
+				continue Signatures
+			}
+		}
+		return errors.New("signature does not match any unused multisig key")
+	}
+	return nil
+}
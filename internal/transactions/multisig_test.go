@@ -0,0 +1,154 @@
+package transactions
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/NicholasRodrigues/go-chain/pkg/crypto"
+)
+
+func multisigKeys(t *testing.T, n int) ([]*crypto.PrivateKey, []crypto.Verifier) {
+	var privKeys []*crypto.PrivateKey
+	var pubKeys []crypto.Verifier
+	for i := 0; i < n; i++ {
+		privKey := newTestKey(t)
+		privKeys = append(privKeys, privKey)
+		pubKeys = append(pubKeys, privKey.PublicKey())
+	}
+	return privKeys, pubKeys
+}
+
+func spendOutput(prevOut TransactionOutput, scriptSig string) (*Transaction, map[string]TransactionOutput) {
+	utxoSet := map[string]TransactionOutput{UTXOKey([]byte("shared"), 0): prevOut}
+	tx := NewTransaction(
+		[]TransactionInput{{Txid: []byte("shared"), Vout: 0, ScriptSig: scriptSig}},
+		[]TransactionOutput{{Value: prevOut.Value, ScriptPubKey: "recipient"}},
+	)
+	return tx, utxoSet
+}
+
+func TestMultisigScriptRoundTrip(t *testing.T) {
+	_, pubKeys := multisigKeys(t, 3)
+	script, err := NewMultisigScript(2, pubKeys)
+	if err != nil {
+		t.Fatalf("Failed to create multisig script: %v", err)
+	}
+
+	multisig, ok := ParseMultisigScript(script)
+	if !ok {
+		t.Fatalf("Failed to parse multisig script")
+	}
+	if multisig.M != 2 || len(multisig.PubKeys) != 3 {
+		t.Errorf("Expected 2-of-3 multisig, got %d-of-%d", multisig.M, len(multisig.PubKeys))
+	}
+}
+
+func TestNewMultisigScript_Invalid(t *testing.T) {
+	_, pubKeys := multisigKeys(t, 2)
+
+	if _, err := NewMultisigScript(3, pubKeys); err == nil {
+		t.Errorf("Expected threshold above key count to fail")
+	}
+	if _, err := NewMultisigScript(0, pubKeys); err == nil {
+		t.Errorf("Expected zero threshold to fail")
+	}
+	if _, err := NewMultisigScript(1, []crypto.Verifier{pubKeys[0], pubKeys[0]}); err == nil {
+		t.Errorf("Expected duplicate keys to fail")
+	}
+}
+
+func TestParseMultisigScript_RejectsDuplicateKeys(t *testing.T) {
+	privKeys, pubKeys := multisigKeys(t, 1)
+	key := pubKeys[0].String()
+	script := "2 " + key + " " + key + " 2 " + opCheckMultisig
+	if _, ok := ParseMultisigScript(script); ok {
+		t.Fatal("Expected a script listing the same key twice to be rejected")
+	}
+
+	// A single signer cannot satisfy both slots of the 2-of-2 script.
+	prevOut := TransactionOutput{Value: 10, ScriptPubKey: script}
+	tx, utxoSet := spendOutput(prevOut, "")
+	if err := tx.SignMultisigInput(0, privKeys[0], prevOut); err == nil {
+		t.Error("Expected signing a script with duplicate keys to fail")
+	}
+	if tx.Validate(utxoSet) {
+		t.Error("Expected spending a script with duplicate keys to be invalid")
+	}
+
+	// Nor can any other key signing as if the script were non-standard.
+	if err := tx.SignInput(0, newTestKey(t), prevOut); err != nil {
+		t.Fatalf("Failed to sign input: %v", err)
+	}
+	if err := tx.ValidateWithFlags(utxoSet, defaultFlags); !errors.Is(err, ErrNonStandardScript) {
+		t.Errorf("Expected a malformed multisig script to be unspendable, got %v", err)
+	}
+}
+
+func TestMultisig_BareTwoOfThree(t *testing.T) {
+	privKeys, pubKeys := multisigKeys(t, 3)
+	script, _ := NewMultisigScript(2, pubKeys)
+	prevOut := TransactionOutput{Value: 10, ScriptPubKey: script}
+	tx, utxoSet := spendOutput(prevOut, "")
+
+	if err := tx.SignMultisigInput(0, privKeys[2], prevOut); err != nil {
+		t.Fatalf("Failed to add first signature: %v", err)
+	}
+	if tx.Validate(utxoSet) {
+		t.Errorf("Expected transaction with one of two signatures to be invalid")
+	}
+
+	if err := tx.SignMultisigInput(0, privKeys[0], prevOut); err != nil {
+		t.Fatalf("Failed to add second signature: %v", err)
+	}
+	if !tx.Validate(utxoSet) {
+		t.Errorf("Expected transaction with two of two signatures to be valid")
+	}
+
+	if err := tx.SignMultisigInput(0, privKeys[1], prevOut); err == nil {
+		t.Errorf("Expected signing beyond the threshold to fail")
+	}
+}
+
+func TestMultisig_ScriptHash(t *testing.T) {
+	privKeys, pubKeys := multisigKeys(t, 3)
+	redeemScript, _ := NewMultisigScript(2, pubKeys)
+	prevOut := TransactionOutput{Value: 10, ScriptPubKey: PayToScriptHashScript(HashScript(redeemScript))}
+	tx, utxoSet := spendOutput(prevOut, redeemScript)
+
+	for _, privKey := range privKeys[:2] {
+		if err := tx.SignMultisigInput(0, privKey, prevOut); err != nil {
+			t.Fatalf("Failed to add signature: %v", err)
+		}
+	}
+	if !tx.Validate(utxoSet) {
+		t.Errorf("Expected pay-to-script-hash multisig spend to be valid")
+	}
+
+	tx.Vin[0].ScriptSig = "1 " + pubKeys[0].String() + " 1 OP_CHECKMULTISIG"
+	if tx.Validate(utxoSet) {
+		t.Errorf("Expected spend with a different redeem script to be invalid")
+	}
+}
+
+func TestMultisig_RejectsDuplicateAndForeignSigners(t *testing.T) {
+	privKeys, pubKeys := multisigKeys(t, 3)
+	script, _ := NewMultisigScript(2, pubKeys)
+	prevOut := TransactionOutput{Value: 10, ScriptPubKey: script}
+	tx, utxoSet := spendOutput(prevOut, "")
+
+	if err := tx.SignMultisigInput(0, privKeys[0], prevOut); err != nil {
+		t.Fatalf("Failed to add signature: %v", err)
+	}
+	if err := tx.SignMultisigInput(0, privKeys[0], prevOut); err == nil {
+		t.Errorf("Expected second signature from the same key to fail")
+	}
+	if err := tx.SignMultisigInput(0, newTestKey(t), prevOut); err == nil {
+		t.Errorf("Expected signature from a key outside the script to fail")
+	}
+
+	// Duplicating a valid signature must not count twice.
+	tx.Vin[0].Witness = append(tx.Vin[0].Witness, tx.Vin[0].Witness[0])
+	if tx.Validate(utxoSet) {
+		t.Errorf("Expected duplicated signature to be invalid")
+	}
+}
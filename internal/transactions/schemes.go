@@ -0,0 +1,84 @@
+package transactions
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/NicholasRodrigues/go-chain/pkg/crypto"
+)
+
+// SchemeActivations maps signature schemes to the block height from which
+// consensus enforces their signatures. New schemes are deployed as soft
+// forks: before activation, signatures under a scheme are not checked by
+// consensus, exactly as by nodes that predate it, so blocks accepted by
+// upgraded nodes are always accepted by older ones. Policy rejects such
+// signatures so they never reach the mempool before activation. The skip only
+// applies to keys committed to by the locking script, either directly or by
+// hash, so a spender cannot pick an inactive scheme to bypass a signature.
+// Each network defines its own activations in its chain parameters.
+type SchemeActivations map[crypto.Scheme]int
+
+// DefaultSchemeActivations returns activations enforcing the built-in
+// schemes from genesis.
+func DefaultSchemeActivations() SchemeActivations {
+	return SchemeActivations{
+		crypto.SchemeEd25519:   0,
+		crypto.SchemeECDSAP256: 0,
+	}
+}
+
+// Active reports whether scheme is enforced at height.
+func (a SchemeActivations) Active(scheme crypto.Scheme, height int) bool {
+	activation, ok := a[scheme]
+	return ok && height >= activation
+}
+
+// ScriptFlags control how input scripts are verified.
+type ScriptFlags struct {
+	Height      int // Height of the block that includes the transaction
+	Activations SchemeActivations
+	// Policy rejects signatures under schemes that are not active instead
+	// of treating them as valid.
+	Policy bool
+
+	cache     *SigCache
+	sighashes *sighashMemo
+}
+
+// WithCache returns a copy of f that skips signatures found in cache and
+// records newly verified ones there.
+func (f ScriptFlags) WithCache(cache *SigCache) ScriptFlags {
+	f.cache = cache
+	return f
+}
+
+// StandardFlags returns the policy flags for a transaction to be included
+// at height on a network with the given scheme activations.
+func StandardFlags(height int, activations SchemeActivations) ScriptFlags {
+	return ScriptFlags{Height: height, Activations: activations, Policy: true}
+}
+
+// ConsensusFlags returns the consensus flags for a transaction included at
+// height on a network with the given scheme activations.
+func ConsensusFlags(height int, activations SchemeActivations) ScriptFlags {
+	return ScriptFlags{Height: height, Activations: activations}
+}
+
+// defaultFlags are used when no height is known: every built-in scheme is
+// enforced and all others are rejected.
+var defaultFlags = StandardFlags(math.MaxInt32, DefaultSchemeActivations())
+
+// checkScheme decides whether the signature of pubKey must be verified.
+func (f ScriptFlags) checkScheme(pubKey []byte) (bool, error) {
+	scheme, err := crypto.SchemeOf(pubKey)
+	if err != nil {
+		return false, err
+	}
+	if f.Activations.Active(scheme, f.Height) {
+		return true, nil
+	}
+	if f.Policy {
+		return false, fmt.Errorf("signature scheme %s is not active at height %d", scheme, f.Height)
+	}
+	return false, nil
+}
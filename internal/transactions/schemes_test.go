@@ -0,0 +1,110 @@
+package transactions
+
+import (
+	"testing"
+
+	"github.com/NicholasRodrigues/go-chain/pkg/crypto"
+)
+
+func newECDSATestKey(t *testing.T) *crypto.ECDSAPrivateKey {
+	privKey, err := crypto.NewECDSAPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to create ECDSA key: %v", err)
+	}
+	return privKey
+}
+
+func TestECDSAPayToPubKeyHash(t *testing.T) {
+	privKey := newECDSATestKey(t)
+	prevOut := TransactionOutput{Value: 10, ScriptPubKey: PayToPubKeyHashScript(HashPubKey(privKey.PublicKey().Bytes()))}
+	tx, utxoSet := spendOutput(prevOut, "")
+
+	if err := tx.SignInput(0, privKey, prevOut); err != nil {
+		t.Fatalf("Failed to sign input: %v", err)
+	}
+	if !tx.Validate(utxoSet) {
+		t.Errorf("Expected ECDSA signed transaction to be valid")
+	}
+
+	tx.Vout[0].Value = 9
+	if tx.Validate(utxoSet) {
+		t.Errorf("Expected modified transaction to be invalid")
+	}
+}
+
+func TestMultisig_MixedSchemes(t *testing.T) {
+	edKey := newTestKey(t)
+	ecKey := newECDSATestKey(t)
+	script, err := NewMultisigScript(2, []crypto.Verifier{edKey.PublicKey(), ecKey.PublicKey()})
+	if err != nil {
+		t.Fatalf("Failed to create multisig script: %v", err)
+	}
+	prevOut := TransactionOutput{Value: 10, ScriptPubKey: script}
+	tx, utxoSet := spendOutput(prevOut, "")
+
+	for _, key := range []crypto.Signer{ecKey, edKey} {
+		if err := tx.SignMultisigInput(0, key, prevOut); err != nil {
+			t.Fatalf("Failed to add %s signature: %v", key.Scheme(), err)
+		}
+	}
+	if !tx.Validate(utxoSet) {
+		t.Errorf("Expected mixed scheme multisig spend to be valid")
+	}
+}
+
+func TestSchemeActivation(t *testing.T) {
+	privKey := newECDSATestKey(t)
+	prevOut := TransactionOutput{Value: 10, ScriptPubKey: PayToPubKeyHashScript(HashPubKey(privKey.PublicKey().Bytes()))}
+	tx, utxoSet := spendOutput(prevOut, "")
+	if err := tx.SignInput(0, privKey, prevOut); err != nil {
+		t.Fatalf("Failed to sign input: %v", err)
+	}
+	// Corrupt the signature so that only unchecked spends succeed.
+	tx.Vin[0].Signature[len(tx.Vin[0].Signature)/2] ^= 0xff
+
+	activations := SchemeActivations{crypto.SchemeEd25519: 0, crypto.SchemeECDSAP256: 10}
+	before := ScriptFlags{Height: 9, Activations: activations}
+	after := ScriptFlags{Height: 10, Activations: activations}
+
+	if tx.ValidateWithFlags(utxoSet, before) != nil {
+		t.Errorf("Expected consensus to leave signatures of an inactive scheme unchecked")
+	}
+	before.Policy = true
+	if tx.ValidateWithFlags(utxoSet, before) == nil {
+		t.Errorf("Expected policy to reject signatures of an inactive scheme")
+	}
+	if tx.ValidateWithFlags(utxoSet, after) == nil {
+		t.Errorf("Expected invalid signature to be rejected after activation")
+	}
+
+	// Unknown schemes are never enforced by consensus and always rejected by policy.
+	tx.Vin[0].PubKey = append([]byte{0x7f}, tx.Vin[0].PubKey[1:]...)
+	prevOut.ScriptPubKey = PayToPubKeyHashScript(HashPubKey(tx.Vin[0].PubKey))
+	utxoSet[UTXOKey(tx.Vin[0].Txid, 0)] = prevOut
+	if tx.ValidateWithFlags(utxoSet, ConsensusFlags(100, DefaultSchemeActivations())) != nil {
+		t.Errorf("Expected consensus to accept a spend under an unknown scheme")
+	}
+	if tx.Validate(utxoSet) {
+		t.Errorf("Expected policy to reject a spend under an unknown scheme")
+	}
+}
+
+func TestSchemeActivation_RequiresCommittedKey(t *testing.T) {
+	flags := ConsensusFlags(100, DefaultSchemeActivations())
+	unknownKey := []byte{0x7f, 1}
+	other := newTestKey(t)
+
+	// Scripts that commit to no key, or to another key, cannot be spent by
+	// supplying a key under an unknown scheme.
+	for _, script := range []string{
+		"coinbase",
+		PayToPubKeyHashScript(HashPubKey(other.PublicKey().Bytes())),
+	} {
+		prevOut := TransactionOutput{Value: 10, ScriptPubKey: script}
+		tx, utxoSet := spendOutput(prevOut, "")
+		tx.Vin[0].PubKey = unknownKey
+		if tx.ValidateWithFlags(utxoSet, flags) == nil {
+			t.Errorf("Expected spend of %q with an uncommitted unknown scheme key to be rejected", script)
+		}
+	}
+}
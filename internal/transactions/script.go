@@ -0,0 +1,174 @@
+package transactions
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/NicholasRodrigues/go-chain/pkg/address"
+	"github.com/NicholasRodrigues/go-chain/pkg/crypto"
+)
+
+// Locking scripts are stored in their textual form. Only the standard
+// templates below are recognised; anything else is non-standard and cannot be
+// spent.
+const (
+	opDup         = "OP_DUP"
+	opSHA256      = "OP_SHA256"
+	opEqual       = "OP_EQUAL"
+	opEqualVerify = "OP_EQUALVERIFY"
+	opCheckSig    = "OP_CHECKSIG"
+)
+
+// ErrNonStandardScript is returned for inputs spending an output whose locking
+// script matches none of the standard templates.
+var ErrNonStandardScript = errors.New("locking script is not a standard template")
+
+// PayToPubKeyHashScript returns a locking script paying to pubKeyHash.
+func PayToPubKeyHashScript(pubKeyHash []byte) string {
+	return strings.Join([]string{opDup, opSHA256, hex.EncodeToString(pubKeyHash), opEqualVerify, opCheckSig}, " ")
+}
+
+// PayToScriptHashScript returns a locking script paying to scriptHash.
+func PayToScriptHashScript(scriptHash []byte) string {
+	return strings.Join([]string{opSHA256, hex.EncodeToString(scriptHash), opEqual}, " ")
+}
+
+// PayToAddrScript returns the locking script for the given address.
+func PayToAddrScript(addr *address.Address) string {
+	if addr.Type == address.ScriptHash {
+		return PayToScriptHashScript(addr.Hash)
+	}
+	return PayToPubKeyHashScript(addr.Hash)
+}
+
+// HashScript hashes a redeem script for use in a pay-to-script-hash output.
+func HashScript(script string) []byte {
+	hash := sha256.Sum256([]byte(script))
+	return hash[:]
+}
+
+// parseHash decodes a hex encoded hash pushed by a script.
+func parseHash(token string) ([]byte, bool) {
+	hash, err := hex.DecodeString(token)
+	if err != nil || len(hash) != address.HashLen {
+		return nil, false
+	}
+	return hash, true
+}
+
+// ExtractPubKeyHash returns the key hash of a pay-to-pubkey-hash script.
+func ExtractPubKeyHash(script string) ([]byte, bool) {
+	tokens := strings.Fields(script)
+	if len(tokens) != 5 || tokens[0] != opDup || tokens[1] != opSHA256 ||
+		tokens[3] != opEqualVerify || tokens[4] != opCheckSig {
+		return nil, false
+	}
+	return parseHash(tokens[2])
+}
+
+// ExtractScriptHash returns the script hash of a pay-to-script-hash script.
+func ExtractScriptHash(script string) ([]byte, bool) {
+	tokens := strings.Fields(script)
+	if len(tokens) != 3 || tokens[0] != opSHA256 || tokens[2] != opEqual {
+		return nil, false
+	}
+	return parseHash(tokens[1])
+}
+
+// ExtractAddress returns the address a standard locking script pays to.
+func ExtractAddress(script string, params *address.Params) (*address.Address, error) {
+	if hash, ok := ExtractPubKeyHash(script); ok {
+		return address.NewPubKeyHash(hash, params)
+	}
+	if hash, ok := ExtractScriptHash(script); ok {
+		return address.NewScriptHash(hash, params)
+	}
+	return nil, fmt.Errorf("script does not pay to an address")
+}
+
+// IsLockedWithKey checks if the output can be used by the owner of the pubKeyHash
+func (out *TransactionOutput) IsLockedWithKey(pubKeyHash []byte) bool {
+	if hash, ok := ExtractPubKeyHash(out.ScriptPubKey); ok {
+		return bytes.Equal(hash, pubKeyHash)
+	}
+	return bytes.Equal([]byte(out.ScriptPubKey), pubKeyHash)
+}
+
+// checkSignature verifies a signature made by pubKey over the digest of input
+// idx. Signatures under schemes not active for flags are handled as described
+// by SchemeActivations, so pubKey must be one the locking script commits to,
+// never one chosen by the spender alone.
+func (tx *Transaction) checkSignature(idx int, prevOut TransactionOutput, pubKey, rawSig []byte, flags ScriptFlags) error {
+	enforce, err := flags.checkScheme(pubKey)
+	if err != nil || !enforce {
+		return err
+	}
+	key, err := crypto.ParsePublicKey(pubKey)
+	if err != nil {
+		return fmt.Errorf("failed to parse public key: %w", err)
+	}
+	sig, hashType, err := splitSignature(rawSig)
+	if err != nil {
+		return err
+	}
+	digest, err := flags.sighashes.signatureHash(tx, idx, prevOut, hashType)
+	if err != nil {
+		return err
+	}
+	if flags.cache.Contains(digest, pubKey, sig) {
+		return nil
+	}
+	if !key.Verify(digest, sig) {
+		return errors.New("signature is invalid")
+	}
+	flags.cache.Add(digest, pubKey, sig)
+	return nil
+}
+
+// checkKeyHashSignature verifies a signature by pubKey after checking that it
+// is the key committed to by pubKeyHash.
+func (tx *Transaction) checkKeyHashSignature(idx int, prevOut TransactionOutput, pubKeyHash, pubKey, rawSig []byte, flags ScriptFlags) error {
+	if !bytes.Equal(HashPubKey(pubKey), pubKeyHash) {
+		return errors.New("public key does not match script")
+	}
+	return tx.checkSignature(idx, prevOut, pubKey, rawSig, flags)
+}
+
+// verifyInput checks that input idx satisfies the locking script of prevOut.
+func (tx *Transaction) verifyInput(idx int, prevOut TransactionOutput, flags ScriptFlags) error {
+	return tx.verifyScript(idx, prevOut, prevOut.ScriptPubKey, true, flags)
+}
+
+func (tx *Transaction) verifyScript(idx int, prevOut TransactionOutput, script string, allowScriptHash bool, flags ScriptFlags) error {
+	in := tx.Vin[idx]
+
+	if hash, ok := ExtractPubKeyHash(script); ok {
+		return tx.checkKeyHashSignature(idx, prevOut, hash, in.PubKey, in.Signature, flags)
+	}
+
+	if hash, ok := ExtractScriptHash(script); ok {
+		if !allowScriptHash {
+			return errors.New("nested script hash is not allowed")
+		}
+		if !bytes.Equal(HashScript(in.ScriptSig), hash) {
+			return errors.New("redeem script does not match script hash")
+		}
+		return tx.verifyScript(idx, prevOut, in.ScriptSig, false, flags)
+	}
+
+	if multisig, ok := ParseMultisigScript(script); ok {
+		return tx.verifyMultisig(idx, prevOut, multisig, flags)
+	}
+
+	if htlc, ok := ParseHTLCScript(script); ok {
+		return tx.verifyHTLC(idx, prevOut, htlc, flags)
+	}
+
+	// Non-standard scripts, including malformed multisig and HTLC scripts,
+	// commit to no key, so any key the input supplied would do.
+	return ErrNonStandardScript
+}
@@ -0,0 +1,94 @@
+package transactions
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/NicholasRodrigues/go-chain/pkg/address"
+	"github.com/NicholasRodrigues/go-chain/pkg/crypto"
+)
+
+func TestPayToAddrScriptRoundTrip(t *testing.T) {
+	privKey, err := crypto.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to create private key: %v", err)
+	}
+	pubKeyHash := HashPubKey(privKey.PublicKey().Bytes())
+
+	keyAddr, _ := address.NewPubKeyHash(pubKeyHash, &address.MainNetParams)
+	scriptAddr, _ := address.NewScriptHash(HashScript("some redeem script"), &address.MainNetParams)
+
+	for _, addr := range []*address.Address{keyAddr, scriptAddr} {
+		script := PayToAddrScript(addr)
+		extracted, err := ExtractAddress(script, &address.MainNetParams)
+		if err != nil {
+			t.Fatalf("Failed to extract address: %v", err)
+		}
+		if extracted.Type != addr.Type || !bytes.Equal(extracted.Hash, addr.Hash) {
+			t.Errorf("Expected address %s, got %s", addr, extracted)
+		}
+	}
+}
+
+func TestExtractAddressNonStandard(t *testing.T) {
+	if _, err := ExtractAddress("pubkey1", &address.MainNetParams); err == nil {
+		t.Errorf("Expected non-standard script to have no address")
+	}
+}
+
+func TestIsLockedWithKey(t *testing.T) {
+	pubKeyHash := HashPubKey([]byte("public key"))
+	out := TransactionOutput{Value: 10, ScriptPubKey: PayToPubKeyHashScript(pubKeyHash)}
+
+	if !out.IsLockedWithKey(pubKeyHash) {
+		t.Errorf("Expected output to be locked with key")
+	}
+	if out.IsLockedWithKey(HashPubKey([]byte("other key"))) {
+		t.Errorf("Expected output not to be locked with another key")
+	}
+}
+
+func TestValidate_RejectsNonStandardScript(t *testing.T) {
+	prevOut := TransactionOutput{Value: 10, ScriptPubKey: "coinbase"}
+	utxoSet := map[string]TransactionOutput{UTXOKey([]byte("prev"), 0): prevOut}
+	tx := NewTransaction(
+		[]TransactionInput{{Txid: []byte("prev"), Vout: 0}},
+		[]TransactionOutput{{Value: 10, ScriptPubKey: "recipient"}},
+	)
+	if err := tx.SignInput(0, newTestKey(t), prevOut); err != nil {
+		t.Fatalf("Failed to sign input: %v", err)
+	}
+	if err := tx.ValidateWithFlags(utxoSet, defaultFlags); !errors.Is(err, ErrNonStandardScript) {
+		t.Errorf("Expected a non-standard script to be unspendable whatever the key, got %v", err)
+	}
+}
+
+func TestValidate_PayToPubKeyHash(t *testing.T) {
+	owner := newTestKey(t)
+	prevOut := TransactionOutput{Value: 10, ScriptPubKey: PayToPubKeyHashScript(HashPubKey(owner.PublicKey().Bytes()))}
+	utxoSet := map[string]TransactionOutput{UTXOKey([]byte("prev"), 0): prevOut}
+
+	newSpend := func() *Transaction {
+		return NewTransaction(
+			[]TransactionInput{{Txid: []byte("prev"), Vout: 0}},
+			[]TransactionOutput{{Value: 10, ScriptPubKey: "recipient"}},
+		)
+	}
+
+	tx := newSpend()
+	if err := tx.SignInput(0, owner, prevOut); err != nil {
+		t.Fatalf("Failed to sign input: %v", err)
+	}
+	if !tx.Validate(utxoSet) {
+		t.Errorf("Expected spend by the owner to be valid")
+	}
+
+	thief := newSpend()
+	if err := thief.SignInput(0, newTestKey(t), prevOut); err != nil {
+		t.Fatalf("Failed to sign input: %v", err)
+	}
+	if thief.Validate(utxoSet) {
+		t.Errorf("Expected spend by another key to be invalid")
+	}
+}
@@ -0,0 +1,77 @@
+package transactions
+
+import (
+	"crypto/sha256"
+	"sync"
+)
+
+// DefaultSigCacheSize is the number of entries a signature cache holds by default.
+const DefaultSigCacheSize = 50000
+
+// SigCache remembers signatures that have already been verified, so that
+// transactions checked on entry to the mempool are not verified again when
+// they arrive in a block. Entries are keyed by the signature hash, which
+// identifies the transaction, the input and the output it spends, together
+// with the public key and the signature itself. It is safe for concurrent use.
+type SigCache struct {
+	mu         sync.RWMutex
+	entries    map[[32]byte]struct{}
+	maxEntries int
+}
+
+// NewSigCache creates a cache holding at most maxEntries signatures.
+func NewSigCache(maxEntries int) *SigCache {
+	return &SigCache{entries: make(map[[32]byte]struct{}), maxEntries: maxEntries}
+}
+
+func sigCacheKey(digest, pubKey, sig []byte) [32]byte {
+	w := &hashWriter{}
+	w.writeBytes(digest)
+	w.writeBytes(pubKey)
+	w.writeBytes(sig)
+	return sha256.Sum256(w.buf.Bytes())
+}
+
+// Contains reports whether sig by pubKey over digest has been verified.
+// A nil cache contains nothing.
+func (c *SigCache) Contains(digest, pubKey, sig []byte) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok := c.entries[sigCacheKey(digest, pubKey, sig)]
+	return ok
+}
+
+// Add records a verified signature, evicting an arbitrary entry when the
+// cache is full. Adding to a nil cache does nothing.
+func (c *SigCache) Add(digest, pubKey, sig []byte) {
+	if c == nil || c.maxEntries <= 0 {
+		return
+	}
+	key := sigCacheKey(digest, pubKey, sig)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; ok {
+		return
+	}
+	for evict := range c.entries {
+		if len(c.entries) < c.maxEntries {
+			break
+		}
+		delete(c.entries, evict)
+	}
+	c.entries[key] = struct{}{}
+}
+
+// Len returns the number of cached signatures.
+func (c *SigCache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.entries)
+}
@@ -0,0 +1,122 @@
+package transactions
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+)
+
+// SigHashType selects which parts of a transaction a signature commits to.
+// It is appended as the last byte of every input signature.
+type SigHashType byte
+
+const (
+	// SigHashAll commits to every input and every output.
+	SigHashAll SigHashType = 0x01
+	// SigHashNone commits to the inputs but to none of the outputs.
+	SigHashNone SigHashType = 0x02
+	// SigHashSingle commits to the inputs and the output at the signed index.
+	SigHashSingle SigHashType = 0x03
+	// SigHashAnyoneCanPay may be combined with the types above to commit to
+	// the signed input only, letting others add inputs.
+	SigHashAnyoneCanPay SigHashType = 0x80
+
+	sigHashMask = 0x1f
+)
+
+// IsValid reports whether the hash type is one of the supported combinations.
+func (t SigHashType) IsValid() bool {
+	base := t &^ SigHashAnyoneCanPay
+	return base >= SigHashAll && base <= SigHashSingle
+}
+
+// hashWriter builds the canonical, length-prefixed encoding that signature
+// digests are computed over.
+type hashWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *hashWriter) writeInt(v int64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(v))
+	w.buf.Write(b[:])
+}
+
+func (w *hashWriter) writeBytes(b []byte) {
+	w.writeInt(int64(len(b)))
+	w.buf.Write(b)
+}
+
+func (w *hashWriter) writeInput(in TransactionInput) {
+	w.writeBytes(in.Txid)
+	w.writeInt(int64(in.Vout))
+	w.writeInt(int64(in.Sequence))
+}
+
+func (w *hashWriter) writeOutput(out TransactionOutput) {
+	w.writeInt(int64(out.Value))
+	w.writeBytes([]byte(out.ScriptPubKey))
+}
+
+// SignatureHash returns the digest that input idx must sign. It commits to
+// the input index, the value and script of the output being spent and the
+// hash type, along with the inputs and outputs selected by the hash type.
+func (tx *Transaction) SignatureHash(idx int, prevOut TransactionOutput, hashType SigHashType) ([]byte, error) {
+	if idx < 0 || idx >= len(tx.Vin) {
+		return nil, fmt.Errorf("input index %d out of range", idx)
+	}
+	if !hashType.IsValid() {
+		return nil, fmt.Errorf("invalid signature hash type 0x%02x", byte(hashType))
+	}
+
+	base := hashType & sigHashMask
+	if base == SigHashSingle && idx >= len(tx.Vout) {
+		return nil, fmt.Errorf("no output matches input %d for SIGHASH_SINGLE", idx)
+	}
+
+	w := &hashWriter{}
+	w.buf.WriteByte(byte(hashType))
+
+	if hashType&SigHashAnyoneCanPay != 0 {
+		w.writeInt(1)
+		w.writeInput(tx.Vin[idx])
+	} else {
+		w.writeInt(int64(len(tx.Vin)))
+		for _, in := range tx.Vin {
+			w.writeInput(in)
+		}
+	}
+
+	switch base {
+	case SigHashAll:
+		w.writeInt(int64(len(tx.Vout)))
+		for _, out := range tx.Vout {
+			w.writeOutput(out)
+		}
+	case SigHashSingle:
+		w.writeInt(1)
+		w.writeOutput(tx.Vout[idx])
+	case SigHashNone:
+		w.writeInt(0)
+	}
+
+	w.writeInt(tx.LockTime)
+	w.writeInt(int64(idx))
+	w.writeOutput(prevOut)
+
+	hash := sha256.Sum256(w.buf.Bytes())
+	return hash[:], nil
+}
+
+// splitSignature separates the hash type byte from a raw signature.
+func splitSignature(sig []byte) ([]byte, SigHashType, error) {
+	if len(sig) == 0 {
+		return nil, 0, fmt.Errorf("empty signature")
+	}
+	hashType := SigHashType(sig[len(sig)-1])
+	if !hashType.IsValid() {
+		return nil, 0, fmt.Errorf("invalid signature hash type 0x%02x", byte(hashType))
+	}
+	return sig[:len(sig)-1], hashType, nil
+}
@@ -0,0 +1,155 @@
+package transactions
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/NicholasRodrigues/go-chain/pkg/crypto"
+)
+
+func newTestKey(t *testing.T) *crypto.PrivateKey {
+	privKey, err := crypto.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to create private key: %v", err)
+	}
+	return privKey
+}
+
+// payTo returns a pay-to-pubkey-hash locking script for the key.
+func payTo(privKey *crypto.PrivateKey) string {
+	return PayToPubKeyHashScript(HashPubKey(privKey.PublicKey().Bytes()))
+}
+
+var owner1, owner2 = newSeededKey(1), newSeededKey(2)
+
+func newSeededKey(b byte) *crypto.PrivateKey {
+	privKey, _ := crypto.NewPrivateKeyFromSeed(bytes.Repeat([]byte{b}, 32))
+	return privKey
+}
+
+// twoInputTransaction returns a transaction spending outputs owned by owner1
+// and owner2.
+func twoInputTransaction() (*Transaction, map[string]TransactionOutput) {
+	utxoSet := map[string]TransactionOutput{
+		UTXOKey([]byte("prev1"), 0): {Value: 6, ScriptPubKey: payTo(owner1)},
+		UTXOKey([]byte("prev2"), 1): {Value: 4, ScriptPubKey: payTo(owner2)},
+	}
+	tx := NewTransaction(
+		[]TransactionInput{
+			{Txid: []byte("prev1"), Vout: 0},
+			{Txid: []byte("prev2"), Vout: 1},
+		},
+		[]TransactionOutput{
+			{Value: 7, ScriptPubKey: "recipient"},
+			{Value: 3, ScriptPubKey: "change"},
+		},
+	)
+	return tx, utxoSet
+}
+
+func TestSignInput_DifferentKeysPerInput(t *testing.T) {
+	tx, utxoSet := twoInputTransaction()
+
+	if err := tx.SignInput(0, owner1, utxoSet[UTXOKey([]byte("prev1"), 0)]); err != nil {
+		t.Fatalf("Failed to sign input 0: %v", err)
+	}
+	if err := tx.SignInput(1, owner2, utxoSet[UTXOKey([]byte("prev2"), 1)]); err != nil {
+		t.Fatalf("Failed to sign input 1: %v", err)
+	}
+
+	if bytes.Equal(tx.Vin[0].PubKey, tx.Vin[1].PubKey) {
+		t.Errorf("Expected inputs to be signed by different keys")
+	}
+	if !tx.Validate(utxoSet) {
+		t.Errorf("Expected transaction signed by two parties to be valid")
+	}
+}
+
+func TestSignatureHash_CommitsToInputAndPrevOut(t *testing.T) {
+	tx, _ := twoInputTransaction()
+	prevOut := TransactionOutput{Value: 6, ScriptPubKey: "owner1"}
+
+	base, _ := tx.SignatureHash(0, prevOut, SigHashAll)
+	otherIndex, _ := tx.SignatureHash(1, prevOut, SigHashAll)
+	otherValue, _ := tx.SignatureHash(0, TransactionOutput{Value: 7, ScriptPubKey: "owner1"}, SigHashAll)
+	otherScript, _ := tx.SignatureHash(0, TransactionOutput{Value: 6, ScriptPubKey: "owner2"}, SigHashAll)
+	otherType, _ := tx.SignatureHash(0, prevOut, SigHashNone)
+
+	for _, digest := range [][]byte{otherIndex, otherValue, otherScript, otherType} {
+		if bytes.Equal(base, digest) {
+			t.Errorf("Expected signature hashes to differ")
+		}
+	}
+}
+
+func TestSignatureHash_Flags(t *testing.T) {
+	tx, utxoSet := twoInputTransaction()
+	prevOut := utxoSet[UTXOKey([]byte("prev1"), 0)]
+
+	digests := func() map[SigHashType][]byte {
+		out := make(map[SigHashType][]byte)
+		for _, ht := range []SigHashType{SigHashAll, SigHashNone, SigHashSingle, SigHashAll | SigHashAnyoneCanPay} {
+			out[ht], _ = tx.SignatureHash(0, prevOut, ht)
+		}
+		return out
+	}
+
+	before := digests()
+
+	// Changing an unrelated output only affects ALL.
+	tx.Vout[1].Value = 2
+	after := digests()
+	if bytes.Equal(before[SigHashAll], after[SigHashAll]) {
+		t.Errorf("Expected SIGHASH_ALL to commit to every output")
+	}
+	if !bytes.Equal(before[SigHashNone], after[SigHashNone]) {
+		t.Errorf("Expected SIGHASH_NONE not to commit to outputs")
+	}
+	if !bytes.Equal(before[SigHashSingle], after[SigHashSingle]) {
+		t.Errorf("Expected SIGHASH_SINGLE not to commit to other outputs")
+	}
+
+	// Adding an input only leaves ANYONECANPAY unaffected.
+	tx.Vin = append(tx.Vin, TransactionInput{Txid: []byte("prev3"), Vout: 0})
+	withInput := digests()
+	if !bytes.Equal(after[SigHashAll|SigHashAnyoneCanPay], withInput[SigHashAll|SigHashAnyoneCanPay]) {
+		t.Errorf("Expected SIGHASH_ANYONECANPAY not to commit to other inputs")
+	}
+	if bytes.Equal(after[SigHashNone], withInput[SigHashNone]) {
+		t.Errorf("Expected SIGHASH_NONE to commit to every input")
+	}
+}
+
+func TestSignatureHash_Errors(t *testing.T) {
+	tx, _ := twoInputTransaction()
+	tx.Vout = tx.Vout[:1]
+
+	if _, err := tx.SignatureHash(1, TransactionOutput{}, SigHashSingle); err == nil {
+		t.Errorf("Expected SIGHASH_SINGLE without matching output to fail")
+	}
+	if _, err := tx.SignatureHash(5, TransactionOutput{}, SigHashAll); err == nil {
+		t.Errorf("Expected out of range input index to fail")
+	}
+	if _, err := tx.SignatureHash(0, TransactionOutput{}, SigHashType(0x04)); err == nil {
+		t.Errorf("Expected invalid hash type to fail")
+	}
+}
+
+func TestValidate_RejectsSignatureForOtherPrevOut(t *testing.T) {
+	tx, utxoSet := twoInputTransaction()
+	if err := tx.SignInput(0, owner1, utxoSet[UTXOKey([]byte("prev1"), 0)]); err != nil {
+		t.Fatalf("Failed to sign input 0: %v", err)
+	}
+	if err := tx.SignInput(1, owner2, utxoSet[UTXOKey([]byte("prev2"), 1)]); err != nil {
+		t.Fatalf("Failed to sign input 1: %v", err)
+	}
+	if !tx.Validate(utxoSet) {
+		t.Fatalf("Expected transaction to be valid")
+	}
+
+	// Pretend the first output being spent was worth less than signed for.
+	utxoSet[UTXOKey([]byte("prev1"), 0)] = TransactionOutput{Value: 5, ScriptPubKey: payTo(owner1)}
+	if tx.Validate(utxoSet) {
+		t.Errorf("Expected signature over a different previous output to be invalid")
+	}
+}
@@ -4,17 +4,31 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"encoding/gob"
-	"encoding/hex"
+	"errors"
 	"fmt"
+	"math"
 	"strings"
 
 	"github.com/NicholasRodrigues/go-chain/pkg/crypto"
 )
 
+var (
+	// ErrIDMismatch is returned for transactions whose ID is not the hash of
+	// their contents.
+	ErrIDMismatch = errors.New("transaction id does not match its contents")
+	// ErrMissingInput is returned for inputs spending an output that does
+	// not exist or is already spent.
+	ErrMissingInput = errors.New("input spends a missing or spent output")
+	// ErrInvalidValue is returned for transactions with negative outputs or
+	// spending more than their inputs.
+	ErrInvalidValue = errors.New("invalid transaction value")
+)
+
 type Transaction struct {
-	ID   []byte
-	Vin  []TransactionInput
-	Vout []TransactionOutput
+	ID       []byte
+	Vin      []TransactionInput
+	Vout     []TransactionOutput
+	LockTime int64 // Block height or Unix timestamp before which the transaction is not final
 }
 
 type TransactionInput struct {
@@ -23,6 +37,8 @@ type TransactionInput struct {
 	ScriptSig string
 	Signature []byte
 	PubKey    []byte
+	Witness   [][]byte // Additional signatures, e.g. for multisig outputs
+	Sequence  uint32   // Relative lock time, see SequenceLockTimeDisabled
 }
 
 type TransactionOutput struct {
@@ -37,21 +53,60 @@ func NewTransaction(vin []TransactionInput, vout []TransactionOutput) *Transacti
 	return tx
 }
 
-// Sign signs each input of the transaction using the provided private key.
-func (tx *Transaction) Sign(privKey *crypto.PrivateKey) {
-	for i := range tx.Vin {
-		msg := tx.Hash()
-		tx.Vin[i].Signature = privKey.Sign(msg)
-		tx.Vin[i].PubKey = privKey.PublicKey().Bytes()
+// NewCoinbaseTransaction creates a coinbase transaction paying value to
+// scriptPubKey. data is placed in the input's ScriptSig and should be unique
+// per block so that coinbase transaction IDs do not collide.
+func NewCoinbaseTransaction(scriptPubKey, data string, value int) *Transaction {
+	return NewTransaction(
+		[]TransactionInput{{Txid: []byte{}, Vout: -1, ScriptSig: data}},
+		[]TransactionOutput{{Value: value, ScriptPubKey: scriptPubKey}},
+	)
+}
+
+// Sign signs every input of the transaction with the same private key using
+// SigHashAll. prevOuts must contain the outputs being spent, keyed like the
+// UTXO set.
+func (tx *Transaction) Sign(privKey crypto.Signer, prevOuts map[string]TransactionOutput) error {
+	for i, vin := range tx.Vin {
+		prevOut, ok := prevOuts[UTXOKey(vin.Txid, vin.Vout)]
+		if !ok {
+			return fmt.Errorf("missing previous output %s", UTXOKey(vin.Txid, vin.Vout))
+		}
+		if err := tx.SignInput(i, privKey, prevOut); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SignInput signs input i with privKey using SigHashAll. prevOut is the output
+// being spent by the input.
+func (tx *Transaction) SignInput(i int, privKey crypto.Signer, prevOut TransactionOutput) error {
+	return tx.SignInputWithType(i, privKey, prevOut, SigHashAll)
+}
+
+// SignInputWithType signs input i with privKey, committing to the parts of
+// the transaction selected by hashType.
+func (tx *Transaction) SignInputWithType(i int, privKey crypto.Signer, prevOut TransactionOutput, hashType SigHashType) error {
+	digest, err := tx.SignatureHash(i, prevOut, hashType)
+	if err != nil {
+		return err
 	}
+	tx.Vin[i].Signature = append(privKey.Sign(digest), byte(hashType))
+	tx.Vin[i].PubKey = privKey.Public().Bytes()
+	return nil
 }
 
-// Hash returns the hash of the transaction, excluding the signature to avoid circular dependencies.
+// Hash returns the hash of the transaction, excluding the ID and signatures to avoid circular dependencies.
 func (tx *Transaction) Hash() []byte {
 	txCopy := *tx
+	txCopy.ID = nil
+	txCopy.Vin = make([]TransactionInput, len(tx.Vin))
+	copy(txCopy.Vin, tx.Vin)
 	for i := range txCopy.Vin {
 		txCopy.Vin[i].Signature = nil
 		txCopy.Vin[i].PubKey = nil
+		txCopy.Vin[i].Witness = nil
 	}
 
 	var encoded bytes.Buffer
@@ -72,54 +127,101 @@ func (tx *Transaction) SetID() {
 	tx.ID = tx.Hash()
 }
 
+// CheckID verifies that the ID of the transaction is the hash of its
+// contents, so that it cannot claim the ID of another transaction.
+func (tx *Transaction) CheckID() error {
+	if !bytes.Equal(tx.ID, tx.Hash()) {
+		return fmt.Errorf("%w: %x", ErrIDMismatch, tx.ID)
+	}
+	return nil
+}
+
 // IsCoinbase checks whether the transaction is a coinbase transaction.
 func (tx *Transaction) IsCoinbase() bool {
 	return len(tx.Vin) == 1 && len(tx.Vin[0].Txid) == 0 && tx.Vin[0].Vout == -1
 }
 
-func utxoKey(txid []byte, vout int) string {
+// UTXOKey returns the key identifying output vout of transaction txid in a UTXO set.
+func UTXOKey(txid []byte, vout int) string {
 	return fmt.Sprintf("%x:%d", txid, vout)
 }
 
-// / Validate ensures that the transaction is valid.
-func (tx *Transaction) Validate(utxoSet map[string]TransactionOutput) bool {
-	if tx.IsCoinbase() {
-		return true
-	}
-
+// CheckValues verifies that every input of a non-coinbase transaction spends
+// an output of utxoSet, no output twice, and that the outputs, none of them
+// negative, do not exceed the inputs. It returns the fee, the value of the
+// inputs not claimed by the outputs.
+func (tx *Transaction) CheckValues(utxoSet map[string]TransactionOutput) (int, error) {
 	inputValue := 0
+	spent := make(map[string]bool, len(tx.Vin))
 	for _, vin := range tx.Vin {
-		// Look up the referenced transaction output in the UTXO set
-		key := utxoKey(vin.Txid, vin.Vout)
+		key := UTXOKey(vin.Txid, vin.Vout)
 		utxo, ok := utxoSet[key]
-		if !ok {
-			fmt.Printf("Referenced output not found in UTXO set: %s\n", key)
-			return false // Referenced output not found, transaction is invalid
-		}
-
-		// Verify the signature
-		pubKey, err := crypto.PublicKeyFromString(hex.EncodeToString(vin.PubKey))
-		if err != nil {
-			fmt.Printf("Failed to parse public key: %v\n", err)
-			return false
+		if !ok || spent[key] {
+			return 0, fmt.Errorf("%w: %s", ErrMissingInput, key)
 		}
-		if !pubKey.Verify(tx.Hash(), vin.Signature) {
-			fmt.Println("Signature is invalid")
-			return false // Signature is invalid
+		spent[key] = true
+		if inputValue, ok = addValue(inputValue, utxo.Value); !ok {
+			return 0, fmt.Errorf("%w: input %s has value %d", ErrInvalidValue, key, utxo.Value)
 		}
+	}
 
-		inputValue += utxo.Value
+	outputValue, err := tx.OutputValue()
+	if err != nil {
+		return 0, err
+	}
+	if outputValue > inputValue {
+		return 0, fmt.Errorf("%w: outputs of %d exceed inputs of %d", ErrInvalidValue, outputValue, inputValue)
 	}
+	return inputValue - outputValue, nil
+}
 
-	outputValue := 0
-	for _, vout := range tx.Vout {
-		outputValue += vout.Value
+// OutputValue returns the total value of the outputs of tx, failing if one of
+// them is negative or the total overflows.
+func (tx *Transaction) OutputValue() (int, error) {
+	total := 0
+	for i, vout := range tx.Vout {
+		var ok bool
+		if total, ok = addValue(total, vout.Value); !ok {
+			return 0, fmt.Errorf("%w: output %d has value %d", ErrInvalidValue, i, vout.Value)
+		}
 	}
+	return total, nil
+}
 
-	fmt.Printf("Input value: %d, Output value: %d\n", inputValue, outputValue)
+// addValue adds value to total, failing for negative values and overflows.
+func addValue(total, value int) (int, bool) {
+	if value < 0 || total > math.MaxInt-value {
+		return 0, false
+	}
+	return total + value, true
+}
 
-	// Check if input value matches output value
-	return inputValue >= outputValue
+// / Validate ensures that the transaction is valid.
+func (tx *Transaction) Validate(utxoSet map[string]TransactionOutput) bool {
+	return tx.ValidateWithFlags(utxoSet, defaultFlags) == nil
+}
+
+// ValidateWithFlags is Validate with explicit script verification flags,
+// returning the reason the transaction is invalid.
+func (tx *Transaction) ValidateWithFlags(utxoSet map[string]TransactionOutput, flags ScriptFlags) error {
+	if err := tx.CheckLimits(); err != nil {
+		return err
+	}
+	if tx.IsCoinbase() {
+		return nil
+	}
+	if _, err := tx.CheckValues(utxoSet); err != nil {
+		return err
+	}
+	flags.sighashes = newSighashMemo()
+
+	for i, vin := range tx.Vin {
+		// Verify that the input satisfies the output's locking script
+		if err := tx.verifyInput(i, utxoSet[UTXOKey(vin.Txid, vin.Vout)], flags); err != nil {
+			return fmt.Errorf("input %d: %w", i, err)
+		}
+	}
+	return nil
 }
 
 // Serialize serializes the transaction into a byte slice.
@@ -133,6 +235,25 @@ func (tx *Transaction) Serialize() []byte {
 	return encoded.Bytes()
 }
 
+// DecodeTransaction deserializes a transaction from a byte slice, returning
+// an error for malformed input instead of panicking. Input larger than
+// MaxTransactionSize is rejected before decoding, and the decoded transaction
+// must respect the other consensus limits.
+func DecodeTransaction(data []byte) (*Transaction, error) {
+	if len(data) > MaxTransactionSize {
+		return nil, fmt.Errorf("%w: %d bytes, at most %d allowed", ErrLimitExceeded, len(data), MaxTransactionSize)
+	}
+	var transaction Transaction
+	decoder := gob.NewDecoder(bytes.NewReader(data))
+	if err := decoder.Decode(&transaction); err != nil {
+		return nil, fmt.Errorf("failed to decode transaction: %w", err)
+	}
+	if err := transaction.CheckLimits(); err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
 // DeserializeTransaction deserializes a transaction from a byte slice.
 func DeserializeTransaction(data []byte) *Transaction {
 	var transaction Transaction
@@ -156,6 +277,10 @@ func (tx *Transaction) String() string {
 		lines = append(lines, fmt.Sprintf("       ScriptSig: %s", input.ScriptSig))
 		lines = append(lines, fmt.Sprintf("       Signature: %x", input.Signature))
 		lines = append(lines, fmt.Sprintf("       PubKey:    %x", input.PubKey))
+		lines = append(lines, fmt.Sprintf("       Sequence:  %d", input.Sequence))
+		for j, w := range input.Witness {
+			lines = append(lines, fmt.Sprintf("       Witness %d: %x", j, w))
+		}
 	}
 
 	for i, output := range tx.Vout {
@@ -164,12 +289,11 @@ func (tx *Transaction) String() string {
 		lines = append(lines, fmt.Sprintf("       ScriptPubKey: %s", output.ScriptPubKey))
 	}
 
-	return strings.Join(lines, "\n")
-}
+	if tx.LockTime != 0 {
+		lines = append(lines, fmt.Sprintf("     LockTime: %d", tx.LockTime))
+	}
 
-// IsLockedWithKey checks if the output can be used by the owner of the pubKeyHash
-func (out *TransactionOutput) IsLockedWithKey(pubKeyHash []byte) bool {
-	return bytes.Compare([]byte(out.ScriptPubKey), pubKeyHash) == 0
+	return strings.Join(lines, "\n")
 }
 
 // UsesKey checks if the input uses the pubKeyHash to unlock the output
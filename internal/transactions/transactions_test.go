@@ -3,6 +3,7 @@ package transactions
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
 	"testing"
 
 	"github.com/NicholasRodrigues/go-chain/pkg/crypto"
@@ -61,9 +62,21 @@ func TestTransaction_SetID(t *testing.T) {
 	tx := NewTransaction(inputs, outputs)
 	txIDBefore := tx.ID
 
+	tx.SetID()
+	if !bytes.Equal(tx.ID, txIDBefore) {
+		t.Errorf("Expected the transaction ID to depend only on its contents")
+	}
+	if err := tx.CheckID(); err != nil {
+		t.Errorf("Expected the ID to match: %v", err)
+	}
+
+	tx.Vout[0].Value = 11
+	if err := tx.CheckID(); !errors.Is(err, ErrIDMismatch) {
+		t.Errorf("Expected an ID mismatch after changing the outputs, got %v", err)
+	}
 	tx.SetID()
 	if bytes.Equal(tx.ID, txIDBefore) {
-		t.Errorf("Expected transaction ID to change after setting")
+		t.Errorf("Expected the transaction ID to change with its contents")
 	}
 }
 
@@ -81,14 +94,26 @@ func TestTransaction_SignAndVerify(t *testing.T) {
 	}
 	tx := NewTransaction(inputs, outputs)
 
-	tx.Sign(privKey)
+	prevOut := TransactionOutput{Value: 10, ScriptPubKey: "pubkey1"}
+	prevOuts := map[string]TransactionOutput{UTXOKey([]byte("somepreviousid"), 1): prevOut}
+	if err := tx.Sign(privKey, prevOuts); err != nil {
+		t.Fatalf("Failed to sign transaction: %v", err)
+	}
 
-	for _, vin := range tx.Vin {
+	for i, vin := range tx.Vin {
 		pubKey, err := crypto.PublicKeyFromString(hex.EncodeToString(vin.PubKey))
 		if err != nil {
 			t.Fatalf("Failed to parse public key: %v", err)
 		}
-		if !pubKey.Verify(tx.Hash(), vin.Signature) {
+		sig, hashType, err := splitSignature(vin.Signature)
+		if err != nil {
+			t.Fatalf("Failed to parse signature: %v", err)
+		}
+		digest, err := tx.SignatureHash(i, prevOut, hashType)
+		if err != nil {
+			t.Fatalf("Failed to compute signature hash: %v", err)
+		}
+		if !pubKey.Verify(digest, sig) {
 			t.Errorf("Failed to verify transaction input signature")
 		}
 	}
@@ -102,7 +127,7 @@ func TestTransaction_Validate(t *testing.T) {
 
 	// Example UTXO set
 	utxoSet := make(map[string]TransactionOutput)
-	utxoSet[utxoKey([]byte("somepreviousid"), 0)] = TransactionOutput{Value: 10, ScriptPubKey: "pubkey1"}
+	utxoSet[UTXOKey([]byte("somepreviousid"), 0)] = TransactionOutput{Value: 10, ScriptPubKey: payTo(privKey)}
 
 	inputs := []TransactionInput{
 		{Txid: []byte("somepreviousid"), Vout: 0, ScriptSig: "signature"},
@@ -113,7 +138,9 @@ func TestTransaction_Validate(t *testing.T) {
 	tx := NewTransaction(inputs, outputs)
 
 	// Sign the transaction
-	tx.Sign(privKey)
+	if err := tx.Sign(privKey, utxoSet); err != nil {
+		t.Fatalf("Failed to sign transaction: %v", err)
+	}
 
 	// Validate the transaction
 	isValid := tx.Validate(utxoSet)
@@ -129,6 +156,43 @@ func TestTransaction_Validate(t *testing.T) {
 	}
 }
 
+func TestTransaction_CheckValues(t *testing.T) {
+	utxoSet := map[string]TransactionOutput{
+		UTXOKey([]byte("a"), 0): {Value: 10, ScriptPubKey: "pubkey1"},
+		UTXOKey([]byte("b"), 0): {Value: 5, ScriptPubKey: "pubkey1"},
+	}
+	a := TransactionInput{Txid: []byte("a"), Vout: 0}
+	b := TransactionInput{Txid: []byte("b"), Vout: 0}
+	output := func(values ...int) []TransactionOutput {
+		var outputs []TransactionOutput
+		for _, v := range values {
+			outputs = append(outputs, TransactionOutput{Value: v, ScriptPubKey: "pubkey2"})
+		}
+		return outputs
+	}
+
+	fee, err := NewTransaction([]TransactionInput{a, b}, output(12)).CheckValues(utxoSet)
+	if err != nil || fee != 3 {
+		t.Errorf("Expected a fee of 3, got %d, %v", fee, err)
+	}
+
+	for _, test := range []struct {
+		name    string
+		inputs  []TransactionInput
+		outputs []TransactionOutput
+		err     error
+	}{
+		{"missing input", []TransactionInput{{Txid: []byte("c"), Vout: 0}}, output(1), ErrMissingInput},
+		{"duplicate input", []TransactionInput{a, a}, output(20), ErrMissingInput},
+		{"outputs exceed inputs", []TransactionInput{a}, output(11), ErrInvalidValue},
+		{"negative output", []TransactionInput{a}, output(20, -10), ErrInvalidValue},
+	} {
+		if _, err := NewTransaction(test.inputs, test.outputs).CheckValues(utxoSet); !errors.Is(err, test.err) {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.err)
+		}
+	}
+}
+
 func TestTransaction_IsCoinbase(t *testing.T) {
 	coinbaseTx := NewTransaction(
 		[]TransactionInput{
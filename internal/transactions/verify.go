@@ -0,0 +1,117 @@
+package transactions
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// sighashMemo stores the signature hashes of one transaction so that each
+// (input, hash type) digest is computed once, however many signatures and
+// keys are checked against it.
+type sighashMemo struct {
+	mu      sync.Mutex
+	digests map[sighashKey][]byte
+}
+
+type sighashKey struct {
+	idx      int
+	hashType SigHashType
+}
+
+func newSighashMemo() *sighashMemo {
+	return &sighashMemo{digests: make(map[sighashKey][]byte)}
+}
+
+// signatureHash returns the memoized digest of input idx. A nil memo computes
+// the digest every time.
+func (m *sighashMemo) signatureHash(tx *Transaction, idx int, prevOut TransactionOutput, hashType SigHashType) ([]byte, error) {
+	if m == nil {
+		return tx.SignatureHash(idx, prevOut, hashType)
+	}
+	key := sighashKey{idx, hashType}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if digest, ok := m.digests[key]; ok {
+		return digest, nil
+	}
+	digest, err := tx.SignatureHash(idx, prevOut, hashType)
+	if err != nil {
+		return nil, err
+	}
+	m.digests[key] = digest
+	return digest, nil
+}
+
+// InputCheck is one input whose script must be verified.
+type InputCheck struct {
+	Tx      *Transaction
+	Index   int
+	PrevOut TransactionOutput
+}
+
+// BatchVerifier verifies the scripts of many inputs across a pool of workers.
+type BatchVerifier struct {
+	Flags   ScriptFlags
+	Cache   *SigCache // Optional cache of already verified signatures
+	Workers int       // Number of goroutines; GOMAXPROCS when zero
+}
+
+// Verify checks every input and returns the first failure found. Once an
+// input fails, remaining work is abandoned.
+func (v *BatchVerifier) Verify(checks []InputCheck) error {
+	memos := make(map[*Transaction]*sighashMemo)
+	for _, check := range checks {
+		if _, ok := memos[check.Tx]; !ok {
+			memos[check.Tx] = newSighashMemo()
+		}
+	}
+
+	workers := v.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(checks) {
+		workers = len(checks)
+	}
+
+	jobs := make(chan InputCheck)
+	var failed atomic.Bool
+	var firstErr error
+	var errOnce sync.Once
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for check := range jobs {
+				if failed.Load() {
+					continue
+				}
+				flags := v.Flags
+				flags.cache = v.Cache
+				flags.sighashes = memos[check.Tx]
+				if err := check.Tx.verifyInput(check.Index, check.PrevOut, flags); err != nil {
+					failed.Store(true)
+					errOnce.Do(func() {
+						firstErr = fmt.Errorf("transaction %x input %d: %w", check.Tx.ID, check.Index, err)
+					})
+				}
+			}
+		}()
+	}
+
+	for _, check := range checks {
+		if failed.Load() {
+			break
+		}
+		jobs <- check
+	}
+	close(jobs)
+	wg.Wait()
+
+	return firstErr
+}
@@ -0,0 +1,111 @@
+package transactions
+
+import (
+	"fmt"
+	"testing"
+)
+
+func signedSpends(t *testing.T, n int) ([]InputCheck, map[string]TransactionOutput) {
+	var checks []InputCheck
+	utxoSet := make(map[string]TransactionOutput)
+	for i := 0; i < n; i++ {
+		privKey := newTestKey(t)
+		prevOut := TransactionOutput{Value: 10, ScriptPubKey: PayToPubKeyHashScript(HashPubKey(privKey.PublicKey().Bytes()))}
+		txid := []byte(fmt.Sprintf("prev%d", i))
+		utxoSet[UTXOKey(txid, 0)] = prevOut
+
+		tx := NewTransaction(
+			[]TransactionInput{{Txid: txid, Vout: 0}},
+			[]TransactionOutput{{Value: 10, ScriptPubKey: "recipient"}},
+		)
+		if err := tx.SignInput(0, privKey, prevOut); err != nil {
+			t.Fatalf("Failed to sign input: %v", err)
+		}
+		checks = append(checks, InputCheck{Tx: tx, Index: 0, PrevOut: prevOut})
+	}
+	return checks, utxoSet
+}
+
+func TestBatchVerifier(t *testing.T) {
+	checks, _ := signedSpends(t, 20)
+	verifier := &BatchVerifier{Flags: ConsensusFlags(1, DefaultSchemeActivations()), Workers: 4}
+	if err := verifier.Verify(checks); err != nil {
+		t.Fatalf("Expected batch to verify, got %v", err)
+	}
+	if err := verifier.Verify(nil); err != nil {
+		t.Errorf("Expected empty batch to verify, got %v", err)
+	}
+
+	checks[13].Tx.Vin[0].Signature[0] ^= 0xff
+	if err := verifier.Verify(checks); err == nil {
+		t.Errorf("Expected batch with a bad signature to fail")
+	}
+}
+
+func TestSigCacheSharedWithBatch(t *testing.T) {
+	checks, utxoSet := signedSpends(t, 3)
+	cache := NewSigCache(10)
+
+	for _, check := range checks {
+		if err := check.Tx.ValidateWithFlags(utxoSet, StandardFlags(1, DefaultSchemeActivations()).WithCache(cache)); err != nil {
+			t.Fatalf("Expected transaction to be valid")
+		}
+	}
+	if cache.Len() != 3 {
+		t.Fatalf("Expected 3 cached signatures, got %d", cache.Len())
+	}
+
+	verifier := &BatchVerifier{Flags: ConsensusFlags(1, DefaultSchemeActivations()), Cache: cache}
+	if err := verifier.Verify(checks); err != nil {
+		t.Fatalf("Expected cached batch to verify, got %v", err)
+	}
+	if cache.Len() != 3 {
+		t.Errorf("Expected cached signatures to be reused, got %d entries", cache.Len())
+	}
+
+	// A changed signature misses the cache and is verified again.
+	checks[0].Tx.Vin[0].Signature[0] ^= 0xff
+	if err := verifier.Verify(checks); err == nil {
+		t.Errorf("Expected altered signature to fail despite the cache")
+	}
+}
+
+func TestSigCacheEviction(t *testing.T) {
+	cache := NewSigCache(2)
+	for i := byte(0); i < 5; i++ {
+		cache.Add([]byte{i}, []byte("key"), []byte("sig"))
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Expected cache to stay at 2 entries, got %d", cache.Len())
+	}
+	if !cache.Contains([]byte{4}, []byte("key"), []byte("sig")) {
+		t.Errorf("Expected most recent entry to be cached")
+	}
+
+	var nilCache *SigCache
+	nilCache.Add([]byte{1}, nil, nil)
+	if nilCache.Contains([]byte{1}, nil, nil) {
+		t.Errorf("Expected nil cache to be empty")
+	}
+}
+
+func TestSighashMemo(t *testing.T) {
+	tx, utxoSet := twoInputTransaction()
+	prevOut := utxoSet[UTXOKey([]byte("prev1"), 0)]
+	memo := newSighashMemo()
+
+	first, err := memo.signatureHash(tx, 0, prevOut, SigHashAll)
+	if err != nil {
+		t.Fatalf("Failed to compute signature hash: %v", err)
+	}
+	// The memo returns the stored digest without recomputing it.
+	tx.Vout[0].Value++
+	second, _ := memo.signatureHash(tx, 0, prevOut, SigHashAll)
+	if string(first) != string(second) {
+		t.Errorf("Expected memoized digest to be reused")
+	}
+	third, _ := memo.signatureHash(tx, 0, prevOut, SigHashNone)
+	if string(first) == string(third) {
+		t.Errorf("Expected different hash types to be memoized separately")
+	}
+}
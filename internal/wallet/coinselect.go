@@ -0,0 +1,260 @@
+package wallet
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+)
+
+const (
+	// DefaultFeeRate is the fee paid per 1000 bytes of serialized transaction.
+	DefaultFeeRate = 1
+	// DefaultDustThreshold is the smallest change output worth creating.
+	// Smaller change is added to the fee instead.
+	DefaultDustThreshold = 1
+
+	// bnbMaxTries bounds the branch-and-bound search.
+	bnbMaxTries = 100000
+)
+
+// ErrNoExactMatch is returned by BranchAndBound when no subset of coins pays
+// the target without creating change.
+var ErrNoExactMatch = errors.New("wallet: no exact match found")
+
+// Strategy chooses which coins fund a payment.
+type Strategy int
+
+const (
+	// LargestFirst spends the largest coins until the payment is covered.
+	LargestFirst Strategy = iota
+	// BranchAndBound searches for a set of coins that pays the target
+	// exactly, avoiding a change output.
+	BranchAndBound
+	// RandomImprove picks random coins until the target is covered, then
+	// adds coins that bring the change closer to the payment amount.
+	RandomImprove
+)
+
+// Selection is the result of coin selection.
+type Selection struct {
+	Coins  []Coin
+	Fee    int
+	Change int // Value of the change output, 0 if none is needed
+}
+
+// CoinSelector selects coins to fund a set of outputs.
+type CoinSelector struct {
+	Strategy      Strategy
+	FeeRate       int        // Fee per 1000 bytes
+	DustThreshold int        // Change below this value is added to the fee
+	Rand          *rand.Rand // Randomness for RandomImprove; nil seeds from the clock
+}
+
+// placeholderInput has the size of a signed pay-to-pubkey-hash input.
+var placeholderInput = transactions.TransactionInput{
+	Txid:      make([]byte, 32),
+	Vout:      1 << 30,
+	Signature: make([]byte, 65),
+	PubKey:    make([]byte, 32),
+	Sequence:  transactions.SequenceFinal,
+}
+
+// placeholderChange has the size of a pay-to-pubkey-hash change output.
+var placeholderChange = transactions.TransactionOutput{
+	Value:        1 << 30,
+	ScriptPubKey: transactions.PayToPubKeyHashScript(make([]byte, 32)),
+}
+
+// EstimateSize returns the serialized size of a transaction spending
+// numInputs pay-to-pubkey-hash coins to outputs.
+func EstimateSize(numInputs int, outputs []transactions.TransactionOutput) int {
+	tx := &transactions.Transaction{ID: make([]byte, 32), Vout: outputs}
+	for i := 0; i < numInputs; i++ {
+		tx.Vin = append(tx.Vin, placeholderInput)
+	}
+	return len(tx.Serialize())
+}
+
+// FeeForSize returns the fee for size bytes at feeRate per 1000 bytes,
+// rounded up.
+func FeeForSize(size, feeRate int) int {
+	return (size*feeRate + 999) / 1000
+}
+
+// estimateFee returns the fee of spending numInputs coins to outputs.
+func (cs *CoinSelector) estimateFee(numInputs int, outputs []transactions.TransactionOutput) int {
+	return FeeForSize(EstimateSize(numInputs, outputs), cs.FeeRate)
+}
+
+// Select chooses coins paying outputs plus fees.
+func (cs *CoinSelector) Select(coins []Coin, outputs []transactions.TransactionOutput) (*Selection, error) {
+	target := 0
+	for _, out := range outputs {
+		target += out.Value
+	}
+
+	available := 0
+	for _, coin := range coins {
+		available += coin.Output.Value
+	}
+	if available < target {
+		return nil, ErrInsufficientFunds
+	}
+
+	switch cs.Strategy {
+	case BranchAndBound:
+		return cs.branchAndBound(coins, outputs, target)
+	case RandomImprove:
+		return cs.randomImprove(coins, outputs, target)
+	default:
+		return cs.largestFirst(coins, outputs, target)
+	}
+}
+
+// finish computes the fee and change of spending selected, dropping change
+// below the dust threshold into the fee.
+func (cs *CoinSelector) finish(selected []Coin, outputs []transactions.TransactionOutput, target int) (*Selection, bool) {
+	total := 0
+	for _, coin := range selected {
+		total += coin.Output.Value
+	}
+
+	fee := cs.estimateFee(len(selected), outputs)
+	if total < target+fee {
+		return nil, false
+	}
+
+	withChange := append(append([]transactions.TransactionOutput(nil), outputs...), placeholderChange)
+	feeWithChange := cs.estimateFee(len(selected), withChange)
+	if change := total - target - feeWithChange; change >= cs.DustThreshold && change > 0 {
+		return &Selection{Coins: selected, Fee: feeWithChange, Change: change}, true
+	}
+	return &Selection{Coins: selected, Fee: total - target}, true
+}
+
+func (cs *CoinSelector) largestFirst(coins []Coin, outputs []transactions.TransactionOutput, target int) (*Selection, error) {
+	sorted := append([]Coin(nil), coins...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Output.Value > sorted[j].Output.Value })
+
+	for n := 1; n <= len(sorted); n++ {
+		if selection, ok := cs.finish(sorted[:n], outputs, target); ok {
+			return selection, nil
+		}
+	}
+	return nil, ErrInsufficientFunds
+}
+
+// branchAndBound performs a depth-first search over the coins sorted by value,
+// looking for a subset whose value lies between the target plus fee and that
+// amount plus the cost of creating and later spending a change output.
+func (cs *CoinSelector) branchAndBound(coins []Coin, outputs []transactions.TransactionOutput, target int) (*Selection, error) {
+	sorted := append([]Coin(nil), coins...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Output.Value > sorted[j].Output.Value })
+
+	withChange := append(append([]transactions.TransactionOutput(nil), outputs...), placeholderChange)
+	changeCost := cs.estimateFee(1, withChange) - cs.estimateFee(1, outputs) + cs.estimateFee(1, nil)
+	if changeCost < cs.DustThreshold {
+		changeCost = cs.DustThreshold
+	}
+
+	// remaining[i] is the value of sorted[i:].
+	remaining := make([]int, len(sorted)+1)
+	for i := len(sorted) - 1; i >= 0; i-- {
+		remaining[i] = remaining[i+1] + sorted[i].Output.Value
+	}
+
+	var best []Coin
+	bestWaste := -1
+	tries := 0
+	var selected []Coin
+
+	var search func(depth, total int)
+	search = func(depth, total int) {
+		if tries >= bnbMaxTries {
+			return
+		}
+		tries++
+
+		fee := cs.estimateFee(len(selected), outputs)
+		if total >= target+fee {
+			if waste := total - target - fee; waste <= changeCost && (bestWaste < 0 || waste < bestWaste) {
+				best = append([]Coin(nil), selected...)
+				bestWaste = waste
+			}
+			// Adding more coins only increases the excess.
+			return
+		}
+		if depth == len(sorted) || total+remaining[depth] < target+fee {
+			return
+		}
+
+		selected = append(selected, sorted[depth])
+		search(depth+1, total+sorted[depth].Output.Value)
+		selected = selected[:len(selected)-1]
+		search(depth+1, total)
+	}
+	search(0, 0)
+
+	if best == nil {
+		return nil, ErrNoExactMatch
+	}
+	total := 0
+	for _, coin := range best {
+		total += coin.Output.Value
+	}
+	return &Selection{Coins: best, Fee: total - target}, nil
+}
+
+// randomImprove implements the random-improve algorithm: coins are picked at
+// random until the target is covered, then further random coins are added
+// while they move the selected value closer to twice the target without
+// exceeding three times the target.
+func (cs *CoinSelector) randomImprove(coins []Coin, outputs []transactions.TransactionOutput, target int) (*Selection, error) {
+	rng := cs.Rand
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	shuffled := append([]Coin(nil), coins...)
+	rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+
+	var selected []Coin
+	total, next := 0, 0
+	for ; next < len(shuffled); next++ {
+		if total >= target+cs.estimateFee(len(selected), outputs) {
+			break
+		}
+		selected = append(selected, shuffled[next])
+		total += shuffled[next].Output.Value
+	}
+	if _, ok := cs.finish(selected, outputs, target); !ok {
+		// Random selection ran out of coins; fees may still be covered by
+		// the largest coins.
+		return cs.largestFirst(coins, outputs, target)
+	}
+
+	ideal, limit := 2*target, 3*target
+	abs := func(v int) int {
+		if v < 0 {
+			return -v
+		}
+		return v
+	}
+	for _, coin := range shuffled[next:] {
+		candidate := total + coin.Output.Value
+		if candidate > limit || abs(ideal-candidate) >= abs(ideal-total) {
+			continue
+		}
+		// Skip coins worth less than the fee of spending them.
+		if _, ok := cs.finish(append(selected[:len(selected):len(selected)], coin), outputs, target); ok {
+			selected = append(selected, coin)
+			total = candidate
+		}
+	}
+
+	selection, _ := cs.finish(selected, outputs, target)
+	return selection, nil
+}
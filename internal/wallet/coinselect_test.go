@@ -0,0 +1,113 @@
+package wallet
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCoins(values ...int) []Coin {
+	coins := make([]Coin, len(values))
+	for i, value := range values {
+		coins[i] = Coin{
+			Txid:   []byte{byte(i)},
+			Output: transactions.TransactionOutput{Value: value, ScriptPubKey: "test"},
+		}
+	}
+	return coins
+}
+
+func selectionTotal(s *Selection) int {
+	total := 0
+	for _, coin := range s.Coins {
+		total += coin.Output.Value
+	}
+	return total
+}
+
+func payment(value int) []transactions.TransactionOutput {
+	return []transactions.TransactionOutput{{Value: value, ScriptPubKey: transactions.PayToPubKeyHashScript(make([]byte, 32))}}
+}
+
+func TestFeeGrowsWithInputs(t *testing.T) {
+	outputs := payment(10)
+	assert.Greater(t, EstimateSize(2, outputs), EstimateSize(1, outputs))
+	assert.Equal(t, 1, FeeForSize(1, 1))
+	assert.Equal(t, 2, FeeForSize(1001, 1))
+	assert.Equal(t, 0, FeeForSize(500, 0))
+}
+
+func TestSelectInsufficientFunds(t *testing.T) {
+	for _, strategy := range []Strategy{LargestFirst, BranchAndBound, RandomImprove} {
+		cs := CoinSelector{Strategy: strategy, FeeRate: 1, DustThreshold: 1, Rand: rand.New(rand.NewSource(1))}
+
+		_, err := cs.Select(testCoins(10, 20), payment(31))
+		assert.ErrorIs(t, err, ErrInsufficientFunds)
+
+		// The coins cover the payment but not the fee.
+		_, err = cs.Select(testCoins(10, 20), payment(30))
+		if strategy == BranchAndBound {
+			assert.ErrorIs(t, err, ErrNoExactMatch)
+		} else {
+			assert.ErrorIs(t, err, ErrInsufficientFunds)
+		}
+	}
+}
+
+func TestSelectExactMatch(t *testing.T) {
+	cs := CoinSelector{Strategy: BranchAndBound, FeeRate: 1, DustThreshold: 1}
+	fee := cs.estimateFee(2, payment(0))
+
+	// 7 + 13 pays 20 plus the fee exactly, so no change output is needed.
+	selection, err := cs.Select(testCoins(50, 7, 3, 13+fee, 40), payment(20))
+	require.NoError(t, err)
+	assert.Equal(t, 0, selection.Change)
+	assert.Equal(t, fee, selection.Fee)
+	assert.Equal(t, 20+fee, selectionTotal(selection))
+
+	_, err = cs.Select(testCoins(50, 60), payment(20))
+	assert.ErrorIs(t, err, ErrNoExactMatch)
+}
+
+func TestSelectLargestFirst(t *testing.T) {
+	cs := CoinSelector{Strategy: LargestFirst, FeeRate: 1, DustThreshold: 1}
+
+	selection, err := cs.Select(testCoins(5, 40, 30), payment(60))
+	require.NoError(t, err)
+	require.Len(t, selection.Coins, 2)
+	assert.Equal(t, 40, selection.Coins[0].Output.Value)
+	assert.Equal(t, 30, selection.Coins[1].Output.Value)
+	assert.Equal(t, 70, 60+selection.Fee+selection.Change)
+}
+
+func TestSelectDustChangeGoesToFee(t *testing.T) {
+	cs := CoinSelector{Strategy: LargestFirst, FeeRate: 1, DustThreshold: 5}
+	fee := cs.estimateFee(1, payment(0))
+
+	// Leftover below the dust threshold is paid as fee.
+	selection, err := cs.Select(testCoins(20+fee+3), payment(20))
+	require.NoError(t, err)
+	assert.Equal(t, 0, selection.Change)
+	assert.Equal(t, fee+3, selection.Fee)
+
+	selection, err = cs.Select(testCoins(100), payment(20))
+	require.NoError(t, err)
+	assert.Greater(t, selection.Change, 5)
+	assert.Equal(t, 100, 20+selection.Fee+selection.Change)
+}
+
+func TestSelectRandomImprove(t *testing.T) {
+	cs := CoinSelector{Strategy: RandomImprove, FeeRate: 1, DustThreshold: 1, Rand: rand.New(rand.NewSource(42))}
+	coins := testCoins(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15)
+
+	for i := 0; i < 20; i++ {
+		selection, err := cs.Select(coins, payment(20))
+		require.NoError(t, err)
+		total := selectionTotal(selection)
+		assert.Equal(t, total, 20+selection.Fee+selection.Change)
+		assert.LessOrEqual(t, total, 60)
+	}
+}
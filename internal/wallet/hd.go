@@ -0,0 +1,199 @@
+package wallet
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+
+	"github.com/NicholasRodrigues/go-chain/internal/blockchain"
+	"github.com/NicholasRodrigues/go-chain/internal/mempool"
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+	"github.com/NicholasRodrigues/go-chain/pkg/address"
+	"github.com/NicholasRodrigues/go-chain/pkg/crypto"
+	"github.com/NicholasRodrigues/go-chain/pkg/mnemonic"
+)
+
+const (
+	// CoinType is the SLIP-44 coin type used in derivation paths. go-chain
+	// has no registered coin type, so it uses the value reserved for testnets.
+	CoinType = 1
+	// DefaultGapLimit is the number of consecutive unused addresses after
+	// which discovery stops.
+	DefaultGapLimit = 20
+	// SeedLen is the length of seeds generated by NewRandom.
+	SeedLen = 32
+)
+
+// AddressPath returns the derivation path m/44'/CoinType'/0'/0'/index'.
+func AddressPath(index uint32) crypto.DerivationPath {
+	h := crypto.HardenedKeyStart
+	return crypto.DerivationPath{44 + h, CoinType + h, h, h, index + h}
+}
+
+// NewFromSeed creates a deterministic wallet whose keys are derived from seed.
+func NewFromSeed(chain *blockchain.Blockchain, pool *mempool.Mempool, params *address.Params, seed []byte) (*Wallet, error) {
+	w := New(chain, pool, params)
+	if err := w.setSeed(seed); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// NewRandom creates a deterministic wallet from a fresh random seed.
+func NewRandom(chain *blockchain.Blockchain, pool *mempool.Mempool, params *address.Params) (*Wallet, error) {
+	return NewRandomFromReader(chain, pool, params, rand.Reader)
+}
+
+// NewRandomFromReader creates a deterministic wallet from a seed read from r.
+func NewRandomFromReader(chain *blockchain.Blockchain, pool *mempool.Mempool, params *address.Params, r io.Reader) (*Wallet, error) {
+	seed := make([]byte, SeedLen)
+	if _, err := io.ReadFull(r, seed); err != nil {
+		return nil, err
+	}
+	return NewFromSeed(chain, pool, params, seed)
+}
+
+// NewFromMnemonic creates a deterministic wallet from a mnemonic sentence and
+// optional passphrase.
+func NewFromMnemonic(chain *blockchain.Blockchain, pool *mempool.Mempool, params *address.Params, sentence, passphrase string) (*Wallet, error) {
+	if err := mnemonic.Validate(sentence); err != nil {
+		return nil, err
+	}
+	return NewFromSeed(chain, pool, params, mnemonic.Seed(sentence, passphrase))
+}
+
+func (w *Wallet) setSeed(seed []byte) error {
+	master, err := crypto.NewMasterKey(seed)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.seed = append([]byte(nil), seed...)
+	w.master = master
+	w.nextIndex = 0
+	return nil
+}
+
+// IsDeterministic reports whether the wallet derives its keys from a seed.
+func (w *Wallet) IsDeterministic() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return w.master != nil
+}
+
+// deriveKey returns the key at index in the wallet's address chain.
+func (w *Wallet) deriveKey(index uint32) (*crypto.PrivateKey, error) {
+	w.mu.RLock()
+	master := w.master
+	w.mu.RUnlock()
+
+	if master == nil {
+		return nil, errors.New("wallet: not a deterministic wallet")
+	}
+	node, err := master.Derive(AddressPath(index))
+	if err != nil {
+		return nil, err
+	}
+	return node.PrivateKey(), nil
+}
+
+// nextDerivedKey derives the first key past nextIndex that the wallet has
+// not handed out yet.
+func (w *Wallet) nextDerivedKey() (*crypto.PrivateKey, error) {
+	for {
+		w.mu.Lock()
+		index := w.nextIndex
+		w.nextIndex++
+		w.mu.Unlock()
+
+		key, err := w.deriveKey(index)
+		if err != nil {
+			return nil, err
+		}
+		if _, known := w.keyFor(transactions.PayToPubKeyHashScript(transactions.HashPubKey(key.PublicKey().Bytes()))); !known {
+			return key, nil
+		}
+	}
+}
+
+// peekDerivedKey derives the first key past nextIndex that the wallet has
+// not handed out yet, without handing it out.
+func (w *Wallet) peekDerivedKey() (*crypto.PrivateKey, error) {
+	w.mu.RLock()
+	index := w.nextIndex
+	w.mu.RUnlock()
+
+	for ; ; index++ {
+		key, err := w.deriveKey(index)
+		if err != nil {
+			return nil, err
+		}
+		if _, known := w.keyFor(transactions.PayToPubKeyHashScript(transactions.HashPubKey(key.PublicKey().Bytes()))); !known {
+			return key, nil
+		}
+	}
+}
+
+// usedPubKeyHashes returns every key hash that received an output on chain
+// or in the mempool.
+func (w *Wallet) usedPubKeyHashes() map[string]bool {
+	used := make(map[string]bool)
+	mark := func(txs []*transactions.Transaction) {
+		for _, tx := range txs {
+			for _, out := range tx.Vout {
+				if hash, ok := transactions.ExtractPubKeyHash(out.ScriptPubKey); ok {
+					used[hex.EncodeToString(hash)] = true
+				}
+			}
+		}
+	}
+
+	for _, block := range w.chain.Blocks {
+		mark(block.Transactions)
+	}
+	if w.pool != nil {
+		mark(w.pool.Transactions())
+	}
+	return used
+}
+
+// Discover scans the chain for addresses derived from the wallet's seed,
+// importing every used key until gapLimit consecutive addresses are unused.
+// It returns the number of used addresses found.
+func (w *Wallet) Discover(gapLimit int) (int, error) {
+	if gapLimit < 1 {
+		return 0, errors.New("wallet: gap limit must be positive")
+	}
+
+	used := w.usedPubKeyHashes()
+	found, gap := 0, 0
+	var index uint32
+	for ; gap < gapLimit; index++ {
+		key, err := w.deriveKey(index)
+		if err != nil {
+			return 0, err
+		}
+		if !used[hex.EncodeToString(transactions.HashPubKey(key.PublicKey().Bytes()))] {
+			gap++
+			continue
+		}
+
+		gap = 0
+		found++
+		if _, err := w.ImportKey(key); err != nil {
+			return 0, err
+		}
+
+		w.mu.Lock()
+		if w.nextIndex <= index {
+			w.nextIndex = index + 1
+		}
+		w.mu.Unlock()
+	}
+	return found, nil
+}
@@ -0,0 +1,100 @@
+package wallet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/NicholasRodrigues/go-chain/internal/mempool"
+	"github.com/NicholasRodrigues/go-chain/pkg/address"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSeed = bytes.Repeat([]byte{0x42}, 32)
+
+func TestDeterministicAddresses(t *testing.T) {
+	chain := newChain()
+	first, err := NewFromSeed(chain, nil, &address.MainNetParams, testSeed)
+	require.NoError(t, err)
+	second, err := NewFromSeed(chain, nil, &address.MainNetParams, testSeed)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		a, err := first.NewAddress()
+		require.NoError(t, err)
+		b, err := second.NewAddress()
+		require.NoError(t, err)
+		assert.Equal(t, a.String(), b.String())
+	}
+	assert.True(t, first.IsDeterministic())
+	assert.Equal(t, "m/44'/1'/0'/0'/3'", AddressPath(3).String())
+}
+
+func TestDiscoverWithGapLimit(t *testing.T) {
+	chain := newChain()
+	pool := mempool.New(chain)
+	original, err := NewFromSeed(chain, pool, &address.MainNetParams, testSeed)
+	require.NoError(t, err)
+
+	var addrs []*address.Address
+	for i := 0; i < 6; i++ {
+		addr, err := original.NewAddress()
+		require.NoError(t, err)
+		addrs = append(addrs, addr)
+	}
+
+	// Index 1 stays unused; index 5 lies past a gap of three.
+	mineTo(chain, pool, addrs[0])
+	mineTo(chain, pool, addrs[2])
+	mineTo(chain, pool, addrs[5])
+
+	restored, err := NewFromSeed(chain, pool, &address.MainNetParams, testSeed)
+	require.NoError(t, err)
+	found, err := restored.Discover(2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, found)
+	assert.Equal(t, Balance{Confirmed: 100}, restored.Balance())
+
+	next, err := restored.NewAddress()
+	require.NoError(t, err)
+	assert.Equal(t, addrs[3].String(), next.String())
+
+	found, err = restored.Discover(DefaultGapLimit)
+	require.NoError(t, err)
+	assert.Equal(t, 3, found)
+	assert.Equal(t, Balance{Confirmed: 150}, restored.Balance())
+}
+
+func TestDiscoverRequiresSeed(t *testing.T) {
+	w := New(newChain(), nil, &address.MainNetParams)
+	_, err := w.Discover(DefaultGapLimit)
+	assert.Error(t, err)
+}
+
+func TestRestoreFromMnemonic(t *testing.T) {
+	chain := newChain()
+	pool := mempool.New(chain)
+	sentence := "legal winner thank year wave sausage worth useful legal winner thank yellow"
+
+	original, err := NewFromMnemonic(chain, pool, &address.MainNetParams, sentence, "passphrase")
+	require.NoError(t, err)
+	addr, err := original.NewAddress()
+	require.NoError(t, err)
+	mineTo(chain, pool, addr)
+
+	restored, err := NewFromMnemonic(chain, pool, &address.MainNetParams, sentence, "passphrase")
+	require.NoError(t, err)
+	_, err = restored.Discover(DefaultGapLimit)
+	require.NoError(t, err)
+	assert.Equal(t, Balance{Confirmed: 50}, restored.Balance())
+
+	// A different passphrase yields a different, empty wallet.
+	other, err := NewFromMnemonic(chain, pool, &address.MainNetParams, sentence, "")
+	require.NoError(t, err)
+	_, err = other.Discover(DefaultGapLimit)
+	require.NoError(t, err)
+	assert.Equal(t, Balance{}, other.Balance())
+
+	_, err = NewFromMnemonic(chain, pool, &address.MainNetParams, "legal winner", "")
+	assert.Error(t, err)
+}
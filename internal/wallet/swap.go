@@ -0,0 +1,71 @@
+package wallet
+
+import (
+	"errors"
+
+	"github.com/NicholasRodrigues/go-chain/internal/atomicswap"
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+	"github.com/NicholasRodrigues/go-chain/pkg/address"
+)
+
+// InitiateSwap builds and signs an atomic swap contract locking value for
+// recipient, who can claim it with the preimage of secretHash, and
+// refundable to the wallet once lockTime has passed. The contract is funded
+// by the smallest spendable coin covering value, and the remainder returns to
+// the key owning that coin. The funding transaction is not broadcast.
+func (w *Wallet) InitiateSwap(recipient *address.Address, secretHash []byte, lockTime int64, value int) (*atomicswap.Contract, error) {
+	if recipient.Type != address.PubKeyHash {
+		return nil, errors.New("wallet: swap recipient must be a public key hash address")
+	}
+
+	var funding *Coin
+	for _, coin := range w.SpendableCoins() {
+		if coin.Output.Value >= value && (funding == nil || coin.Output.Value < funding.Output.Value) {
+			coin := coin
+			funding = &coin
+		}
+	}
+	if funding == nil {
+		return nil, ErrInsufficientFunds
+	}
+	key, ok := w.keyFor(funding.Output.ScriptPubKey)
+	if !ok {
+		return nil, errors.New("wallet: no key for the funding coin")
+	}
+
+	return atomicswap.Initiate(atomicswap.Funding{
+		Txid:   funding.Txid,
+		Vout:   funding.Vout,
+		Output: funding.Output,
+		Key:    key,
+	}, recipient.Hash, secretHash, lockTime, value)
+}
+
+// RedeemSwap claims a contract paying to a wallet key by revealing secret.
+// The transaction is not broadcast.
+func (w *Wallet) RedeemSwap(contract *atomicswap.Contract, secret []byte) (*transactions.Transaction, error) {
+	htlc, ok := transactions.ParseHTLCScript(contract.RedeemScript)
+	if !ok {
+		return nil, errors.New("wallet: redeem script is not an htlc")
+	}
+	key, ok := w.keyFor(transactions.PayToPubKeyHashScript(htlc.RecipientPKH))
+	if !ok {
+		return nil, errors.New("wallet: no key for the contract recipient")
+	}
+	return atomicswap.Redeem(contract, key, secret)
+}
+
+// RefundSwap returns a contract initiated by the wallet to the refunding
+// key. The transaction is not broadcast and only becomes final once the
+// contract's lock time has passed.
+func (w *Wallet) RefundSwap(contract *atomicswap.Contract) (*transactions.Transaction, error) {
+	htlc, ok := transactions.ParseHTLCScript(contract.RedeemScript)
+	if !ok {
+		return nil, errors.New("wallet: redeem script is not an htlc")
+	}
+	key, ok := w.keyFor(transactions.PayToPubKeyHashScript(htlc.RefundPKH))
+	if !ok {
+		return nil, errors.New("wallet: no key for the contract refund")
+	}
+	return atomicswap.Refund(contract, key)
+}
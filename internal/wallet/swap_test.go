@@ -0,0 +1,56 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/NicholasRodrigues/go-chain/internal/atomicswap"
+	"github.com/NicholasRodrigues/go-chain/internal/mempool"
+	"github.com/NicholasRodrigues/go-chain/pkg/address"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalletSwap(t *testing.T) {
+	chain := newChain()
+	pool := mempool.New(chain)
+	alice := New(chain, pool, &address.MainNetParams)
+	bob := New(chain, pool, &address.MainNetParams)
+	aliceAddr, err := alice.NewAddress()
+	require.NoError(t, err)
+	bobAddr, err := bob.NewAddress()
+	require.NoError(t, err)
+	mineTo(chain, pool, aliceAddr)
+	mineTo(chain, pool, aliceAddr)
+
+	secret, secretHash, err := atomicswap.NewSecret()
+	require.NoError(t, err)
+	_, err = alice.InitiateSwap(bobAddr, secretHash, 100, 51)
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// Bob claims the first contract with the secret.
+	contract, err := alice.InitiateSwap(bobAddr, secretHash, 100, 30)
+	require.NoError(t, err)
+	require.NoError(t, pool.Add(contract.FundingTx))
+	mineTo(chain, pool, bobAddr)
+	_, err = alice.RedeemSwap(contract, secret)
+	assert.Error(t, err, "alice is not the recipient")
+	redeem, err := bob.RedeemSwap(contract, secret)
+	require.NoError(t, err)
+	require.NoError(t, pool.Add(redeem))
+	mineTo(chain, pool, aliceAddr)
+	assert.Equal(t, 80, bob.Balance().Confirmed)
+
+	// Alice takes the second contract back once its lock time has passed.
+	lockTime := int64(chain.Height() + 2)
+	contract, err = alice.InitiateSwap(bobAddr, secretHash, lockTime, 40)
+	require.NoError(t, err)
+	require.NoError(t, pool.Add(contract.FundingTx))
+	mineTo(chain, pool, bobAddr)
+	_, err = bob.RefundSwap(contract)
+	assert.Error(t, err, "bob cannot refund alice's contract")
+	refund, err := alice.RefundSwap(contract)
+	require.NoError(t, err)
+	assert.ErrorIs(t, pool.Add(refund), mempool.ErrNonFinal)
+	mineTo(chain, pool, bobAddr)
+	require.NoError(t, pool.Add(refund))
+}
@@ -0,0 +1,402 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/NicholasRodrigues/go-chain/internal/blockchain"
+	"github.com/NicholasRodrigues/go-chain/internal/mempool"
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+	"github.com/NicholasRodrigues/go-chain/pkg/address"
+	"github.com/NicholasRodrigues/go-chain/pkg/crypto"
+	"github.com/NicholasRodrigues/go-chain/pkg/keystore"
+)
+
+// ErrInsufficientFunds is returned when the spendable balance cannot cover a payment.
+var ErrInsufficientFunds = errors.New("wallet: insufficient funds")
+
+// Coin is an output owned by the wallet.
+type Coin struct {
+	Txid      []byte
+	Vout      int
+	Output    transactions.TransactionOutput
+	Height    int  // Height of the block that created the output, -1 if unconfirmed
+	Confirmed bool // Whether the output is part of the chain's UTXO set
+	Immature  bool // Whether the output is a coinbase not yet spendable in the next block
+}
+
+// Balance summarises the value of the wallet's coins.
+type Balance struct {
+	Confirmed   int // Mature confirmed coins not spent by pool transactions
+	Immature    int // Coinbase outputs not yet CoinbaseMaturity blocks deep
+	Unconfirmed int // Coins created by pool transactions
+}
+
+// Wallet holds private keys and tracks the outputs paying to them.
+type Wallet struct {
+	mu     sync.RWMutex
+	params *address.Params
+	chain  *blockchain.Blockchain
+	pool   *mempool.Mempool
+	keys   []*crypto.PrivateKey
+	byHash map[string]*crypto.PrivateKey // hex pubkey hash -> key
+
+	// Deterministic wallets derive new keys from seed.
+	seed      []byte
+	master    *crypto.ExtendedKey
+	nextIndex uint32
+
+	selector CoinSelector
+	entropy  io.Reader // Source of random keys; nil means crypto/rand
+}
+
+// New creates an empty wallet tracking chain and broadcasting to pool.
+func New(chain *blockchain.Blockchain, pool *mempool.Mempool, params *address.Params) *Wallet {
+	return &Wallet{
+		params: params,
+		chain:  chain,
+		pool:   pool,
+		byHash: make(map[string]*crypto.PrivateKey),
+		selector: CoinSelector{
+			Strategy:      BranchAndBound,
+			FeeRate:       DefaultFeeRate,
+			DustThreshold: DefaultDustThreshold,
+		},
+	}
+}
+
+// NewAddress returns the address of a new key. Deterministic wallets derive
+// the next key from their seed; others generate a random key.
+func (w *Wallet) NewAddress() (*address.Address, error) {
+	var key *crypto.PrivateKey
+	var err error
+	if w.IsDeterministic() {
+		key, err = w.nextDerivedKey()
+	} else {
+		key, err = w.newRandomKey()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return w.ImportKey(key)
+}
+
+// SetEntropy makes the wallet generate random keys from r instead of
+// crypto/rand, so tests can reproduce them. A nil r restores crypto/rand.
+func (w *Wallet) SetEntropy(r io.Reader) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.entropy = r
+}
+
+func (w *Wallet) newRandomKey() (*crypto.PrivateKey, error) {
+	w.mu.RLock()
+	r := w.entropy
+	w.mu.RUnlock()
+	if r == nil {
+		return crypto.NewPrivateKey()
+	}
+	return crypto.NewPrivateKeyFromReader(r)
+}
+
+// ImportKey adds an existing key to the wallet and returns its address.
+func (w *Wallet) ImportKey(key *crypto.PrivateKey) (*address.Address, error) {
+	pubKeyHash := transactions.HashPubKey(key.PublicKey().Bytes())
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.byHash[hex.EncodeToString(pubKeyHash)]; !ok {
+		w.keys = append(w.keys, key)
+		w.byHash[hex.EncodeToString(pubKeyHash)] = key
+	}
+	return address.NewPubKeyHash(pubKeyHash, w.params)
+}
+
+// Keys returns the wallet's private keys in the order they were added.
+func (w *Wallet) Keys() []*crypto.PrivateKey {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return append([]*crypto.PrivateKey(nil), w.keys...)
+}
+
+// Addresses returns the addresses of the wallet's keys.
+func (w *Wallet) Addresses() []*address.Address {
+	var addrs []*address.Address
+	for _, key := range w.Keys() {
+		addr, _ := address.NewPubKeyHash(transactions.HashPubKey(key.PublicKey().Bytes()), w.params)
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+// SaveKeystore writes the wallet's keys and seed to an encrypted keystore file.
+func (w *Wallet) SaveKeystore(path string, password []byte) error {
+	w.mu.RLock()
+	seed := w.seed
+	w.mu.RUnlock()
+
+	return keystore.Save(path, &keystore.Secrets{Keys: w.Keys(), Seed: seed}, password)
+}
+
+// LoadKeystore imports the keys of an encrypted keystore file. A stored seed
+// makes the wallet deterministic if it is not already.
+func (w *Wallet) LoadKeystore(path string, password []byte) error {
+	secrets, err := keystore.Load(path, password)
+	if err != nil {
+		return err
+	}
+	if len(secrets.Seed) > 0 && !w.IsDeterministic() {
+		if err := w.setSeed(secrets.Seed); err != nil {
+			return err
+		}
+	}
+	for _, key := range secrets.Keys {
+		if _, err := w.ImportKey(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keyFor returns the wallet key that can spend script, if any.
+func (w *Wallet) keyFor(script string) (*crypto.PrivateKey, bool) {
+	pubKeyHash, ok := transactions.ExtractPubKeyHash(script)
+	if !ok {
+		return nil, false
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	key, ok := w.byHash[hex.EncodeToString(pubKeyHash)]
+	return key, ok
+}
+
+// poolSpends returns the outpoints spent by pool transactions.
+func (w *Wallet) poolSpends() map[string]bool {
+	spent := make(map[string]bool)
+	if w.pool == nil {
+		return spent
+	}
+	for _, tx := range w.pool.Transactions() {
+		for _, in := range tx.Vin {
+			spent[transactions.UTXOKey(in.Txid, in.Vout)] = true
+		}
+	}
+	return spent
+}
+
+// Coins returns the confirmed wallet outputs that are not spent by a pool
+// transaction, followed by the wallet outputs created by pool transactions.
+func (w *Wallet) Coins() []Coin {
+	spent := w.poolSpends()
+	next := w.chain.Height() + 1
+
+	var coins []Coin
+	for key, entry := range w.chain.UTXOSet() {
+		if spent[key] {
+			continue
+		}
+		if _, ok := w.keyFor(entry.Output.ScriptPubKey); ok {
+			coins = append(coins, Coin{
+				Txid:      entry.Txid,
+				Vout:      entry.Vout,
+				Output:    entry.Output,
+				Height:    entry.Height,
+				Confirmed: true,
+				Immature:  !w.chain.IsMature(entry, next),
+			})
+		}
+	}
+
+	sort.Slice(coins, func(i, j int) bool {
+		if coins[i].Height != coins[j].Height {
+			return coins[i].Height < coins[j].Height
+		}
+		return transactions.UTXOKey(coins[i].Txid, coins[i].Vout) < transactions.UTXOKey(coins[j].Txid, coins[j].Vout)
+	})
+
+	if w.pool != nil {
+		for _, tx := range w.pool.Transactions() {
+			for i, out := range tx.Vout {
+				if _, ok := w.keyFor(out.ScriptPubKey); ok && !spent[transactions.UTXOKey(tx.ID, i)] {
+					coins = append(coins, Coin{Txid: tx.ID, Vout: i, Output: out, Height: -1})
+				}
+			}
+		}
+	}
+	return coins
+}
+
+// SpendableCoins returns the confirmed, mature coins the wallet can spend.
+func (w *Wallet) SpendableCoins() []Coin {
+	var coins []Coin
+	for _, coin := range w.Coins() {
+		if coin.Confirmed && !coin.Immature {
+			coins = append(coins, coin)
+		}
+	}
+	return coins
+}
+
+// Balance returns the confirmed, immature and unconfirmed balance of the
+// wallet.
+func (w *Wallet) Balance() Balance {
+	var balance Balance
+	for _, coin := range w.Coins() {
+		switch {
+		case coin.Immature:
+			balance.Immature += coin.Output.Value
+		case coin.Confirmed:
+			balance.Confirmed += coin.Output.Value
+		default:
+			balance.Unconfirmed += coin.Output.Value
+		}
+	}
+	return balance
+}
+
+// CreatePayment builds and signs a transaction paying amount to addr. Coins
+// are chosen by the wallet's coin selector, and any change above the dust
+// threshold is sent to a new wallet address.
+func (w *Wallet) CreatePayment(addr *address.Address, amount int) (*transactions.Transaction, error) {
+	tx, change, err := w.createPayment(addr, amount)
+	if err != nil {
+		return nil, err
+	}
+	if change != nil {
+		if _, err := w.ImportKey(change); err != nil {
+			return nil, err
+		}
+	}
+	return tx, nil
+}
+
+// createPayment builds and signs a payment like CreatePayment, but leaves
+// the change key, nil if there is no change, for the caller to add to the
+// wallet once the payment is used.
+func (w *Wallet) createPayment(addr *address.Address, amount int) (*transactions.Transaction, *crypto.PrivateKey, error) {
+	if amount <= 0 {
+		return nil, nil, fmt.Errorf("wallet: invalid amount %d", amount)
+	}
+
+	outputs := []transactions.TransactionOutput{{Value: amount, ScriptPubKey: transactions.PayToAddrScript(addr)}}
+	selection, err := w.selectCoins(w.SpendableCoins(), outputs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var change *crypto.PrivateKey
+	if selection.Change > 0 {
+		if w.IsDeterministic() {
+			change, err = w.peekDerivedKey()
+		} else {
+			change, err = w.newRandomKey()
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		changeScript := transactions.PayToPubKeyHashScript(transactions.HashPubKey(change.PublicKey().Bytes()))
+		outputs = append(outputs, transactions.TransactionOutput{Value: selection.Change, ScriptPubKey: changeScript})
+	}
+
+	tx, err := w.signCoins(selection.Coins, outputs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tx, change, nil
+}
+
+// SetCoinSelector replaces the coin selector used for payments.
+func (w *Wallet) SetCoinSelector(cs CoinSelector) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.selector = cs
+}
+
+// selectCoins runs the wallet's coin selector. When branch and bound finds no
+// changeless solution it falls back to largest-first.
+func (w *Wallet) selectCoins(coins []Coin, outputs []transactions.TransactionOutput) (*Selection, error) {
+	w.mu.RLock()
+	cs := w.selector
+	w.mu.RUnlock()
+
+	selection, err := cs.Select(coins, outputs)
+	if errors.Is(err, ErrNoExactMatch) {
+		cs.Strategy = LargestFirst
+		selection, err = cs.Select(coins, outputs)
+	}
+	return selection, err
+}
+
+// signCoins builds a transaction spending coins to outputs and signs each
+// input with the key owning it.
+func (w *Wallet) signCoins(coins []Coin, outputs []transactions.TransactionOutput) (*transactions.Transaction, error) {
+	var inputs []transactions.TransactionInput
+	for _, coin := range coins {
+		inputs = append(inputs, transactions.TransactionInput{Txid: coin.Txid, Vout: coin.Vout})
+	}
+
+	tx := transactions.NewTransaction(inputs, outputs)
+	for i, coin := range coins {
+		key, ok := w.keyFor(coin.Output.ScriptPubKey)
+		if !ok {
+			return nil, fmt.Errorf("wallet: no key for output %s", transactions.UTXOKey(coin.Txid, coin.Vout))
+		}
+		if err := tx.SignInput(i, key, coin.Output); err != nil {
+			return nil, err
+		}
+	}
+	return tx, nil
+}
+
+// SignTransaction signs every input of tx that spends a confirmed output
+// owned by the wallet and returns the number of inputs signed. Inputs the
+// wallet cannot sign are left untouched.
+func (w *Wallet) SignTransaction(tx *transactions.Transaction) (int, error) {
+	utxos := w.chain.UTXOSet()
+	signed := 0
+	for i, in := range tx.Vin {
+		entry, ok := utxos[transactions.UTXOKey(in.Txid, in.Vout)]
+		if !ok {
+			continue
+		}
+		key, ok := w.keyFor(entry.Output.ScriptPubKey)
+		if !ok {
+			continue
+		}
+		if err := tx.SignInput(i, key, entry.Output); err != nil {
+			return signed, err
+		}
+		signed++
+	}
+	return signed, nil
+}
+
+// Send creates a payment and broadcasts it to the local mempool. The change
+// address is only added to the wallet once the mempool accepts the payment.
+func (w *Wallet) Send(addr *address.Address, amount int) (*transactions.Transaction, error) {
+	if w.pool == nil {
+		return nil, errors.New("wallet: no mempool to broadcast to")
+	}
+
+	tx, change, err := w.createPayment(addr, amount)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.pool.Add(tx); err != nil {
+		return nil, err
+	}
+	if change != nil {
+		if _, err := w.ImportKey(change); err != nil {
+			return nil, err
+		}
+	}
+	return tx, nil
+}
@@ -0,0 +1,206 @@
+package wallet
+
+import (
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/NicholasRodrigues/go-chain/internal/blockchain"
+	"github.com/NicholasRodrigues/go-chain/internal/mempool"
+	"github.com/NicholasRodrigues/go-chain/internal/transactions"
+	"github.com/NicholasRodrigues/go-chain/pkg/address"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newChain returns a chain whose coinbase outputs can be spent right away.
+func newChain() *blockchain.Blockchain {
+	params := blockchain.MainNetParams
+	params.CoinbaseMaturity = 0
+	return blockchain.NewBlockchainWithParams(&params)
+}
+
+// mineTo mines a block paying a 50 coin coinbase to addr along with the pool's transactions.
+func mineTo(chain *blockchain.Blockchain, pool *mempool.Mempool, addr *address.Address) {
+	coinbase := transactions.NewCoinbaseTransaction(transactions.PayToAddrScript(addr), addr.String(), 50)
+	chain.AddBlock(append([]*transactions.Transaction{coinbase}, pool.Transactions()...))
+	pool.RemoveBlock(chain.Blocks[len(chain.Blocks)-1])
+}
+
+func TestWalletAddresses(t *testing.T) {
+	w := New(newChain(), nil, &address.MainNetParams)
+
+	first, err := w.NewAddress()
+	require.NoError(t, err)
+	second, err := w.NewAddress()
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first.String(), second.String())
+	assert.Len(t, w.Keys(), 2)
+
+	// Importing a known key does not duplicate it.
+	_, err = w.ImportKey(w.Keys()[0])
+	require.NoError(t, err)
+	assert.Len(t, w.Addresses(), 2)
+	assert.Equal(t, first.String(), w.Addresses()[0].String())
+}
+
+func TestWalletEntropy(t *testing.T) {
+	newAddress := func(seed int64) string {
+		w := New(newChain(), nil, &address.MainNetParams)
+		w.SetEntropy(rand.New(rand.NewSource(seed)))
+		addr, err := w.NewAddress()
+		require.NoError(t, err)
+		return addr.String()
+	}
+	assert.Equal(t, newAddress(1), newAddress(1))
+	assert.NotEqual(t, newAddress(1), newAddress(2))
+
+	a, err := NewRandomFromReader(newChain(), nil, &address.MainNetParams, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+	b, err := NewRandomFromReader(newChain(), nil, &address.MainNetParams, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+	addrA, err := a.NewAddress()
+	require.NoError(t, err)
+	addrB, err := b.NewAddress()
+	require.NoError(t, err)
+	assert.Equal(t, addrA.String(), addrB.String())
+}
+
+func TestWalletBalanceAndSend(t *testing.T) {
+	chain := newChain()
+	pool := mempool.New(chain)
+	alice := New(chain, pool, &address.MainNetParams)
+	bob := New(chain, pool, &address.MainNetParams)
+
+	aliceAddr, err := alice.NewAddress()
+	require.NoError(t, err)
+	bobAddr, err := bob.NewAddress()
+	require.NoError(t, err)
+
+	mineTo(chain, pool, aliceAddr)
+	assert.Equal(t, Balance{Confirmed: 50}, alice.Balance())
+
+	tx, err := alice.Send(bobAddr, 20)
+	require.NoError(t, err)
+	assert.Equal(t, 1, pool.Count())
+
+	// The change is what remains after the payment and fee.
+	require.Len(t, tx.Vout, 2)
+	change := tx.Vout[1].Value
+	assert.Greater(t, change, 0)
+	assert.Less(t, change, 30)
+
+	// The payment and change are unconfirmed until mined.
+	assert.Equal(t, Balance{Unconfirmed: change}, alice.Balance())
+	assert.Equal(t, Balance{Unconfirmed: 20}, bob.Balance())
+
+	mineTo(chain, pool, bobAddr)
+	_, pending := pool.Get(tx.ID)
+	assert.False(t, pending)
+	assert.Equal(t, Balance{Confirmed: change}, alice.Balance())
+	assert.Equal(t, Balance{Confirmed: 70}, bob.Balance())
+}
+
+func TestWalletRejectedPaymentKeepsChangeKey(t *testing.T) {
+	chain := newChain()
+	w, err := NewFromSeed(chain, mempool.New(newChain()), &address.MainNetParams, testSeed)
+	require.NoError(t, err)
+	addr, err := w.NewAddress()
+	require.NoError(t, err)
+	mineTo(chain, mempool.New(chain), addr)
+
+	// The pool follows another chain, so it rejects the payment's input.
+	_, err = w.Send(addr, 20)
+	require.Error(t, err)
+	assert.Len(t, w.Addresses(), 1, "no change address for a rejected payment")
+
+	w.pool = mempool.New(chain)
+	tx, err := w.Send(addr, 20)
+	require.NoError(t, err)
+	require.Len(t, tx.Vout, 2)
+	require.Len(t, w.Addresses(), 2)
+	assert.Equal(t, tx.Vout[1].ScriptPubKey, transactions.PayToAddrScript(w.Addresses()[1]))
+	next, err := w.NewAddress()
+	require.NoError(t, err)
+	assert.NotEqual(t, w.Addresses()[1].String(), next.String())
+}
+
+func TestWalletImmatureCoinbase(t *testing.T) {
+	params := blockchain.MainNetParams
+	params.CoinbaseMaturity = 2
+	chain := blockchain.NewBlockchainWithParams(&params)
+	pool := mempool.New(chain)
+	w := New(chain, pool, &address.MainNetParams)
+	addr, err := w.NewAddress()
+	require.NoError(t, err)
+
+	// The coinbase at height 1 is spendable in the block at height 3.
+	mineTo(chain, pool, addr)
+	assert.Equal(t, Balance{Immature: 50}, w.Balance())
+	assert.Empty(t, w.SpendableCoins())
+	_, err = w.Send(addr, 10)
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+
+	next, err := w.NewAddress()
+	require.NoError(t, err)
+	mineTo(chain, pool, next)
+	assert.Equal(t, Balance{Confirmed: 50, Immature: 50}, w.Balance())
+	coins := w.SpendableCoins()
+	require.Len(t, coins, 1)
+	assert.Equal(t, 1, coins[0].Height)
+	_, err = w.Send(addr, 10)
+	assert.NoError(t, err)
+}
+
+func TestWalletInsufficientFunds(t *testing.T) {
+	chain := newChain()
+	pool := mempool.New(chain)
+	w := New(chain, pool, &address.MainNetParams)
+	addr, err := w.NewAddress()
+	require.NoError(t, err)
+
+	mineTo(chain, pool, addr)
+
+	_, err = w.Send(addr, 51)
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = w.Send(addr, 0)
+	assert.Error(t, err)
+}
+
+func TestWalletKeystoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wallet.json")
+	w := New(newChain(), nil, &address.MainNetParams)
+	for i := 0; i < 2; i++ {
+		_, err := w.NewAddress()
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.SaveKeystore(path, []byte("password")))
+
+	restored := New(w.chain, nil, &address.MainNetParams)
+	assert.Error(t, restored.LoadKeystore(path, []byte("wrong")))
+	require.NoError(t, restored.LoadKeystore(path, []byte("password")))
+
+	assert.Equal(t, w.Addresses()[1].String(), restored.Addresses()[1].String())
+}
+
+func TestWalletSignTransaction(t *testing.T) {
+	chain := newChain()
+	pool := mempool.New(chain)
+	w := New(chain, pool, &address.MainNetParams)
+	addr, err := w.NewAddress()
+	require.NoError(t, err)
+	mineTo(chain, pool, addr)
+
+	coinbase := chain.Blocks[1].Transactions[0]
+	tx := transactions.NewTransaction(
+		[]transactions.TransactionInput{{Txid: coinbase.ID, Vout: 0}, {Txid: []byte("unknown"), Vout: 0}},
+		[]transactions.TransactionOutput{{Value: 50, ScriptPubKey: transactions.PayToAddrScript(addr)}},
+	)
+	signed, err := w.SignTransaction(tx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, signed)
+	assert.NotEmpty(t, tx.Vin[0].Signature)
+	assert.Empty(t, tx.Vin[1].Signature)
+}
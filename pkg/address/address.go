@@ -0,0 +1,159 @@
+package address
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// HashLen is the length of the key and script hashes an address commits to.
+const HashLen = 32
+
+// ErrWrongNetwork is returned when an address belongs to a different network.
+var ErrWrongNetwork = errors.New("address: wrong network")
+
+// Type identifies what kind of hash an address commits to.
+type Type byte
+
+const (
+	// PubKeyHash addresses pay to the hash of a public key.
+	PubKeyHash Type = iota
+	// ScriptHash addresses pay to the hash of a redeem script.
+	ScriptHash
+)
+
+// String returns a short name for the address type.
+func (t Type) String() string {
+	switch t {
+	case PubKeyHash:
+		return "pubkeyhash"
+	case ScriptHash:
+		return "scripthash"
+	default:
+		return fmt.Sprintf("unknown(%d)", byte(t))
+	}
+}
+
+// Params holds the network specific prefixes used when encoding addresses.
+type Params struct {
+	Name              string
+	PubKeyHashVersion byte
+	ScriptHashVersion byte
+	HRP               string
+}
+
+var (
+	// MainNetParams are the address prefixes of the main network.
+	MainNetParams = Params{Name: "mainnet", PubKeyHashVersion: 0x26, ScriptHashVersion: 0x3f, HRP: "gc"}
+	// TestNetParams are the address prefixes of the test network.
+	TestNetParams = Params{Name: "testnet", PubKeyHashVersion: 0x6f, ScriptHashVersion: 0xc4, HRP: "tgc"}
+	// RegTestParams are the address prefixes of regression test networks.
+	// Base58 addresses share the test network's versions.
+	RegTestParams = Params{Name: "regtest", PubKeyHashVersion: 0x6f, ScriptHashVersion: 0xc4, HRP: "gcrt"}
+)
+
+// Address is a key hash or script hash bound to a network.
+type Address struct {
+	Type   Type
+	Hash   []byte
+	Params *Params
+}
+
+// NewPubKeyHash returns an address paying to the given public key hash.
+func NewPubKeyHash(hash []byte, params *Params) (*Address, error) {
+	return newAddress(PubKeyHash, hash, params)
+}
+
+// NewScriptHash returns an address paying to the given script hash.
+func NewScriptHash(hash []byte, params *Params) (*Address, error) {
+	return newAddress(ScriptHash, hash, params)
+}
+
+func newAddress(t Type, hash []byte, params *Params) (*Address, error) {
+	if len(hash) != HashLen {
+		return nil, fmt.Errorf("address: invalid hash length %d", len(hash))
+	}
+	h := make([]byte, HashLen)
+	copy(h, hash)
+	return &Address{Type: t, Hash: h, Params: params}, nil
+}
+
+// version returns the base58 version byte for the address type.
+func (a *Address) version() byte {
+	if a.Type == ScriptHash {
+		return a.Params.ScriptHashVersion
+	}
+	return a.Params.PubKeyHashVersion
+}
+
+// EncodeBase58 returns the Base58Check encoding of the address.
+func (a *Address) EncodeBase58() string {
+	return CheckEncode(a.version(), a.Hash)
+}
+
+// EncodeBech32 returns the bech32 encoding of the address. The first data
+// group carries the address type.
+func (a *Address) EncodeBech32() string {
+	groups, _ := convertBits(a.Hash, 8, 5, true)
+	s, _ := Bech32Encode(a.Params.HRP, append([]byte{byte(a.Type)}, groups...))
+	return s
+}
+
+// String returns the bech32 encoding, which is the default display format.
+func (a *Address) String() string {
+	return a.EncodeBech32()
+}
+
+// DecodeBase58 parses a Base58Check address for the given network.
+func DecodeBase58(s string, params *Params) (*Address, error) {
+	version, payload, err := CheckDecode(s)
+	if err != nil {
+		return nil, err
+	}
+
+	switch version {
+	case params.PubKeyHashVersion:
+		return NewPubKeyHash(payload, params)
+	case params.ScriptHashVersion:
+		return NewScriptHash(payload, params)
+	default:
+		return nil, ErrWrongNetwork
+	}
+}
+
+// DecodeBech32 parses a bech32 address for the given network.
+func DecodeBech32(s string, params *Params) (*Address, error) {
+	hrp, data, err := Bech32Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if hrp != params.HRP {
+		return nil, ErrWrongNetwork
+	}
+	if len(data) < 1 {
+		return nil, ErrInvalidFormat
+	}
+
+	hash, err := convertBits(data[1:], 5, 8, false)
+	if err != nil {
+		return nil, err
+	}
+
+	switch Type(data[0]) {
+	case PubKeyHash:
+		return NewPubKeyHash(hash, params)
+	case ScriptHash:
+		return NewScriptHash(hash, params)
+	default:
+		return nil, fmt.Errorf("%w: unknown address type %d", ErrInvalidFormat, data[0])
+	}
+}
+
+// Decode parses an address in either encoding, telling them apart by the
+// network's bech32 prefix.
+func Decode(s string, params *Params) (*Address, error) {
+	if strings.HasPrefix(strings.ToLower(s), params.HRP+"1") {
+		return DecodeBech32(s, params)
+	}
+	return DecodeBase58(s, params)
+}
@@ -0,0 +1,79 @@
+package address
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testHash() []byte {
+	h := sha256.Sum256([]byte("public key"))
+	return h[:]
+}
+
+func TestAddressRoundTrip(t *testing.T) {
+	for _, newAddr := range []func([]byte, *Params) (*Address, error){NewPubKeyHash, NewScriptHash} {
+		addr, err := newAddr(testHash(), &MainNetParams)
+		assert.NoError(t, err)
+
+		fromBase58, err := DecodeBase58(addr.EncodeBase58(), &MainNetParams)
+		assert.NoError(t, err)
+		assert.Equal(t, addr.Type, fromBase58.Type)
+		assert.Equal(t, addr.Hash, fromBase58.Hash)
+
+		fromBech32, err := DecodeBech32(addr.EncodeBech32(), &MainNetParams)
+		assert.NoError(t, err)
+		assert.Equal(t, addr.Type, fromBech32.Type)
+		assert.Equal(t, addr.Hash, fromBech32.Hash)
+	}
+}
+
+func TestDecodeDetectsEncoding(t *testing.T) {
+	addr, err := NewPubKeyHash(testHash(), &TestNetParams)
+	assert.NoError(t, err)
+
+	for _, s := range []string{addr.EncodeBase58(), addr.EncodeBech32()} {
+		decoded, err := Decode(s, &TestNetParams)
+		assert.NoError(t, err)
+		assert.True(t, bytes.Equal(addr.Hash, decoded.Hash))
+	}
+}
+
+func TestDecodeCatchesTypos(t *testing.T) {
+	addr, err := NewPubKeyHash(testHash(), &MainNetParams)
+	assert.NoError(t, err)
+
+	typo := func(s string, i int) string {
+		b := []byte(s)
+		if b[i] == 'q' {
+			b[i] = 'p'
+		} else {
+			b[i] = 'q'
+		}
+		return string(b)
+	}
+
+	_, err = Decode(typo(addr.EncodeBech32(), 10), &MainNetParams)
+	assert.ErrorIs(t, err, ErrChecksum)
+
+	_, err = Decode(typo(addr.EncodeBase58(), 10), &MainNetParams)
+	assert.ErrorIs(t, err, ErrChecksum)
+}
+
+func TestDecodeWrongNetwork(t *testing.T) {
+	addr, err := NewPubKeyHash(testHash(), &TestNetParams)
+	assert.NoError(t, err)
+
+	_, err = DecodeBase58(addr.EncodeBase58(), &MainNetParams)
+	assert.ErrorIs(t, err, ErrWrongNetwork)
+
+	_, err = DecodeBech32(addr.EncodeBech32(), &MainNetParams)
+	assert.ErrorIs(t, err, ErrWrongNetwork)
+}
+
+func TestNewAddressRejectsBadHashLength(t *testing.T) {
+	_, err := NewPubKeyHash([]byte{1, 2, 3}, &MainNetParams)
+	assert.Error(t, err)
+}
@@ -0,0 +1,110 @@
+package address
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var (
+	// ErrChecksum is returned when an encoded address fails its checksum.
+	ErrChecksum = errors.New("address: checksum mismatch")
+	// ErrInvalidFormat is returned when a string is not a well-formed encoding.
+	ErrInvalidFormat = errors.New("address: invalid format")
+)
+
+var base58Index = func() [256]int {
+	var idx [256]int
+	for i := range idx {
+		idx[i] = -1
+	}
+	for i := 0; i < len(base58Alphabet); i++ {
+		idx[base58Alphabet[i]] = i
+	}
+	return idx
+}()
+
+// Base58Encode encodes data using the Bitcoin base58 alphabet.
+func Base58Encode(data []byte) string {
+	num := new(big.Int).SetBytes(data)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+
+	var out []byte
+	for num.Sign() > 0 {
+		num.DivMod(num, radix, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+
+	// Leading zero bytes are encoded as leading '1' characters.
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+		out = append(out, base58Alphabet[0])
+	}
+
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+// Base58Decode decodes a base58 string back into bytes.
+func Base58Decode(s string) ([]byte, error) {
+	num := new(big.Int)
+	radix := big.NewInt(58)
+
+	for i := 0; i < len(s); i++ {
+		digit := base58Index[s[i]]
+		if digit < 0 {
+			return nil, ErrInvalidFormat
+		}
+		num.Mul(num, radix)
+		num.Add(num, big.NewInt(int64(digit)))
+	}
+
+	zeros := 0
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+
+	return append(make([]byte, zeros), num.Bytes()...), nil
+}
+
+// checksum returns the first four bytes of the double SHA-256 of data.
+func checksum(data []byte) []byte {
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	return second[:4]
+}
+
+// CheckEncode prepends version to payload, appends a four byte checksum and
+// encodes the result in base58.
+func CheckEncode(version byte, payload []byte) string {
+	data := make([]byte, 0, 1+len(payload)+4)
+	data = append(data, version)
+	data = append(data, payload...)
+	data = append(data, checksum(data)...)
+	return Base58Encode(data)
+}
+
+// CheckDecode reverses CheckEncode, verifying the checksum.
+func CheckDecode(s string) (byte, []byte, error) {
+	data, err := Base58Decode(s)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(data) < 5 {
+		return 0, nil, ErrInvalidFormat
+	}
+
+	body, sum := data[:len(data)-4], data[len(data)-4:]
+	if !bytes.Equal(checksum(body), sum) {
+		return 0, nil, ErrChecksum
+	}
+	return body[0], body[1:], nil
+}
@@ -0,0 +1,58 @@
+package address
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBase58Vectors(t *testing.T) {
+	vectors := []struct {
+		hex     string
+		encoded string
+	}{
+		{"", ""},
+		{"61", "2g"},
+		{"626262", "a3gV"},
+		{"636363", "aPEr"},
+		{"00000000000000000000", "1111111111"},
+	}
+
+	for _, v := range vectors {
+		data, _ := hex.DecodeString(v.hex)
+		assert.Equal(t, v.encoded, Base58Encode(data))
+
+		decoded, err := Base58Decode(v.encoded)
+		assert.NoError(t, err)
+		assert.Equal(t, hex.EncodeToString(data), hex.EncodeToString(decoded))
+	}
+}
+
+func TestBase58DecodeInvalidCharacter(t *testing.T) {
+	_, err := Base58Decode("0OIl")
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+}
+
+func TestCheckEncodeDecode(t *testing.T) {
+	payload := []byte("a payload of some length")
+	encoded := CheckEncode(0x26, payload)
+
+	version, decoded, err := CheckDecode(encoded)
+	assert.NoError(t, err)
+	assert.Equal(t, byte(0x26), version)
+	assert.Equal(t, payload, decoded)
+}
+
+func TestCheckDecodeBadChecksum(t *testing.T) {
+	encoded := []byte(CheckEncode(0x26, []byte("payload")))
+	// Swap one character for a different valid one.
+	if encoded[5] == 'z' {
+		encoded[5] = 'y'
+	} else {
+		encoded[5] = 'z'
+	}
+
+	_, _, err := CheckDecode(string(encoded))
+	assert.ErrorIs(t, err, ErrChecksum)
+}
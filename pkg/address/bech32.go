@@ -0,0 +1,135 @@
+package address
+
+import (
+	"fmt"
+	"strings"
+)
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+var bech32Generator = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+// polymod computes the BCH checksum used by bech32.
+func polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= bech32Generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+// hrpExpand expands the human-readable part for checksum computation.
+func hrpExpand(hrp string) []byte {
+	out := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+func bech32Checksum(hrp string, data []byte) []byte {
+	values := append(hrpExpand(hrp), data...)
+	values = append(values, 0, 0, 0, 0, 0, 0)
+	mod := polymod(values) ^ 1
+
+	out := make([]byte, 6)
+	for i := range out {
+		out[i] = byte((mod >> uint(5*(5-i))) & 31)
+	}
+	return out
+}
+
+// Bech32Encode encodes 5-bit groups in data with the given human-readable part.
+func Bech32Encode(hrp string, data []byte) (string, error) {
+	for _, v := range data {
+		if v >= 32 {
+			return "", fmt.Errorf("%w: data value out of range", ErrInvalidFormat)
+		}
+	}
+
+	hrp = strings.ToLower(hrp)
+	var sb strings.Builder
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, v := range append(data, bech32Checksum(hrp, data)...) {
+		sb.WriteByte(bech32Charset[v])
+	}
+	return sb.String(), nil
+}
+
+// Bech32Decode splits a bech32 string into its human-readable part and its
+// 5-bit data groups, verifying the checksum.
+func Bech32Decode(s string) (string, []byte, error) {
+	if len(s) > 90 {
+		return "", nil, fmt.Errorf("%w: bech32 string too long", ErrInvalidFormat)
+	}
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, fmt.Errorf("%w: mixed case", ErrInvalidFormat)
+	}
+	s = strings.ToLower(s)
+
+	pos := strings.LastIndexByte(s, '1')
+	if pos < 1 || pos+7 > len(s) {
+		return "", nil, fmt.Errorf("%w: bad separator position", ErrInvalidFormat)
+	}
+
+	hrp := s[:pos]
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return "", nil, fmt.Errorf("%w: invalid character in prefix", ErrInvalidFormat)
+		}
+	}
+
+	data := make([]byte, 0, len(s)-pos-1)
+	for i := pos + 1; i < len(s); i++ {
+		idx := strings.IndexByte(bech32Charset, s[i])
+		if idx < 0 {
+			return "", nil, fmt.Errorf("%w: invalid character %q", ErrInvalidFormat, s[i])
+		}
+		data = append(data, byte(idx))
+	}
+
+	if polymod(append(hrpExpand(hrp), data...)) != 1 {
+		return "", nil, ErrChecksum
+	}
+	return hrp, data[:len(data)-6], nil
+}
+
+// convertBits regroups data from fromBits-wide groups into toBits-wide groups.
+func convertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, error) {
+	var acc uint32
+	var bits uint
+	maxv := uint32(1)<<toBits - 1
+
+	var out []byte
+	for _, v := range data {
+		if uint32(v)>>fromBits != 0 {
+			return nil, fmt.Errorf("%w: value out of range", ErrInvalidFormat)
+		}
+		acc = acc<<fromBits | uint32(v)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			out = append(out, byte((acc>>bits)&maxv))
+		}
+	}
+
+	if pad {
+		if bits > 0 {
+			out = append(out, byte((acc<<(toBits-bits))&maxv))
+		}
+	} else if bits >= fromBits || (acc<<(toBits-bits))&maxv != 0 {
+		return nil, fmt.Errorf("%w: invalid padding", ErrInvalidFormat)
+	}
+	return out, nil
+}
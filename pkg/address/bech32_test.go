@@ -0,0 +1,55 @@
+package address
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBech32ValidChecksums(t *testing.T) {
+	// Test vectors from BIP-173.
+	valid := []string{
+		"A12UEL5L",
+		"a12uel5l",
+		"an83characterlonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1tt5tgs",
+		"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw",
+		"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w",
+		"?1ezyfcl",
+	}
+
+	for _, s := range valid {
+		hrp, data, err := Bech32Decode(s)
+		assert.NoError(t, err, s)
+
+		encoded, err := Bech32Encode(hrp, data)
+		assert.NoError(t, err)
+		assert.Equal(t, strings.ToLower(s), encoded)
+	}
+}
+
+func TestBech32InvalidStrings(t *testing.T) {
+	invalid := []string{
+		"pzry9x0s0muk",  // no separator
+		"1pzry9x0s0muk", // empty prefix
+		"x1b4n0q5v",     // invalid data character
+		"li1dgmt3",      // checksum too short
+		"A1G7SGD8",      // checksum computed with uppercase prefix
+		"a12UEL5L",      // mixed case
+	}
+
+	for _, s := range invalid {
+		_, _, err := Bech32Decode(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestConvertBitsRoundTrip(t *testing.T) {
+	data := []byte{0x00, 0xff, 0x10, 0x42, 0x99}
+	groups, err := convertBits(data, 8, 5, true)
+	assert.NoError(t, err)
+
+	back, err := convertBits(groups, 5, 8, false)
+	assert.NoError(t, err)
+	assert.Equal(t, data, back)
+}
@@ -0,0 +1,117 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// ECDSAPrivateKey is an ECDSA key on the NIST P-256 curve.
+type ECDSAPrivateKey struct {
+	key *ecdsa.PrivateKey
+}
+
+// NewECDSAPrivateKey generates a random P-256 key.
+func NewECDSAPrivateKey() (*ECDSAPrivateKey, error) {
+	return NewECDSAPrivateKeyFromReader(rand.Reader)
+}
+
+// NewECDSAPrivateKeyFromReader generates a P-256 key from entropy read from
+// r, drawing 32 bytes until they form a valid scalar. The same entropy always
+// yields the same key.
+func NewECDSAPrivateKeyFromReader(r io.Reader) (*ECDSAPrivateKey, error) {
+	b := make([]byte, 32)
+	for {
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		if key, err := ECDSAPrivateKeyFromBytes(b); err == nil {
+			return key, nil
+		}
+	}
+}
+
+// ECDSAPrivateKeyFromBytes decodes a P-256 private scalar as returned by Bytes.
+func ECDSAPrivateKeyFromBytes(b []byte) (*ECDSAPrivateKey, error) {
+	curve := elliptic.P256()
+	d := new(big.Int).SetBytes(b)
+	if len(b) != 32 || d.Sign() == 0 || d.Cmp(curve.Params().N) >= 0 {
+		return nil, fmt.Errorf("invalid private key")
+	}
+	key := &ecdsa.PrivateKey{D: d}
+	key.PublicKey.Curve = curve
+	key.PublicKey.X, key.PublicKey.Y = curve.ScalarBaseMult(b)
+	return &ECDSAPrivateKey{key: key}, nil
+}
+
+func (p *ECDSAPrivateKey) Scheme() Scheme {
+	return SchemeECDSAP256
+}
+
+// Bytes returns the 32 byte private scalar.
+func (p *ECDSAPrivateKey) Bytes() []byte {
+	return p.key.D.FillBytes(make([]byte, 32))
+}
+
+// Sign returns the scheme byte followed by the ASN.1 signature of the
+// SHA-256 digest of msg. It panics if the system random source fails.
+func (p *ECDSAPrivateKey) Sign(msg []byte) []byte {
+	digest := sha256.Sum256(msg)
+	sig, err := ecdsa.SignASN1(rand.Reader, p.key, digest[:])
+	if err != nil {
+		panic(err)
+	}
+	return append([]byte{byte(SchemeECDSAP256)}, sig...)
+}
+
+func (p *ECDSAPrivateKey) PublicKey() *ECDSAPublicKey {
+	return &ECDSAPublicKey{key: &p.key.PublicKey}
+}
+
+func (p *ECDSAPrivateKey) Public() Verifier {
+	return p.PublicKey()
+}
+
+// ECDSAPublicKey is a P-256 public key.
+type ECDSAPublicKey struct {
+	key *ecdsa.PublicKey
+}
+
+func (p *ECDSAPublicKey) Scheme() Scheme {
+	return SchemeECDSAP256
+}
+
+// Bytes returns the scheme byte followed by the compressed curve point.
+func (p *ECDSAPublicKey) Bytes() []byte {
+	return append([]byte{byte(SchemeECDSAP256)}, elliptic.MarshalCompressed(p.key.Curve, p.key.X, p.key.Y)...)
+}
+
+// Verify checks a signature produced by ECDSAPrivateKey.Sign.
+func (p *ECDSAPublicKey) Verify(msg, sig []byte) bool {
+	if len(sig) < 2 || Scheme(sig[0]) != SchemeECDSAP256 {
+		return false
+	}
+	digest := sha256.Sum256(msg)
+	return ecdsa.VerifyASN1(p.key, digest[:], sig[1:])
+}
+
+func (p *ECDSAPublicKey) String() string {
+	return hex.EncodeToString(p.Bytes())
+}
+
+func parseECDSAP256PublicKey(encoded []byte) (Verifier, error) {
+	if len(encoded) != 34 || Scheme(encoded[0]) != SchemeECDSAP256 {
+		return nil, fmt.Errorf("invalid public key length")
+	}
+	curve := elliptic.P256()
+	x, y := elliptic.UnmarshalCompressed(curve, encoded[1:])
+	if x == nil {
+		return nil, fmt.Errorf("invalid public key")
+	}
+	return &ECDSAPublicKey{key: &ecdsa.PublicKey{Curve: curve, X: x, Y: y}}, nil
+}
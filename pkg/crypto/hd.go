@@ -0,0 +1,136 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// HardenedKeyStart is the first hardened child index. Ed25519 derivation
+// only supports hardened children.
+const HardenedKeyStart uint32 = 0x80000000
+
+// masterKeyHMACKey is the HMAC key SLIP-0010 uses for ed25519 master keys.
+var masterKeyHMACKey = []byte("ed25519 seed")
+
+// ErrNonHardened is returned when deriving a non-hardened ed25519 child.
+var ErrNonHardened = errors.New("crypto: ed25519 only supports hardened derivation")
+
+// ExtendedKey is a node in a SLIP-0010 ed25519 key tree.
+type ExtendedKey struct {
+	key       []byte // 32 byte ed25519 seed
+	chainCode []byte
+	depth     uint8
+	index     uint32
+}
+
+// NewMasterKey derives the root of the key tree from seed.
+func NewMasterKey(seed []byte) (*ExtendedKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, fmt.Errorf("crypto: seed length must be between 16 and 64 bytes")
+	}
+	mac := hmac.New(sha512.New, masterKeyHMACKey)
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	return &ExtendedKey{key: sum[:32], chainCode: sum[32:]}, nil
+}
+
+// Child derives the hardened child at index.
+func (k *ExtendedKey) Child(index uint32) (*ExtendedKey, error) {
+	if index < HardenedKeyStart {
+		return nil, ErrNonHardened
+	}
+
+	data := make([]byte, 0, 37)
+	data = append(data, 0x00)
+	data = append(data, k.key...)
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	return &ExtendedKey{key: sum[:32], chainCode: sum[32:], depth: k.depth + 1, index: index}, nil
+}
+
+// Derive walks path starting from k.
+func (k *ExtendedKey) Derive(path DerivationPath) (*ExtendedKey, error) {
+	key := k
+	for _, index := range path {
+		var err error
+		if key, err = key.Child(index); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// PrivateKey returns the signing key of the node.
+func (k *ExtendedKey) PrivateKey() *PrivateKey {
+	key, _ := NewPrivateKeyFromSeed(k.key)
+	return key
+}
+
+// ChainCode returns the chain code of the node.
+func (k *ExtendedKey) ChainCode() []byte {
+	return append([]byte(nil), k.chainCode...)
+}
+
+// Depth returns the number of derivation steps from the master key.
+func (k *ExtendedKey) Depth() uint8 {
+	return k.depth
+}
+
+// Index returns the child index the node was derived with.
+func (k *ExtendedKey) Index() uint32 {
+	return k.index
+}
+
+// DerivationPath is a sequence of child indexes below the master key.
+type DerivationPath []uint32
+
+// ParsePath parses a path such as m/44'/1'/0'/0'/5'. Hardened indexes may be
+// marked with ', h or H.
+func ParsePath(s string) (DerivationPath, error) {
+	parts := strings.Split(strings.TrimSpace(s), "/")
+	if len(parts) == 0 || parts[0] != "m" {
+		return nil, fmt.Errorf("crypto: derivation path must start with m")
+	}
+
+	path := make(DerivationPath, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		hardened := false
+		if trimmed := strings.TrimRight(part, "'hH"); len(trimmed) == len(part)-1 {
+			part, hardened = trimmed, true
+		}
+
+		index, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || uint32(index) >= HardenedKeyStart {
+			return nil, fmt.Errorf("crypto: invalid path component %q", part)
+		}
+		if hardened {
+			index += uint64(HardenedKeyStart)
+		}
+		path = append(path, uint32(index))
+	}
+	return path, nil
+}
+
+// String formats the path with ' marking hardened indexes.
+func (p DerivationPath) String() string {
+	var sb strings.Builder
+	sb.WriteString("m")
+	for _, index := range p {
+		sb.WriteString("/")
+		if index >= HardenedKeyStart {
+			sb.WriteString(strconv.FormatUint(uint64(index-HardenedKeyStart), 10))
+			sb.WriteString("'")
+		} else {
+			sb.WriteString(strconv.FormatUint(uint64(index), 10))
+		}
+	}
+	return sb.String()
+}
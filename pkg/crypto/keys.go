@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"io"
 )
 
 const (
@@ -28,12 +29,34 @@ func (p *PrivateKey) PublicKey() *PublicKey {
 	return &PublicKey{key: p.key.Public().(ed25519.PublicKey)}
 }
 
+func (p *PrivateKey) Public() Verifier {
+	return p.PublicKey()
+}
+
+func (p *PrivateKey) Scheme() Scheme {
+	return SchemeEd25519
+}
+
 func NewPrivateKey() (*PrivateKey, error) {
-	_, priv, err := ed25519.GenerateKey(rand.Reader)
-	if err != nil {
+	return NewPrivateKeyFromReader(rand.Reader)
+}
+
+// NewPrivateKeyFromReader generates a key from a seed read from r. The same
+// entropy always yields the same key, so tests can use a seeded source.
+func NewPrivateKeyFromReader(r io.Reader) (*PrivateKey, error) {
+	seed := make([]byte, ed25519.SeedSize)
+	if _, err := io.ReadFull(r, seed); err != nil {
 		return nil, err
 	}
-	return &PrivateKey{key: priv}, nil
+	return &PrivateKey{key: ed25519.NewKeyFromSeed(seed)}, nil
+}
+
+// NewPrivateKeyFromSeed returns the private key derived from a 32 byte seed.
+func NewPrivateKeyFromSeed(seed []byte) (*PrivateKey, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid seed length")
+	}
+	return &PrivateKey{key: ed25519.NewKeyFromSeed(seed)}, nil
 }
 
 type PublicKey struct {
@@ -44,7 +67,16 @@ func (p *PublicKey) Bytes() []byte {
 	return p.key[:]
 }
 
+func (p *PublicKey) Scheme() Scheme {
+	return SchemeEd25519
+}
+
+// Verify checks an untagged Ed25519 signature, or one prefixed with the
+// Ed25519 scheme byte.
 func (p *PublicKey) Verify(msg, sig []byte) bool {
+	if len(sig) == ed25519.SignatureSize+1 && Scheme(sig[0]) == SchemeEd25519 {
+		sig = sig[1:]
+	}
 	return ed25519.Verify(p.key, msg, sig)
 }
 
@@ -1,6 +1,7 @@
 package crypto
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -56,3 +57,26 @@ func TestPrivateKeyFromString(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, privKey.Bytes(), deserializedPrivKey.Bytes())
 }
+
+func TestNewPrivateKeyFromReader(t *testing.T) {
+	seed := bytes.Repeat([]byte{7}, 32)
+	a, err := NewPrivateKeyFromReader(bytes.NewReader(seed))
+	assert.NoError(t, err)
+	b, err := NewPrivateKeyFromSeed(seed)
+	assert.NoError(t, err)
+	assert.Equal(t, b.Bytes(), a.Bytes())
+
+	_, err = NewPrivateKeyFromReader(bytes.NewReader(seed[:31]))
+	assert.Error(t, err)
+
+	ecdsaA, err := NewECDSAPrivateKeyFromReader(bytes.NewReader(seed))
+	assert.NoError(t, err)
+	ecdsaB, err := NewECDSAPrivateKeyFromReader(bytes.NewReader(seed))
+	assert.NoError(t, err)
+	assert.Equal(t, ecdsaA.Bytes(), ecdsaB.Bytes())
+
+	// Entropy above the curve order is skipped.
+	ecdsaC, err := NewECDSAPrivateKeyFromReader(bytes.NewReader(append(bytes.Repeat([]byte{0xff}, 32), seed...)))
+	assert.NoError(t, err)
+	assert.Equal(t, ecdsaA.Bytes(), ecdsaC.Bytes())
+}
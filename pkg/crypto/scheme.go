@@ -0,0 +1,118 @@
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Scheme identifies a signature algorithm. Keys and signatures of every
+// scheme other than Ed25519 are prefixed with their scheme byte; Ed25519 keys
+// and signatures keep the untagged encoding they had before schemes existed,
+// so transactions signed with them remain valid unchanged.
+type Scheme byte
+
+const (
+	SchemeEd25519   Scheme = 0x01
+	SchemeECDSAP256 Scheme = 0x02
+)
+
+// ErrUnknownScheme is returned when a key or signature names an unregistered scheme.
+var ErrUnknownScheme = errors.New("crypto: unknown signature scheme")
+
+// Signer signs messages under a signature scheme.
+type Signer interface {
+	Scheme() Scheme
+	// Sign returns the encoded signature of msg.
+	Sign(msg []byte) []byte
+	// Public returns the verifier for the signer's public key.
+	Public() Verifier
+}
+
+// Verifier checks signatures made under a signature scheme.
+type Verifier interface {
+	Scheme() Scheme
+	// Verify reports whether sig is a valid encoded signature of msg.
+	Verify(msg, sig []byte) bool
+	// Bytes returns the encoded public key.
+	Bytes() []byte
+	String() string
+}
+
+// SchemeInfo describes a registered signature scheme.
+type SchemeInfo struct {
+	Name string
+	// ParsePublicKey decodes an encoded public key, including its scheme byte.
+	ParsePublicKey func(encoded []byte) (Verifier, error)
+}
+
+var (
+	schemesMu sync.RWMutex
+	schemes   = map[Scheme]SchemeInfo{
+		SchemeEd25519:   {Name: "ed25519", ParsePublicKey: parseTaggedEd25519},
+		SchemeECDSAP256: {Name: "ecdsa-p256", ParsePublicKey: parseECDSAP256PublicKey},
+	}
+)
+
+// RegisterScheme adds a signature scheme. Registering a scheme only teaches
+// the package to parse its keys; whether transactions may use it is decided
+// by the scheme's activation in consensus rules.
+func RegisterScheme(id Scheme, info SchemeInfo) error {
+	schemesMu.Lock()
+	defer schemesMu.Unlock()
+	if _, ok := schemes[id]; ok {
+		return fmt.Errorf("crypto: scheme %#x already registered", byte(id))
+	}
+	schemes[id] = info
+	return nil
+}
+
+// LookupScheme returns the registered scheme with identifier id.
+func LookupScheme(id Scheme) (SchemeInfo, bool) {
+	schemesMu.RLock()
+	defer schemesMu.RUnlock()
+	info, ok := schemes[id]
+	return info, ok
+}
+
+func (s Scheme) String() string {
+	if info, ok := LookupScheme(s); ok {
+		return info.Name
+	}
+	return fmt.Sprintf("scheme(%#x)", byte(s))
+}
+
+// SchemeOf returns the scheme of an encoded public key.
+func SchemeOf(encoded []byte) (Scheme, error) {
+	if len(encoded) == pubKeyLen {
+		return SchemeEd25519, nil
+	}
+	if len(encoded) == 0 {
+		return 0, errors.New("crypto: empty public key")
+	}
+	return Scheme(encoded[0]), nil
+}
+
+// ParsePublicKey decodes an encoded public key of any registered scheme.
+func ParsePublicKey(encoded []byte) (Verifier, error) {
+	if len(encoded) == pubKeyLen {
+		return &PublicKey{key: append([]byte(nil), encoded...)}, nil
+	}
+	scheme, err := SchemeOf(encoded)
+	if err != nil {
+		return nil, err
+	}
+	info, ok := LookupScheme(scheme)
+	if !ok {
+		return nil, fmt.Errorf("%w: %#x", ErrUnknownScheme, byte(scheme))
+	}
+	return info.ParsePublicKey(encoded)
+}
+
+// parseTaggedEd25519 decodes an Ed25519 key carrying an explicit scheme byte.
+func parseTaggedEd25519(encoded []byte) (Verifier, error) {
+	if len(encoded) != 1+pubKeyLen || Scheme(encoded[0]) != SchemeEd25519 {
+		return nil, fmt.Errorf("invalid public key length")
+	}
+	return &PublicKey{key: append([]byte(nil), encoded[1:]...)}, nil
+}
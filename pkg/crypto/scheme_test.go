@@ -0,0 +1,70 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestECDSASignAndVerify(t *testing.T) {
+	privKey, err := NewECDSAPrivateKey()
+	require.NoError(t, err)
+
+	msg := []byte("message")
+	sig := privKey.Sign(msg)
+	assert.Equal(t, byte(SchemeECDSAP256), sig[0])
+	assert.True(t, privKey.Public().Verify(msg, sig))
+	assert.False(t, privKey.Public().Verify([]byte("other"), sig))
+
+	restored, err := ECDSAPrivateKeyFromBytes(privKey.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, privKey.PublicKey().Bytes(), restored.PublicKey().Bytes())
+}
+
+func TestParsePublicKey(t *testing.T) {
+	edKey, err := NewPrivateKey()
+	require.NoError(t, err)
+	ecKey, err := NewECDSAPrivateKey()
+	require.NoError(t, err)
+
+	for _, signer := range []Signer{edKey, ecKey} {
+		parsed, err := ParsePublicKey(signer.Public().Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, signer.Scheme(), parsed.Scheme())
+		assert.Equal(t, signer.Public().Bytes(), parsed.Bytes())
+
+		msg := []byte("message")
+		assert.True(t, parsed.Verify(msg, signer.Sign(msg)))
+	}
+
+	// Ed25519 keys may also carry an explicit scheme byte.
+	tagged, err := ParsePublicKey(append([]byte{byte(SchemeEd25519)}, edKey.PublicKey().Bytes()...))
+	require.NoError(t, err)
+	assert.Equal(t, edKey.PublicKey().Bytes(), tagged.Bytes())
+
+	_, err = ParsePublicKey([]byte{0x7f, 1, 2, 3})
+	assert.ErrorIs(t, err, ErrUnknownScheme)
+	_, err = ParsePublicKey(nil)
+	assert.Error(t, err)
+}
+
+func TestSignatureSchemesDoNotMix(t *testing.T) {
+	edKey, err := NewPrivateKey()
+	require.NoError(t, err)
+	ecKey, err := NewECDSAPrivateKey()
+	require.NoError(t, err)
+
+	msg := []byte("message")
+	assert.False(t, ecKey.Public().Verify(msg, edKey.Sign(msg)))
+	assert.False(t, edKey.Public().Verify(msg, ecKey.Sign(msg)))
+
+	// A tagged Ed25519 signature verifies like an untagged one.
+	assert.True(t, edKey.Public().Verify(msg, append([]byte{byte(SchemeEd25519)}, edKey.Sign(msg)...)))
+}
+
+func TestRegisterScheme(t *testing.T) {
+	assert.Error(t, RegisterScheme(SchemeEd25519, SchemeInfo{Name: "duplicate"}))
+	assert.Equal(t, "ecdsa-p256", SchemeECDSAP256.String())
+	assert.Equal(t, "scheme(0x7e)", Scheme(0x7e).String())
+}
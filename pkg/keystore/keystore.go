@@ -0,0 +1,220 @@
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/NicholasRodrigues/go-chain/pkg/crypto"
+)
+
+const (
+	// Version is the envelope version written by this package.
+	Version = 1
+	// MaxIterations is the largest PBKDF2 work factor accepted when
+	// decrypting, so a crafted envelope cannot stall the process.
+	MaxIterations = 10000000
+
+	kdfPBKDF2 = "pbkdf2-sha256"
+	cipherAES = "aes-256-gcm"
+	keyLen    = 32
+	saltLen   = 16
+)
+
+// kdfIterations is the PBKDF2 work factor used for new envelopes.
+var kdfIterations = 600000
+
+var (
+	// ErrWrongPassword is returned when an envelope cannot be decrypted.
+	ErrWrongPassword = errors.New("keystore: wrong password or corrupted file")
+	// ErrUnsupported is returned for envelopes using an unknown version, KDF or cipher.
+	ErrUnsupported = errors.New("keystore: unsupported format")
+)
+
+// KDFParams are the parameters of the password based key derivation.
+type KDFParams struct {
+	Iterations int    `json:"iterations"`
+	Salt       string `json:"salt"`
+}
+
+// Envelope is the versioned JSON document stored on disk.
+type Envelope struct {
+	Version    int       `json:"version"`
+	KDF        string    `json:"kdf"`
+	KDFParams  KDFParams `json:"kdfparams"`
+	Cipher     string    `json:"cipher"`
+	Nonce      string    `json:"nonce"`
+	Ciphertext string    `json:"ciphertext"`
+}
+
+// Secrets is the plaintext content of a keystore.
+type Secrets struct {
+	Keys []*crypto.PrivateKey
+	Seed []byte // Optional seed of a deterministic wallet
+}
+
+// payload is the JSON form of Secrets that gets encrypted.
+type payload struct {
+	Keys []string `json:"keys"`
+	Seed string   `json:"seed,omitempty"`
+}
+
+// additionalData binds the envelope header to the ciphertext so that the
+// KDF parameters cannot be altered without detection.
+func (e *Envelope) additionalData() []byte {
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[:8], uint64(e.Version))
+	binary.BigEndian.PutUint64(buf[8:], uint64(e.KDFParams.Iterations))
+	return append(append(buf[:], e.KDF+e.Cipher...), e.KDFParams.Salt...)
+}
+
+func newGCM(password []byte, salt []byte, iterations int) (cipher.AEAD, error) {
+	key := crypto.PBKDF2(password, salt, iterations, keyLen, sha256.New)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Encrypt seals secrets with a key derived from password.
+func Encrypt(secrets *Secrets, password []byte) (*Envelope, error) {
+	if len(password) == 0 {
+		return nil, errors.New("keystore: empty password")
+	}
+
+	plain := payload{Seed: hex.EncodeToString(secrets.Seed)}
+	for _, key := range secrets.Keys {
+		plain.Keys = append(plain.Keys, key.String())
+	}
+	plaintext, err := json.Marshal(plain)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(password, salt, kdfIterations)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	env := &Envelope{
+		Version:   Version,
+		KDF:       kdfPBKDF2,
+		KDFParams: KDFParams{Iterations: kdfIterations, Salt: hex.EncodeToString(salt)},
+		Cipher:    cipherAES,
+		Nonce:     hex.EncodeToString(nonce),
+	}
+	env.Ciphertext = hex.EncodeToString(aead.Seal(nil, nonce, plaintext, env.additionalData()))
+	return env, nil
+}
+
+// Decrypt opens an envelope with password.
+func Decrypt(env *Envelope, password []byte) (*Secrets, error) {
+	if env.Version != Version || env.KDF != kdfPBKDF2 || env.Cipher != cipherAES {
+		return nil, ErrUnsupported
+	}
+	if env.KDFParams.Iterations < 1 || env.KDFParams.Iterations > MaxIterations {
+		return nil, fmt.Errorf("%w: invalid iteration count %d", ErrUnsupported, env.KDFParams.Iterations)
+	}
+
+	salt, err := hex.DecodeString(env.KDFParams.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: invalid salt: %w", err)
+	}
+	nonce, err := hex.DecodeString(env.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: invalid nonce: %w", err)
+	}
+	ciphertext, err := hex.DecodeString(env.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: invalid ciphertext: %w", err)
+	}
+
+	aead, err := newGCM(password, salt, env.KDFParams.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("%w: invalid nonce length", ErrUnsupported)
+	}
+	plaintext, err := aead.Open(nil, nonce, ciphertext, env.additionalData())
+	if err != nil {
+		return nil, ErrWrongPassword
+	}
+
+	var plain payload
+	if err := json.Unmarshal(plaintext, &plain); err != nil {
+		return nil, err
+	}
+	secrets := &Secrets{}
+	if plain.Seed != "" {
+		if secrets.Seed, err = hex.DecodeString(plain.Seed); err != nil {
+			return nil, fmt.Errorf("keystore: invalid seed: %w", err)
+		}
+	}
+	for _, s := range plain.Keys {
+		key, err := crypto.PrivateKeyFromString(s)
+		if err != nil {
+			return nil, err
+		}
+		secrets.Keys = append(secrets.Keys, key)
+	}
+	return secrets, nil
+}
+
+// Save encrypts secrets and writes them to path, readable only by the owner.
+func Save(path string, secrets *Secrets, password []byte) error {
+	env, err := Encrypt(secrets, password)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a failure never truncates an existing keystore.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Load reads and decrypts the keystore at path.
+func Load(path string, password []byte) (*Secrets, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("keystore: invalid file: %w", err)
+	}
+	return Decrypt(&env, password)
+}
+
+// ChangePassword re-encrypts the keystore at path under newPassword.
+func ChangePassword(path string, oldPassword, newPassword []byte) error {
+	secrets, err := Load(path, oldPassword)
+	if err != nil {
+		return err
+	}
+	return Save(path, secrets, newPassword)
+}
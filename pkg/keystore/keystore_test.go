@@ -0,0 +1,114 @@
+package keystore
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/NicholasRodrigues/go-chain/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	// Keep the key derivation cheap in tests.
+	kdfIterations = 1000
+}
+
+func testSecrets(t *testing.T, n int) *Secrets {
+	secrets := &Secrets{}
+	for i := 0; i < n; i++ {
+		key, err := crypto.NewPrivateKey()
+		require.NoError(t, err)
+		secrets.Keys = append(secrets.Keys, key)
+	}
+	return secrets
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	secrets := testSecrets(t, 3)
+
+	env, err := Encrypt(secrets, []byte("correct horse"))
+	require.NoError(t, err)
+	assert.Equal(t, Version, env.Version)
+
+	decrypted, err := Decrypt(env, []byte("correct horse"))
+	require.NoError(t, err)
+	require.Len(t, decrypted.Keys, 3)
+	for i, key := range secrets.Keys {
+		assert.Equal(t, key.Bytes(), decrypted.Keys[i].Bytes())
+	}
+
+	_, err = Decrypt(env, []byte("wrong horse"))
+	assert.ErrorIs(t, err, ErrWrongPassword)
+}
+
+func TestEncryptDecryptSeed(t *testing.T) {
+	secrets := &Secrets{Seed: []byte("0123456789abcdef0123456789abcdef")}
+
+	env, err := Encrypt(secrets, []byte("pw"))
+	require.NoError(t, err)
+
+	decrypted, err := Decrypt(env, []byte("pw"))
+	require.NoError(t, err)
+	assert.Equal(t, secrets.Seed, decrypted.Seed)
+	assert.Empty(t, decrypted.Keys)
+}
+
+func TestEnvelopeHeaderIsAuthenticated(t *testing.T) {
+	env, err := Encrypt(testSecrets(t, 1), []byte("pw"))
+	require.NoError(t, err)
+
+	env.KDFParams.Iterations++
+	_, err = Decrypt(env, []byte("pw"))
+	assert.ErrorIs(t, err, ErrWrongPassword)
+
+	env.KDFParams.Iterations--
+	env.Version = 2
+	_, err = Decrypt(env, []byte("pw"))
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
+
+func TestDecryptRejectsExcessiveIterations(t *testing.T) {
+	env, err := Encrypt(testSecrets(t, 1), []byte("pw"))
+	require.NoError(t, err)
+
+	for _, iterations := range []int{0, MaxIterations + 1, 1 << 31} {
+		env.KDFParams.Iterations = iterations
+		_, err = Decrypt(env, []byte("pw"))
+		assert.ErrorIs(t, err, ErrUnsupported, "%d iterations", iterations)
+	}
+}
+
+func TestSaveLoadChangePassword(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wallet.json")
+	secrets := testSecrets(t, 2)
+
+	require.NoError(t, Save(path, secrets, []byte("old")))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	for _, key := range secrets.Keys {
+		assert.False(t, strings.Contains(string(data), key.String()), "private key stored in plaintext")
+	}
+	var env Envelope
+	require.NoError(t, json.Unmarshal(data, &env))
+	assert.Equal(t, "aes-256-gcm", env.Cipher)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	require.NoError(t, ChangePassword(path, []byte("old"), []byte("new")))
+
+	_, err = Load(path, []byte("old"))
+	assert.ErrorIs(t, err, ErrWrongPassword)
+
+	loaded, err := Load(path, []byte("new"))
+	require.NoError(t, err)
+	assert.Equal(t, secrets.Keys[1].Bytes(), loaded.Keys[1].Bytes())
+
+	assert.ErrorIs(t, ChangePassword(path, []byte("old"), []byte("other")), ErrWrongPassword)
+}
@@ -0,0 +1,159 @@
+// Package mnemonic implements BIP-39 style mnemonic sentences for backing up
+// wallet seeds.
+package mnemonic
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/NicholasRodrigues/go-chain/pkg/crypto"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	seedIterations = 2048
+	seedLen        = 64
+	bitsPerWord    = 11
+)
+
+//go:embed english.txt
+var englishWords string
+
+// English is the BIP-39 English wordlist.
+var English = strings.Fields(englishWords)
+
+var wordIndex = func() map[string]int {
+	idx := make(map[string]int, len(English))
+	for i, word := range English {
+		idx[word] = i
+	}
+	return idx
+}()
+
+var (
+	// ErrInvalidWord is returned for words that are not in the wordlist.
+	ErrInvalidWord = errors.New("mnemonic: word not in wordlist")
+	// ErrInvalidLength is returned for unsupported entropy or sentence lengths.
+	ErrInvalidLength = errors.New("mnemonic: invalid length")
+	// ErrChecksum is returned when the checksum bits do not match.
+	ErrChecksum = errors.New("mnemonic: checksum mismatch")
+)
+
+// NewEntropy returns bits of random entropy. bits must be a multiple of 32
+// between 128 and 256.
+func NewEntropy(bits int) ([]byte, error) {
+	return NewEntropyFromReader(bits, rand.Reader)
+}
+
+// NewEntropyFromReader returns bits of entropy read from r.
+func NewEntropyFromReader(bits int, r io.Reader) ([]byte, error) {
+	if bits%32 != 0 || bits < 128 || bits > 256 {
+		return nil, ErrInvalidLength
+	}
+	entropy := make([]byte, bits/8)
+	if _, err := io.ReadFull(r, entropy); err != nil {
+		return nil, err
+	}
+	return entropy, nil
+}
+
+// Generate returns a new random mnemonic encoding bits of entropy.
+func Generate(bits int) (string, error) {
+	entropy, err := NewEntropy(bits)
+	if err != nil {
+		return "", err
+	}
+	return FromEntropy(entropy)
+}
+
+// FromEntropy encodes entropy as a mnemonic. The entropy is followed by the
+// first len(entropy)/4 bits of its SHA-256 hash and split into 11 bit words.
+func FromEntropy(entropy []byte) (string, error) {
+	bits := len(entropy) * 8
+	if bits%32 != 0 || bits < 128 || bits > 256 {
+		return "", ErrInvalidLength
+	}
+
+	hash := sha256.Sum256(entropy)
+	data := append(append([]byte(nil), entropy...), hash[0])
+	words := (bits + bits/32) / bitsPerWord
+
+	sentence := make([]string, words)
+	for i := range sentence {
+		sentence[i] = English[readBits(data, i*bitsPerWord, bitsPerWord)]
+	}
+	return strings.Join(sentence, " "), nil
+}
+
+// normalize returns the words of mnemonic in NFKD form and lower case.
+func normalize(mnemonic string) []string {
+	return strings.Fields(strings.ToLower(norm.NFKD.String(mnemonic)))
+}
+
+// ToEntropy decodes a mnemonic back into its entropy, verifying the checksum.
+// Words are matched after NFKD normalisation and regardless of case.
+func ToEntropy(mnemonic string) ([]byte, error) {
+	words := normalize(mnemonic)
+	if len(words)%3 != 0 || len(words) < 12 || len(words) > 24 {
+		return nil, ErrInvalidLength
+	}
+
+	totalBits := len(words) * bitsPerWord
+	checksumBits := totalBits / 33
+	data := make([]byte, (totalBits+7)/8)
+	for i, word := range words {
+		index, ok := wordIndex[word]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidWord, word)
+		}
+		writeBits(data, i*bitsPerWord, bitsPerWord, index)
+	}
+
+	entropy := data[:(totalBits-checksumBits)/8]
+	hash := sha256.Sum256(entropy)
+	if readBits(data, len(entropy)*8, checksumBits) != readBits(hash[:], 0, checksumBits) {
+		return nil, ErrChecksum
+	}
+	return entropy, nil
+}
+
+// Validate reports whether mnemonic is a well-formed sentence.
+func Validate(mnemonic string) error {
+	_, err := ToEntropy(mnemonic)
+	return err
+}
+
+// Seed derives a 64 byte wallet seed from mnemonic and an optional
+// passphrase. The mnemonic is normalised as by ToEntropy, so every spelling
+// it accepts gives the same seed, and the passphrase is NFKD normalised as
+// BIP-39 requires.
+func Seed(mnemonic, passphrase string) []byte {
+	sentence := strings.Join(normalize(mnemonic), " ")
+	salt := "mnemonic" + norm.NFKD.String(passphrase)
+	return crypto.PBKDF2([]byte(sentence), []byte(salt), seedIterations, seedLen, sha512.New)
+}
+
+// readBits returns n bits of data starting at bit offset, most significant first.
+func readBits(data []byte, offset, n int) int {
+	v := 0
+	for i := offset; i < offset+n; i++ {
+		v = v<<1 | int(data[i/8]>>(7-uint(i%8))&1)
+	}
+	return v
+}
+
+// writeBits stores the low n bits of v into data starting at bit offset.
+func writeBits(data []byte, offset, n, v int) {
+	for i := 0; i < n; i++ {
+		if v>>(n-1-i)&1 == 1 {
+			bit := offset + i
+			data[bit/8] |= 1 << (7 - uint(bit%8))
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package mnemonic
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWordlist(t *testing.T) {
+	assert.Len(t, English, 2048)
+	assert.Equal(t, "abandon", English[0])
+	assert.Equal(t, "zoo", English[2047])
+}
+
+func TestVectors(t *testing.T) {
+	// Test vectors from the BIP-39 reference implementation, passphrase "TREZOR".
+	vectors := []struct {
+		entropy  string
+		mnemonic string
+		seed     string
+	}{
+		{"00000000000000000000000000000000",
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+			"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04"},
+		{"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+			"legal winner thank year wave sausage worth useful legal winner thank yellow",
+			"2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607"},
+		{"ffffffffffffffffffffffffffffffff",
+			"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong",
+			"ac27495480225222079d7be181583751e86f571027b0497b5b5d11218e0a8a13332572917f0f8e5a589620c6f15b11c61dee327651a14c34e18231052e48c069"},
+		{"0000000000000000000000000000000000000000000000000000000000000000",
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art",
+			"bda85446c68413707090a52022edd26a1c9462295029f2e60cd7c4f2bbd3097170af7a4d73245cafa9c3cca8d561a7c3de6f5d4a10be8ed2a5e608d68f92fcc8"},
+	}
+
+	for _, v := range vectors {
+		entropy, _ := hex.DecodeString(v.entropy)
+
+		sentence, err := FromEntropy(entropy)
+		require.NoError(t, err)
+		assert.Equal(t, v.mnemonic, sentence)
+
+		decoded, err := ToEntropy(sentence)
+		require.NoError(t, err)
+		assert.Equal(t, entropy, decoded)
+
+		assert.Equal(t, v.seed, hex.EncodeToString(Seed(sentence, "TREZOR")))
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	for _, bits := range []int{128, 160, 192, 224, 256} {
+		sentence, err := Generate(bits)
+		require.NoError(t, err)
+		assert.Len(t, strings.Fields(sentence), (bits+bits/32)/11)
+		assert.NoError(t, Validate(sentence))
+	}
+
+	_, err := Generate(100)
+	assert.ErrorIs(t, err, ErrInvalidLength)
+
+	entropy, err := NewEntropyFromReader(128, bytes.NewReader(bytes.Repeat([]byte{0x7f}, 16)))
+	require.NoError(t, err)
+	assert.Equal(t, bytes.Repeat([]byte{0x7f}, 16), entropy)
+	_, err = NewEntropyFromReader(128, bytes.NewReader(make([]byte, 15)))
+	assert.Error(t, err)
+}
+
+func TestValidateErrors(t *testing.T) {
+	assert.ErrorIs(t, Validate("abandon abandon abandon"), ErrInvalidLength)
+	assert.ErrorIs(t, Validate("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon"), ErrChecksum)
+	assert.ErrorIs(t, Validate("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abuot"), ErrInvalidWord)
+}
+
+func TestSeedPassphrase(t *testing.T) {
+	sentence := "legal winner thank year wave sausage worth useful legal winner thank yellow"
+	assert.NotEqual(t, Seed(sentence, ""), Seed(sentence, "TREZOR"))
+	assert.Len(t, Seed(sentence, ""), 64)
+}
+
+func TestSeedNormalization(t *testing.T) {
+	sentence := "legal winner thank year wave sausage worth useful legal winner thank yellow"
+	seed := Seed(sentence, "")
+
+	// Any spelling ToEntropy accepts gives the same seed.
+	for _, spelling := range []string{
+		strings.ToUpper(sentence),
+		"  legal\twinner thank year  wave sausage worth useful legal winner thank\nyellow ",
+		strings.Replace(sentence, "legal", "\uff4c\uff45\uff47\uff41\uff4c", 1), // Fullwidth letters decompose to ASCII
+	} {
+		require.NoError(t, Validate(spelling), spelling)
+		assert.Equal(t, seed, Seed(spelling, ""), spelling)
+	}
+
+	// Passphrases are NFKD normalised but keep their case.
+	assert.Equal(t, Seed(sentence, "caf\u00e9"), Seed(sentence, "cafe\u0301"))
+	assert.NotEqual(t, Seed(sentence, "Cafe"), Seed(sentence, "cafe"))
+}